@@ -1,14 +1,18 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"github.com/Trendyol/go-dcp/metric"
 	"github.com/ansrivas/fiberprometheus/v2"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	dcp "github.com/Trendyol/go-dcp/config"
 
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/fiber/v2/middleware/pprof"
 
 	"github.com/Trendyol/go-dcp/couchbase"
@@ -21,56 +25,164 @@ import (
 
 type API interface {
 	Listen()
-	Shutdown()
+	Shutdown() error
 	UnregisterMetricCollectors()
+	SetReloader(reloader Reloader)
+	SetAuthenticator(authenticator Authenticator)
+}
+
+const defaultLagLimit = 10
+
+// Reloader reloads the config of a connector from its file and returns the changed fields.
+type Reloader func() ([]string, error)
+
+// route is an endpoint of an instance, callers need role to call it.
+type route struct {
+	handler fiber.Handler
+	method  string
+	role    string
 }
 
 type api struct {
 	client           couchbase.Client
 	stream           stream.Stream
 	serviceDiscovery servicediscovery.ServiceDiscovery
+	vBucketDiscovery stream.VBucketDiscovery
 	app              *fiber.App
 	config           *dcp.Dcp
 	registerer       *metric.Registerer
+	logger           logger.Logger
+	reloader         Reloader
+	authenticator    Authenticator
+	closeCh          chan struct{}
+	closeOnce        sync.Once
 }
 
 func (s *api) Listen() {
-	logger.Log.Info("api starting on port %d", s.config.API.Port)
+	s.logger.Info("api starting on port %d", s.config.API.Port)
 
-	err := s.app.Listen(fmt.Sprintf(":%d", s.config.API.Port))
+	err := s.listen()
 
 	if err != nil {
-		logger.Log.Error("api cannot start on port %d, err: %v", s.config.API.Port, err)
+		s.logger.Error("api cannot start on port %d, err: %v", s.config.API.Port, err)
 	} else {
-		logger.Log.Info("api stopped")
+		s.logger.Info("api stopped")
 	}
 }
 
-func (s *api) Shutdown() {
+func (s *api) Shutdown() error {
+	s.close()
+
 	err := s.app.Shutdown()
 	if err != nil {
-		logger.Log.Error("api cannot be shutdown, err: %v", err)
-		panic(err)
+		return fmt.Errorf("api cannot be shutdown: %w", err)
 	}
+
+	return nil
+}
+
+// close ends the open tails, they would keep their connections open on shutdown.
+func (s *api) close() {
+	s.closeOnce.Do(func() {
+		close(s.closeCh)
+	})
 }
 
 func (s *api) UnregisterMetricCollectors() {
 	s.registerer.UnregisterAll()
 }
 
+func (s *api) SetReloader(reloader Reloader) {
+	s.reloader = reloader
+}
+
+func (s *api) reload(c *fiber.Ctx) error {
+	if s.reloader == nil {
+		return fiber.NewError(fiber.StatusNotFound, "config reload is only available for connectors created from a config file")
+	}
+
+	fields, err := s.reloader()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	return c.JSON(fiber.Map{"changed": fields})
+}
+
 func (s *api) status(c *fiber.Ctx) error {
-	if _, err := s.client.Ping(); err != nil {
+	if _, err := s.client.Ping(c.UserContext()); err != nil {
 		return err
 	}
 
 	return c.SendString("OK")
 }
 
+// live reports the api is responsive, it does not depend on couchbase so a restart does not fix what it cannot.
+// It fails only when the listener watchdog reports a stall with the failHealth action.
+func (s *api) live(c *fiber.Ctx) error {
+	if health := s.stream.GetHealth(); health.ListenerStalled {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"status":  "DOWN",
+			"reasons": []string{fmt.Sprintf("listener did not ack any event for %.0fs", health.SecondsSinceLastAck)},
+		})
+	}
+
+	return c.JSON(fiber.Map{"status": "UP"})
+}
+
+// ready reports whether the streams are healthy, a 503 response lists the reasons.
+func (s *api) ready(c *fiber.Ctx) error {
+	health := s.stream.GetHealth()
+	reasons := health.NotReadyReasons(&s.config.HealthCheck.Readiness)
+
+	if !s.config.HealthCheck.Disabled {
+		ctx, cancel := context.WithTimeout(c.UserContext(), s.config.HealthCheck.Timeout)
+		defer cancel()
+
+		if _, err := s.client.Ping(ctx); err != nil {
+			reasons = append(reasons, fmt.Sprintf("cannot ping couchbase: %v", err))
+		}
+	}
+
+	if len(reasons) > 0 {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"status":  "DOWN",
+			"reasons": reasons,
+			"health":  health,
+		})
+	}
+
+	return c.JSON(fiber.Map{"status": "UP", "health": health})
+}
+
 func (s *api) offset(c *fiber.Ctx) error {
 	offsets, _, _ := s.stream.GetOffsets()
 	return c.JSON(offsets)
 }
 
+// lag reports the vBuckets which are the most seconds behind, limit query sets how many, 10 by default.
+func (s *api) lag(c *fiber.Ctx) error {
+	lags, err := s.stream.GetLags()
+	if err != nil {
+		return fiber.NewError(fiber.StatusServiceUnavailable, err.Error())
+	}
+
+	var maxSecondsBehind float64
+	var maxSeqNoLag uint64
+
+	for _, lag := range lags {
+		maxSecondsBehind = max(maxSecondsBehind, lag.SecondsBehind)
+		maxSeqNoLag = max(maxSeqNoLag, lag.SeqNoLag)
+	}
+
+	return c.JSON(fiber.Map{
+		"vBucketCount":     len(lags),
+		"maxSecondsBehind": maxSecondsBehind,
+		"maxSeqNoLag":      maxSeqNoLag,
+		"worst":            stream.WorstLags(lags, c.QueryInt("limit", defaultLagLimit)),
+	})
+}
+
 func (s *api) rebalance(c *fiber.Ctx) error {
 	s.stream.Rebalance()
 
@@ -85,11 +197,52 @@ func (s *api) followers(c *fiber.Ctx) error {
 	return c.JSON(s.serviceDiscovery.GetAll())
 }
 
+func (s *api) membership(c *fiber.Ctx) error {
+	return c.JSON(s.vBucketDiscovery.GetMembers())
+}
+
+// routes returns the endpoints of the instance by path, they are served on the root of its own api
+// or under /connectors/{name} of a shared api. Endpoints which change the state are admin only POST endpoints.
+func (s *api) routes() map[string]route {
+	routes := map[string]route{
+		"/rebalance":     {s.rebalance, fiber.MethodPost, dcp.APIRoleAdmin},
+		"/config/reload": {s.reload, fiber.MethodPost, dcp.APIRoleAdmin},
+		"/membership":    {s.membership, fiber.MethodGet, dcp.APIRoleReader},
+		"/lag":           {s.lag, fiber.MethodGet, dcp.APIRoleReader},
+		"/health/live":   {s.live, fiber.MethodGet, rolePublic},
+		"/health/ready":  {s.ready, fiber.MethodGet, rolePublic},
+	}
+
+	if s.config.Debug {
+		routes["/states/offset"] = route{s.offset, fiber.MethodGet, dcp.APIRoleReader}
+		routes["/states/followers"] = route{s.followers, fiber.MethodGet, dcp.APIRoleReader}
+	}
+
+	if s.config.Debug || s.config.API.Tail.Enabled {
+		// events carry the documents, so they are not shown to readers
+		routes["/tail"] = route{s.tail, fiber.MethodGet, dcp.APIRoleAdmin}
+	}
+
+	if s.config.API.Dashboard.Enabled {
+		routes["/dashboard"] = route{s.dashboard, fiber.MethodGet, dcp.APIRoleReader}
+		routes["/dashboard/data"] = route{s.dashboardData, fiber.MethodGet, dcp.APIRoleReader}
+	}
+
+	if !s.config.HealthCheck.Disabled {
+		routes["/status"] = route{s.status, fiber.MethodGet, rolePublic}
+	}
+
+	return routes
+}
+
 func NewAPI(config *dcp.Dcp,
 	client couchbase.Client,
 	stream stream.Stream,
 	serviceDiscovery servicediscovery.ServiceDiscovery,
+	vBucketDiscovery stream.VBucketDiscovery,
 	collectors []prometheus.Collector,
+	registry metric.Registry,
+	logger logger.Logger,
 ) API {
 	app := fiber.New(fiber.Config{DisableStartupMessage: true})
 
@@ -99,36 +252,50 @@ func NewAPI(config *dcp.Dcp,
 		client:           client,
 		stream:           stream,
 		serviceDiscovery: serviceDiscovery,
-		registerer:       metric.WrapWithRegisterer(prometheus.DefaultRegisterer),
+		vBucketDiscovery: vBucketDiscovery,
+		registerer:       metric.WrapWithRegisterer(registry),
+		logger:           logger,
+		authenticator:    NewAuthenticator(&config.API.Auth),
+		closeCh:          make(chan struct{}),
 	}
 
+	app.Use(config.Metric.Path, api.authenticated(dcp.APIRoleReader))
+
 	err := api.registerer.RegisterAll(collectors)
 	if err == nil {
-		app.Use(newMetricMiddleware(app, config))
-	} else {
-		logger.Log.Error("metric middleware cannot be initialized: %v", err)
+		err = registerMetricMiddleware(app, config.Dcp.Group.Name, config.Metric.Path, registry, logger)
 	}
 
-	if config.Debug {
-		app.Use(pprof.New())
-		app.Get("/states/offset", api.offset)
-		app.Get("/states/followers", api.followers)
+	if err != nil {
+		logger.Error("metric middleware cannot be initialized: %v", err)
 	}
 
-	if !config.HealthCheck.Disabled {
-		app.Get("/status", api.status)
+	if config.Debug {
+		app.Use("/debug/pprof", api.authenticated(dcp.APIRoleAdmin), pprof.New())
 	}
 
-	app.Get("/rebalance", api.rebalance)
+	for path, route := range api.routes() {
+		app.Add(route.method, path, api.authenticated(route.role), route.handler)
+	}
 
 	return api
 }
 
-func newMetricMiddleware(app *fiber.App, config *dcp.Dcp) func(ctx *fiber.Ctx) error {
-	fiberPrometheus := fiberprometheus.New(config.Dcp.Group.Name)
-	fiberPrometheus.RegisterAt(app, config.Metric.Path)
+// registerMetricMiddleware serves the metrics of registry on path and counts the requests of app.
+func registerMetricMiddleware(app *fiber.App, serviceName string, path string, registry metric.Registry, logger logger.Logger) (err error) { //nolint:lll
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cannot register http metrics: %v", r)
+		}
+	}()
+
+	fiberPrometheus := fiberprometheus.NewWithRegistry(registry, serviceName, "", "", nil)
+
+	// registered before the middleware, so metric scrapes are not counted as requests
+	app.Get(path, adaptor.HTTPHandler(promhttp.HandlerFor(registry, promhttp.HandlerOpts{})))
+	app.Use(fiberPrometheus.Middleware)
 
-	logger.Log.Info("metric middleware registered on path %s", config.Metric.Path)
+	logger.Info("metric middleware registered on path %s", path)
 
-	return fiberPrometheus.Middleware
+	return nil
 }
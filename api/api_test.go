@@ -0,0 +1,44 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Trendyol/go-dcp/membership"
+	"github.com/Trendyol/go-dcp/stream"
+)
+
+type testMembershipVBucketDiscovery struct {
+	stream.VBucketDiscovery
+}
+
+func (d *testMembershipVBucketDiscovery) GetMembers() []membership.Member {
+	return []membership.Member{
+		{Name: "first", MemberNumber: 1, TotalMembers: 2, VBucketRangeEnd: 511, Self: true, Leader: true},
+		{Name: "second", MemberNumber: 2, TotalMembers: 2, VBucketRangeStart: 512, VBucketRangeEnd: 1023},
+	}
+}
+
+func TestMembership(t *testing.T) {
+	s := &api{vBucketDiscovery: &testMembershipVBucketDiscovery{}}
+
+	app := fiber.New()
+	app.Get("/membership", s.membership)
+
+	res, err := app.Test(httptest.NewRequest("GET", "/membership", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var members []membership.Member
+	if err := json.NewDecoder(res.Body).Decode(&members); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(members) != 2 || members[0].Name != "first" || !members[0].Self || members[1].VBucketRangeStart != 512 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "first and second members", members)
+	}
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	dcp "github.com/Trendyol/go-dcp/config"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	basicPrefix  = "Basic "
+	bearerPrefix = "Bearer "
+
+	// rolePublic is the role of the endpoints served without authentication.
+	rolePublic = ""
+)
+
+var ErrUnauthenticated = errors.New("api authentication failed")
+
+// Authenticator returns the role of the caller of a request, dcp.APIRoleReader or dcp.APIRoleAdmin.
+// It returns ErrUnauthenticated when the caller is not known.
+type Authenticator interface {
+	Authenticate(c *fiber.Ctx) (string, error)
+}
+
+// AuthenticatorFunc lets a function be an Authenticator.
+type AuthenticatorFunc func(c *fiber.Ctx) (string, error)
+
+func (f AuthenticatorFunc) Authenticate(c *fiber.Ctx) (string, error) {
+	return f(c)
+}
+
+type noneAuthenticator struct{}
+
+func (a *noneAuthenticator) Authenticate(_ *fiber.Ctx) (string, error) {
+	return dcp.APIRoleAdmin, nil
+}
+
+type basicAuthenticator struct {
+	users []dcp.APIUser
+}
+
+func (a *basicAuthenticator) Authenticate(c *fiber.Ctx) (string, error) {
+	header := c.Get(fiber.HeaderAuthorization)
+	if !strings.HasPrefix(header, basicPrefix) {
+		return "", ErrUnauthenticated
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(header, basicPrefix))
+	if err != nil {
+		return "", ErrUnauthenticated
+	}
+
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+
+	for _, user := range a.users {
+		// both are compared, so the time does not tell whether the username exists
+		usernameMatches := equal(username, user.Username)
+		if equal(password, user.Password) && usernameMatches {
+			return user.Role, nil
+		}
+	}
+
+	return "", ErrUnauthenticated
+}
+
+type bearerAuthenticator struct {
+	tokens []dcp.APIToken
+}
+
+func (a *bearerAuthenticator) Authenticate(c *fiber.Ctx) (string, error) {
+	header := c.Get(fiber.HeaderAuthorization)
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return "", ErrUnauthenticated
+	}
+
+	received := strings.TrimPrefix(header, bearerPrefix)
+
+	for _, token := range a.tokens {
+		if equal(received, token.Token) {
+			return token.Role, nil
+		}
+	}
+
+	return "", ErrUnauthenticated
+}
+
+// mtlsAuthenticator trusts the client certificates verified by the tls listener of the api.
+type mtlsAuthenticator struct {
+	adminCommonNames []string
+}
+
+func (a *mtlsAuthenticator) Authenticate(c *fiber.Ctx) (string, error) {
+	state := c.Context().TLSConnectionState()
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return "", ErrUnauthenticated
+	}
+
+	commonName := state.VerifiedChains[0][0].Subject.CommonName
+
+	for _, adminCommonName := range a.adminCommonNames {
+		if commonName == adminCommonName {
+			return dcp.APIRoleAdmin, nil
+		}
+	}
+
+	return dcp.APIRoleReader, nil
+}
+
+func equal(received string, expected string) bool {
+	return subtle.ConstantTimeCompare([]byte(received), []byte(expected)) == 1
+}
+
+// allows reports whether role can call an endpoint which requires the required role, admins can call every endpoint.
+func allows(role string, required string) bool {
+	return required == rolePublic || role == required || role == dcp.APIRoleAdmin
+}
+
+// NewAuthenticator creates the authenticator of api.auth.type.
+func NewAuthenticator(auth *dcp.APIAuth) Authenticator {
+	switch auth.Type {
+	case dcp.APIAuthTypeBasic:
+		return &basicAuthenticator{users: auth.Users}
+	case dcp.APIAuthTypeBearer:
+		return &bearerAuthenticator{tokens: auth.Tokens}
+	case dcp.APIAuthTypeMTLS:
+		return &mtlsAuthenticator{adminCommonNames: auth.AdminCommonNames}
+	default:
+		return &noneAuthenticator{}
+	}
+}
+
+// authorize fails the request unless its caller has the required role.
+func (s *api) authorize(c *fiber.Ctx, required string) error {
+	if required == rolePublic {
+		return nil
+	}
+
+	role, err := s.authenticator.Authenticate(c)
+	if err != nil {
+		switch s.config.API.Auth.Type {
+		case dcp.APIAuthTypeBasic:
+			c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="go-dcp"`)
+		case dcp.APIAuthTypeBearer:
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="go-dcp"`)
+		}
+
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+
+	if !allows(role, required) {
+		return fiber.NewError(fiber.StatusForbidden, "role "+role+" cannot call this endpoint")
+	}
+
+	return nil
+}
+
+// authenticated is the middleware of the endpoints which require the required role.
+func (s *api) authenticated(required string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := s.authorize(c, required); err != nil {
+			return err
+		}
+
+		return c.Next()
+	}
+}
+
+func (s *api) SetAuthenticator(authenticator Authenticator) {
+	s.authenticator = authenticator
+}
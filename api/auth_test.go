@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Trendyol/go-dcp/config"
+)
+
+func newTestAuthApp(auth config.APIAuth) *fiber.App {
+	s := &api{config: &config.Dcp{API: config.API{Auth: auth}}}
+	s.authenticator = NewAuthenticator(&s.config.API.Auth)
+
+	ok := func(c *fiber.Ctx) error {
+		return c.SendString("OK")
+	}
+
+	app := fiber.New()
+	app.Get("/public", s.authenticated(rolePublic), ok)
+	app.Get("/read", s.authenticated(config.APIRoleReader), ok)
+	app.Post("/write", s.authenticated(config.APIRoleAdmin), ok)
+
+	return app
+}
+
+func testAuth(t *testing.T, app *fiber.App, method string, path string, authorization string, expected int) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, nil)
+	if authorization != "" {
+		req.Header.Set(fiber.HeaderAuthorization, authorization)
+	}
+
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != expected {
+		t.Errorf("Unexpected result for %s %s. Expected: %v, Got: %v", method, path, expected, res.StatusCode)
+	}
+}
+
+func basic(username string, password string) string {
+	return basicPrefix + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+func TestBasicAuth(t *testing.T) {
+	app := newTestAuthApp(config.APIAuth{
+		Type: config.APIAuthTypeBasic,
+		Users: []config.APIUser{
+			{Username: "viewer", Password: "secret", Role: config.APIRoleReader},
+			{Username: "ops", Password: "secret", Role: config.APIRoleAdmin},
+		},
+	})
+
+	testAuth(t, app, "GET", "/public", "", 200)
+	testAuth(t, app, "GET", "/read", "", 401)
+	testAuth(t, app, "GET", "/read", basic("viewer", "wrong"), 401)
+	testAuth(t, app, "GET", "/read", basic("viewer", "secret"), 200)
+	testAuth(t, app, "POST", "/write", basic("viewer", "secret"), 403)
+	testAuth(t, app, "POST", "/write", basic("ops", "secret"), 200)
+}
+
+func TestBearerAuth(t *testing.T) {
+	app := newTestAuthApp(config.APIAuth{
+		Type:   config.APIAuthTypeBearer,
+		Tokens: []config.APIToken{{Token: "reader-token", Role: config.APIRoleReader}},
+	})
+
+	testAuth(t, app, "GET", "/read", bearerPrefix+"unknown", 401)
+	testAuth(t, app, "GET", "/read", bearerPrefix+"reader-token", 200)
+	testAuth(t, app, "POST", "/write", bearerPrefix+"reader-token", 403)
+}
+
+func TestMTLSAuthWithoutCertificate(t *testing.T) {
+	app := newTestAuthApp(config.APIAuth{Type: config.APIAuthTypeMTLS, AdminCommonNames: []string{"ops"}})
+
+	testAuth(t, app, "GET", "/public", "", 200)
+	testAuth(t, app, "GET", "/read", "", 401)
+}
+
+func TestNoneAuth(t *testing.T) {
+	app := newTestAuthApp(config.APIAuth{Type: config.APIAuthTypeNone})
+
+	testAuth(t, app, "POST", "/write", "", 200)
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	_ "embed"
+	"sort"
+	"time"
+
+	"github.com/Trendyol/go-dcp/membership"
+	"github.com/Trendyol/go-dcp/models"
+	"github.com/Trendyol/go-dcp/stream"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+//go:embed dashboard.html
+var dashboardPage []byte
+
+type dashboardGroup struct {
+	Name              string `json:"name"`
+	MembershipType    string `json:"membershipType"`
+	MemberNumber      int    `json:"memberNumber"`
+	TotalMembers      int    `json:"totalMembers"`
+	VBucketCount      int    `json:"vBucketCount"`
+	VBucketRangeStart uint16 `json:"vBucketRangeStart"`
+	VBucketRangeEnd   uint16 `json:"vBucketRangeEnd"`
+	FencingToken      uint64 `json:"fencingToken"`
+}
+
+type dashboardVBucket struct {
+	SeqNo         uint64   `json:"seqNo"`
+	StartSeqNo    uint64   `json:"startSeqNo"`
+	EndSeqNo      uint64   `json:"endSeqNo"`
+	SeqNoLag      *uint64  `json:"seqNoLag,omitempty"`
+	SecondsBehind *float64 `json:"secondsBehind,omitempty"`
+	VbID          uint16   `json:"vbId"`
+	Dirty         bool     `json:"dirty"`
+}
+
+type dashboardCheckpoint struct {
+	LastSavedAt             *time.Time `json:"lastSavedAt,omitempty"`
+	AgeSeconds              *float64   `json:"ageSeconds,omitempty"`
+	OffsetWrite             int        `json:"offsetWrite"`
+	ConsecutiveSaveFailures int        `json:"consecutiveSaveFailures"`
+}
+
+type dashboardData struct {
+	GeneratedAt time.Time                `json:"generatedAt"`
+	Group       dashboardGroup           `json:"group"`
+	Health      stream.Health            `json:"health"`
+	Checkpoint  dashboardCheckpoint      `json:"checkpoint"`
+	Members     []membership.Member      `json:"members"`
+	Followers   []string                 `json:"followers"`
+	VBuckets    []dashboardVBucket       `json:"vBuckets"`
+	LagError    string                   `json:"lagError,omitempty"`
+	Rebalances  []stream.RebalanceRecord `json:"rebalances"`
+	Errors      []stream.ErrorRecord     `json:"errors"`
+}
+
+func (s *api) dashboard(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Send(dashboardPage)
+}
+
+// dashboardData is polled by the dashboard page, it only reads what the stream and the discoveries already keep.
+func (s *api) dashboardData(c *fiber.Ctx) error {
+	vBucketDiscoveryMetric := s.vBucketDiscovery.GetMetric()
+
+	data := dashboardData{
+		GeneratedAt: time.Now(),
+		Group: dashboardGroup{
+			Name:              s.config.Dcp.Group.Name,
+			MembershipType:    vBucketDiscoveryMetric.Type,
+			MemberNumber:      vBucketDiscoveryMetric.MemberNumber,
+			TotalMembers:      vBucketDiscoveryMetric.TotalMembers,
+			VBucketCount:      vBucketDiscoveryMetric.VBucketCount,
+			VBucketRangeStart: vBucketDiscoveryMetric.VBucketRangeStart,
+			VBucketRangeEnd:   vBucketDiscoveryMetric.VBucketRangeEnd,
+			FencingToken:      vBucketDiscoveryMetric.Epoch,
+		},
+		Health:  s.stream.GetHealth(),
+		Members: s.vBucketDiscovery.GetMembers(),
+	}
+
+	if s.serviceDiscovery != nil {
+		data.Followers = s.serviceDiscovery.GetAll()
+	}
+
+	// the checkpoint is created when the stream is opened
+	if data.Health.Open {
+		data.Checkpoint = newDashboardCheckpoint(s.stream.GetCheckpointMetric())
+	}
+
+	if streamMetric, _ := s.stream.GetMetric(); streamMetric != nil {
+		data.Rebalances = streamMetric.Rebalances.List()
+		data.Errors = streamMetric.Errors.List()
+	}
+
+	lags, err := s.stream.GetLags()
+	if err != nil {
+		data.LagError = err.Error()
+	}
+
+	data.VBuckets = s.dashboardVBuckets(lags)
+
+	return c.JSON(data)
+}
+
+func (s *api) dashboardVBuckets(lags []stream.VBucketLag) []dashboardVBucket {
+	offsets, dirtyOffsets, _ := s.stream.GetOffsets()
+	if offsets == nil {
+		return nil
+	}
+
+	lagsByVbID := make(map[uint16]stream.VBucketLag, len(lags))
+	for _, lag := range lags {
+		// the lags of the sources are left out, the offsets are the ones of the top level bucket
+		if lag.Bucket == s.config.BucketName {
+			lagsByVbID[lag.VbID] = lag
+		}
+	}
+
+	vBuckets := make([]dashboardVBucket, 0, offsets.Count())
+
+	offsets.Range(func(vbID uint16, offset *models.Offset) bool {
+		vBucket := dashboardVBucket{
+			VbID:  vbID,
+			SeqNo: offset.SeqNo,
+		}
+
+		if offset.SnapshotMarker != nil {
+			vBucket.StartSeqNo, vBucket.EndSeqNo = offset.StartSeqNo, offset.EndSeqNo
+		}
+
+		if dirtyOffsets != nil {
+			vBucket.Dirty, _ = dirtyOffsets.Load(vbID)
+		}
+
+		if lag, ok := lagsByVbID[vbID]; ok {
+			vBucket.SeqNoLag, vBucket.SecondsBehind = &lag.SeqNoLag, &lag.SecondsBehind
+		}
+
+		vBuckets = append(vBuckets, vBucket)
+
+		return true
+	})
+
+	sort.Slice(vBuckets, func(i, j int) bool {
+		return vBuckets[i].VbID < vBuckets[j].VbID
+	})
+
+	return vBuckets
+}
+
+func newDashboardCheckpoint(checkpointMetric *stream.CheckpointMetric) dashboardCheckpoint {
+	checkpoint := dashboardCheckpoint{
+		OffsetWrite:             checkpointMetric.OffsetWrite,
+		ConsecutiveSaveFailures: int(checkpointMetric.ConsecutiveSaveFailures.Load()),
+	}
+
+	if lastSavedAtNano := checkpointMetric.LastSavedAt.Load(); lastSavedAtNano != 0 {
+		lastSavedAt := time.Unix(0, lastSavedAtNano)
+		age := time.Since(lastSavedAt).Seconds()
+		checkpoint.LastSavedAt, checkpoint.AgeSeconds = &lastSavedAt, &age
+	}
+
+	return checkpoint
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/membership"
+	"github.com/Trendyol/go-dcp/models"
+	"github.com/Trendyol/go-dcp/stream"
+	"github.com/Trendyol/go-dcp/wrapper"
+)
+
+type testDashboardStream struct {
+	stream.Stream
+	metric *stream.Metric
+}
+
+func (s *testDashboardStream) GetOffsets() (*wrapper.ConcurrentSwissMap[uint16, *models.Offset], *wrapper.ConcurrentSwissMap[uint16, bool], bool) { //nolint:lll
+	offsets := wrapper.CreateConcurrentSwissMap[uint16, *models.Offset](2)
+	offsets.Store(1, &models.Offset{SeqNo: 20, SnapshotMarker: &models.SnapshotMarker{StartSeqNo: 10, EndSeqNo: 30}})
+	offsets.Store(0, &models.Offset{SeqNo: 5})
+
+	dirtyOffsets := wrapper.CreateConcurrentSwissMap[uint16, bool](2)
+	dirtyOffsets.Store(1, true)
+
+	return offsets, dirtyOffsets, true
+}
+
+func (s *testDashboardStream) GetLags() ([]stream.VBucketLag, error) {
+	return nil, errors.New("high seq nos are not sampled yet")
+}
+
+func (s *testDashboardStream) GetHealth() stream.Health {
+	return stream.Health{}
+}
+
+func (s *testDashboardStream) GetMetric() (*stream.Metric, int) {
+	return s.metric, 0
+}
+
+type testDashboardVBucketDiscovery struct {
+	stream.VBucketDiscovery
+}
+
+func (d *testDashboardVBucketDiscovery) GetMetric() *stream.VBucketDiscoveryMetric {
+	return &stream.VBucketDiscoveryMetric{Type: "static", MemberNumber: 1, TotalMembers: 2, VBucketRangeEnd: 511}
+}
+
+func (d *testDashboardVBucketDiscovery) GetMembers() []membership.Member {
+	return []membership.Member{{Name: "first", MemberNumber: 1, TotalMembers: 2, Self: true}}
+}
+
+func TestDashboardData(t *testing.T) {
+	metric := &stream.Metric{
+		Rebalances: stream.NewHistory[stream.RebalanceRecord](stream.RebalanceHistorySize),
+		Errors:     stream.NewHistory[stream.ErrorRecord](stream.ErrorHistorySize),
+	}
+	metric.Errors.Add(stream.ErrorRecord{Op: "rebalance", Error: "cannot open stream"})
+
+	s := &api{
+		config:           &config.Dcp{API: config.API{Dashboard: config.Dashboard{Enabled: true}}},
+		stream:           &testDashboardStream{metric: metric},
+		vBucketDiscovery: &testDashboardVBucketDiscovery{},
+	}
+	s.config.Dcp.Group.Name = "group"
+
+	app := fiber.New()
+	app.Get("/dashboard/data", s.dashboardData)
+
+	res, err := app.Test(httptest.NewRequest("GET", "/dashboard/data", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var data dashboardData
+	if err := json.NewDecoder(res.Body).Decode(&data); err != nil {
+		t.Fatal(err)
+	}
+
+	if data.Group.Name != "group" || data.Group.VBucketRangeEnd != 511 || len(data.Members) != 1 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %+v", "group of 2 members", data.Group)
+	}
+
+	if len(data.VBuckets) != 2 || data.VBuckets[0].VbID != 0 || data.VBuckets[1].EndSeqNo != 30 || !data.VBuckets[1].Dirty {
+		t.Errorf("Unexpected result. Expected: %v, Got: %+v", "vBuckets 0 and 1", data.VBuckets)
+	}
+
+	if data.LagError == "" || data.VBuckets[1].SecondsBehind != nil {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "unknown lag", data.LagError)
+	}
+
+	if len(data.Errors) != 1 || data.Errors[0].Op != "rebalance" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "rebalance error", data.Errors)
+	}
+}
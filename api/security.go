@@ -0,0 +1,62 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+
+	dcp "github.com/Trendyol/go-dcp/config"
+)
+
+// listen serves the api on api.port, over TLS when api.tls.enabled is set.
+func (s *api) listen() error {
+	address := fmt.Sprintf(":%d", s.config.API.Port)
+
+	if !s.config.API.TLS.Enabled {
+		return s.app.Listen(address)
+	}
+
+	tlsConfig, err := newServerTLSConfig(&s.config.API.TLS)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	return s.app.Listener(tls.NewListener(listener, tlsConfig))
+}
+
+func newServerTLSConfig(tlsConfig *dcp.APITLS) (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(os.ExpandEnv(tlsConfig.CertPath), os.ExpandEnv(tlsConfig.KeyPath))
+	if err != nil {
+		return nil, err
+	}
+
+	serverTLSConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if tlsConfig.RootCAPath != "" {
+		cert, err := os.ReadFile(os.ExpandEnv(tlsConfig.RootCAPath))
+		if err != nil {
+			return nil, err
+		}
+
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(cert) {
+			return nil, fmt.Errorf("no certificate found in %s", tlsConfig.RootCAPath)
+		}
+
+		// probes of the public endpoints do not have a certificate, the others are rejected by the authenticator
+		serverTLSConfig.ClientCAs = clientCAs
+		serverTLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return serverTLSConfig, nil
+}
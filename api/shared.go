@@ -0,0 +1,285 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/Trendyol/go-dcp/metric"
+	"github.com/prometheus/client_golang/prometheus"
+
+	dcp "github.com/Trendyol/go-dcp/config"
+
+	"github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/servicediscovery"
+	"github.com/Trendyol/go-dcp/stream"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ConnectorLabel is added to the metrics of every instance attached to a shared api.
+const ConnectorLabel = "connector"
+
+var (
+	ErrConnectorAlreadyAttached = errors.New("connector is already attached")
+	// ErrSharedAPIMTLS is returned for a connector with mtls auth, the shared api is not served over tls.
+	ErrSharedAPIMTLS = errors.New("mtls auth cannot be used with a shared api")
+)
+
+// SharedAPI serves the admin api of several Dcp instances on one port.
+// Endpoints of an instance are served under /connectors/{name}, metrics of instances are told apart by the connector label.
+type SharedAPI interface {
+	Listen()
+	Shutdown() error
+	// SetAuthenticator authenticates the callers of /connectors and the metric path as readers, it is set before Listen.
+	SetAuthenticator(authenticator Authenticator)
+	Attach(name string,
+		config *dcp.Dcp,
+		client couchbase.Client,
+		stream stream.Stream,
+		serviceDiscovery servicediscovery.ServiceDiscovery,
+		vBucketDiscovery stream.VBucketDiscovery,
+		collectors []prometheus.Collector,
+	) (API, error)
+}
+
+type sharedAPI struct {
+	app           *fiber.App
+	registry      metric.Registry
+	logger        logger.Logger
+	authenticator Authenticator
+	instances     map[string]*sharedInstance
+	lock          sync.RWMutex
+	port          int
+}
+
+type sharedInstance struct {
+	api    *api
+	shared *sharedAPI
+	routes map[string]route
+	name   string
+}
+
+func (s *sharedAPI) Listen() {
+	s.logger.Info("shared api starting on port %d", s.port)
+
+	err := s.app.Listen(fmt.Sprintf(":%d", s.port))
+
+	if err != nil {
+		s.logger.Error("shared api cannot start on port %d, err: %v", s.port, err)
+	} else {
+		s.logger.Info("shared api stopped")
+	}
+}
+
+func (s *sharedAPI) Shutdown() error {
+	for _, instance := range s.getInstances() {
+		instance.api.close()
+	}
+
+	err := s.app.Shutdown()
+	if err != nil {
+		return fmt.Errorf("shared api cannot be shutdown: %w", err)
+	}
+
+	return nil
+}
+
+func (s *sharedAPI) Attach(name string,
+	config *dcp.Dcp,
+	client couchbase.Client,
+	stream stream.Stream,
+	serviceDiscovery servicediscovery.ServiceDiscovery,
+	vBucketDiscovery stream.VBucketDiscovery,
+	collectors []prometheus.Collector,
+) (API, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.instances[name]; ok {
+		return nil, fmt.Errorf("name: %s, err: %w", name, ErrConnectorAlreadyAttached)
+	}
+
+	if config.API.Auth.Type == dcp.APIAuthTypeMTLS {
+		return nil, fmt.Errorf("name: %s, err: %w", name, ErrSharedAPIMTLS)
+	}
+
+	api := &api{
+		config:           config,
+		client:           client,
+		stream:           stream,
+		serviceDiscovery: serviceDiscovery,
+		vBucketDiscovery: vBucketDiscovery,
+		registerer:       metric.WrapWithRegisterer(prometheus.WrapRegistererWith(prometheus.Labels{ConnectorLabel: name}, s.registry)),
+		logger:           s.logger,
+		authenticator:    NewAuthenticator(&config.API.Auth),
+		closeCh:          make(chan struct{}),
+	}
+
+	if err := api.registerer.RegisterAll(collectors); err != nil {
+		api.registerer.UnregisterAll()
+		return nil, fmt.Errorf("cannot register metric collectors of %s: %w", name, err)
+	}
+
+	instance := &sharedInstance{
+		name:   name,
+		api:    api,
+		shared: s,
+		routes: api.routes(),
+	}
+
+	s.instances[name] = instance
+
+	s.logger.Info("connector %s attached to shared api", name)
+
+	return instance, nil
+}
+
+func (s *sharedAPI) SetAuthenticator(authenticator Authenticator) {
+	s.authenticator = authenticator
+}
+
+// authenticated is the middleware of the own endpoints of the shared api which are not public.
+func (s *sharedAPI) authenticated(c *fiber.Ctx) error {
+	role, err := s.authenticator.Authenticate(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+
+	if !allows(role, dcp.APIRoleReader) {
+		return fiber.NewError(fiber.StatusForbidden, "role "+role+" cannot call this endpoint")
+	}
+
+	return c.Next()
+}
+
+func (s *sharedAPI) detach(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.instances, name)
+
+	s.logger.Info("connector %s detached from shared api", name)
+}
+
+func (s *sharedAPI) getInstance(name string) (*sharedInstance, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	instance, ok := s.instances[name]
+	return instance, ok
+}
+
+func (s *sharedAPI) getInstances() []*sharedInstance {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	instances := make([]*sharedInstance, 0, len(s.instances))
+	for _, instance := range s.instances {
+		instances = append(instances, instance)
+	}
+
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].name < instances[j].name
+	})
+
+	return instances
+}
+
+func (s *sharedAPI) connectors(c *fiber.Ctx) error {
+	instances := s.getInstances()
+
+	names := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		names = append(names, instance.name)
+	}
+
+	return c.JSON(names)
+}
+
+// status is OK when every attached instance with health check enabled can ping its cluster.
+func (s *sharedAPI) status(c *fiber.Ctx) error {
+	for _, instance := range s.getInstances() {
+		if instance.api.config.HealthCheck.Disabled {
+			continue
+		}
+
+		if _, err := instance.api.client.Ping(c.UserContext()); err != nil {
+			return fmt.Errorf("connector %s is not healthy: %w", instance.name, err)
+		}
+	}
+
+	return c.SendString("OK")
+}
+
+func (s *sharedAPI) forward(c *fiber.Ctx) error {
+	instance, ok := s.getInstance(c.Params("name"))
+	if !ok {
+		return fiber.ErrNotFound
+	}
+
+	route, ok := instance.routes["/"+c.Params("*")]
+	if !ok {
+		return fiber.ErrNotFound
+	}
+
+	if route.method != c.Method() {
+		return fiber.ErrMethodNotAllowed
+	}
+
+	if err := instance.api.authorize(c, route.role); err != nil {
+		return err
+	}
+
+	return route.handler(c)
+}
+
+func (i *sharedInstance) Listen() {
+}
+
+func (i *sharedInstance) Shutdown() error {
+	i.shared.detach(i.name)
+	i.api.close()
+	return nil
+}
+
+func (i *sharedInstance) SetReloader(reloader Reloader) {
+	i.api.SetReloader(reloader)
+}
+
+func (i *sharedInstance) SetAuthenticator(authenticator Authenticator) {
+	i.api.SetAuthenticator(authenticator)
+}
+
+func (i *sharedInstance) UnregisterMetricCollectors() {
+	i.api.UnregisterMetricCollectors()
+}
+
+// NewSharedAPI creates an api which is listened and shut down by its owner, not by the attached instances.
+func NewSharedAPI(port int, metricPath string, registry metric.Registry, logger logger.Logger) SharedAPI {
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+
+	shared := &sharedAPI{
+		app:           app,
+		port:          port,
+		registry:      registry,
+		logger:        logger,
+		authenticator: &noneAuthenticator{},
+		instances:     map[string]*sharedInstance{},
+	}
+
+	app.Use(metricPath, shared.authenticated)
+
+	if err := registerMetricMiddleware(app, "", metricPath, registry, logger); err != nil {
+		logger.Error("metric middleware cannot be initialized: %v", err)
+	}
+
+	app.Get("/status", shared.status)
+	app.Get("/connectors", shared.authenticated, shared.connectors)
+	app.Get("/connectors/:name/*", shared.forward)
+	app.Post("/connectors/:name/*", shared.forward)
+
+	return shared
+}
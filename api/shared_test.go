@@ -0,0 +1,140 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+func newTestSharedAPI() *sharedAPI {
+	return NewSharedAPI(8080, "/metrics", prometheus.NewRegistry(), &logger.Loggers{Logrus: logrus.New()}).(*sharedAPI)
+}
+
+func TestSharedAPIAttach(t *testing.T) {
+	shared := newTestSharedAPI()
+	c := &config.Dcp{HealthCheck: config.HealthCheck{Disabled: true}}
+
+	if _, err := shared.Attach("first", c, nil, nil, nil, nil, nil); err != nil {
+		t.Fatalf("Unexpected error. Got: %v", err)
+	}
+
+	if _, err := shared.Attach("first", c, nil, nil, nil, nil, nil); !errors.Is(err, ErrConnectorAlreadyAttached) {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", ErrConnectorAlreadyAttached, err)
+	}
+
+	if _, err := shared.Attach("second", c, nil, nil, nil, nil, nil); err != nil {
+		t.Fatalf("Unexpected error. Got: %v", err)
+	}
+
+	res, err := shared.app.Test(httptest.NewRequest("GET", "/connectors", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := io.ReadAll(res.Body)
+	if string(body) != `["first","second"]` {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", `["first","second"]`, string(body))
+	}
+}
+
+func TestSharedAPIForward(t *testing.T) {
+	shared := newTestSharedAPI()
+	c := &config.Dcp{HealthCheck: config.HealthCheck{Disabled: true}}
+
+	instance, err := shared.Attach("first", c, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error. Got: %v", err)
+	}
+
+	for path, expected := range map[string]int{
+		"/connectors/first/status":         404,
+		"/connectors/first/states/offset":  404,
+		"/connectors/unknown/rebalance":    404,
+		"/connectors/first/rebalance":      405,
+		"/connectors/first/unknown/status": 404,
+	} {
+		res, err := shared.app.Test(httptest.NewRequest("GET", path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.StatusCode != expected {
+			t.Errorf("Unexpected result for %s. Expected: %v, Got: %v", path, expected, res.StatusCode)
+		}
+	}
+
+	if _, ok := shared.getInstance("first"); !ok {
+		t.Fatalf("Expected attached connector")
+	}
+
+	_ = instance.Shutdown()
+
+	if _, ok := shared.getInstance("first"); ok {
+		t.Errorf("Expected detached connector")
+	}
+}
+
+func TestSharedAPIAttachMTLS(t *testing.T) {
+	shared := newTestSharedAPI()
+	c := &config.Dcp{API: config.API{Auth: config.APIAuth{Type: config.APIAuthTypeMTLS}}}
+
+	if _, err := shared.Attach("first", c, nil, nil, nil, nil, nil); !errors.Is(err, ErrSharedAPIMTLS) {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", ErrSharedAPIMTLS, err)
+	}
+
+	if _, ok := shared.getInstance("first"); ok {
+		t.Errorf("Expected connector not attached")
+	}
+}
+
+func TestSharedAPIAuthenticator(t *testing.T) {
+	shared := newTestSharedAPI()
+	shared.SetAuthenticator(AuthenticatorFunc(func(c *fiber.Ctx) (string, error) {
+		switch c.Get(fiber.HeaderAuthorization) {
+		case "reader":
+			return config.APIRoleReader, nil
+		case "unknown":
+			return "unknown", nil
+		default:
+			return "", ErrUnauthenticated
+		}
+	}))
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		expected      int
+	}{
+		{name: "metrics without caller", path: "/metrics", expected: 401},
+		{name: "metrics of reader", path: "/metrics", authorization: "reader", expected: 200},
+		{name: "connectors without caller", path: "/connectors", expected: 401},
+		{name: "connectors of unknown role", path: "/connectors", authorization: "unknown", expected: 403},
+		{name: "connectors of reader", path: "/connectors", authorization: "reader", expected: 200},
+		{name: "status", path: "/status", expected: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(fiber.MethodGet, tt.path, nil)
+			req.Header.Set(fiber.HeaderAuthorization, tt.authorization)
+
+			res, err := shared.app.Test(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.StatusCode != tt.expected {
+				t.Errorf("Unexpected result. Expected: %v, Got: %v", tt.expected, res.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Trendyol/go-dcp/stream"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	tailFormatSSE    = "sse"
+	tailFormatNDJSON = "ndjson"
+
+	tailHeartbeatInterval = 15 * time.Second
+)
+
+// tail streams a copy of the events handed to the listener as server-sent events, or as NDJSON with format=ndjson.
+// The collection, vbId, keyPrefix, truncate and redact queries filter the events, acks and checkpoints are not affected.
+func (s *api) tail(c *fiber.Ctx) error {
+	format := c.Query("format", tailFormatSSE)
+	if format != tailFormatSSE && format != tailFormatNDJSON {
+		return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("format %q is not one of sse, ndjson", format))
+	}
+
+	filter, err := s.tailFilter(c)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if format == tailFormatSSE {
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+	} else {
+		c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set("X-Accel-Buffering", "no")
+
+	subscription := s.stream.GetTail().Subscribe(filter, s.config.API.Tail.BufferSize)
+
+	s.logger.Info("tail subscribed from %s, filter: %+v", c.IP(), filter)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer subscription.Close()
+
+		s.writeTail(w, subscription, format == tailFormatSSE)
+
+		s.logger.Info("tail unsubscribed, dropped events: %d", subscription.Dropped())
+	})
+
+	return nil
+}
+
+func (s *api) tailFilter(c *fiber.Ctx) (stream.TailFilter, error) {
+	filter := stream.TailFilter{
+		KeyPrefix:    c.Query("keyPrefix"),
+		Collections:  splitQuery(c.Query("collection")),
+		MaxValueSize: s.config.API.Tail.MaxValueSize,
+		Redact:       c.QueryBool("redact", false),
+	}
+
+	for _, vbID := range splitQuery(c.Query("vbId")) {
+		id, err := strconv.ParseUint(vbID, 10, 16)
+		if err != nil {
+			return filter, fmt.Errorf("vbId %q is not valid", vbID)
+		}
+
+		filter.VbIDs = append(filter.VbIDs, uint16(id))
+	}
+
+	truncate := c.QueryInt("truncate", 0)
+	if truncate > 0 && (filter.MaxValueSize <= 0 || truncate < filter.MaxValueSize) {
+		filter.MaxValueSize = truncate
+	}
+
+	return filter, nil
+}
+
+// writeTail writes the events until the client goes away or the api is shut down,
+// heartbeats find out gone clients while no event matches and report the dropped events.
+func (s *api) writeTail(w *bufio.Writer, subscription *stream.TailSubscription, sse bool) {
+	heartbeat := time.NewTicker(tailHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	var reportedDropped uint64
+
+	// the headers are sent with the first write, clients would wait for the first event otherwise
+	writeTailHeartbeat(w, sse)
+
+	if err := w.Flush(); err != nil {
+		return
+	}
+
+	for {
+		select {
+		case <-s.closeCh:
+			return
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+
+			if err := writeTailEvent(w, &event, sse); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if dropped := subscription.Dropped(); dropped != reportedDropped {
+				reportedDropped = dropped
+				writeTailDropped(w, dropped, sse)
+			} else {
+				writeTailHeartbeat(w, sse)
+			}
+		}
+
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeTailEvent(w *bufio.Writer, event *stream.TailEvent, sse bool) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if sse {
+		_, err = fmt.Fprintf(w, "event: %s\nid: %d:%d\ndata: %s\n\n", event.Type, event.VbID, event.SeqNo, data)
+	} else {
+		_, err = fmt.Fprintf(w, "%s\n", data)
+	}
+
+	return err
+}
+
+func writeTailHeartbeat(w *bufio.Writer, sse bool) {
+	if sse {
+		_, _ = w.WriteString(": heartbeat\n\n")
+	} else {
+		_, _ = w.WriteString("\n")
+	}
+}
+
+func writeTailDropped(w *bufio.Writer, dropped uint64, sse bool) {
+	if sse {
+		_, _ = fmt.Fprintf(w, "event: dropped\ndata: {\"dropped\":%d}\n\n", dropped)
+	} else {
+		_, _ = fmt.Fprintf(w, "{\"dropped\":%d}\n", dropped)
+	}
+}
+
+func splitQuery(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/stream"
+)
+
+type testTailStream struct {
+	stream.Stream
+	tail *stream.Tail
+}
+
+func (s *testTailStream) GetTail() *stream.Tail {
+	return s.tail
+}
+
+func TestTailEndsOnClose(t *testing.T) {
+	s := &api{
+		config:  &config.Dcp{API: config.API{Tail: config.Tail{BufferSize: 10}}},
+		stream:  &testTailStream{tail: stream.NewTail()},
+		logger:  &logger.Loggers{Logrus: logrus.New()},
+		closeCh: make(chan struct{}),
+	}
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Get("/tail", s.tail)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		_ = app.Listener(listener)
+	}()
+
+	defer func() {
+		_ = app.Shutdown()
+	}()
+
+	res, err := http.Get("http://" + listener.Addr().String() + "/tail") //nolint:noctx
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if contentType := res.Header.Get(fiber.HeaderContentType); contentType != "text/event-stream" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "text/event-stream", contentType)
+	}
+
+	buf := make([]byte, 64)
+
+	n, err := res.Body.Read(buf)
+	if err != nil || string(buf[:n]) != ": heartbeat\n\n" {
+		t.Errorf("Unexpected result. Expected: %q, Got: %q, %v", ": heartbeat\n\n", buf[:n], err)
+	}
+
+	s.close()
+
+	if _, err := io.ReadAll(res.Body); err != nil && !errors.Is(err, io.EOF) {
+		t.Errorf("Unexpected error. Got: %v", err)
+	}
+}
+
+func TestTailFilterMaxValueSize(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		maxValueSize int
+		expected     int
+	}{
+		{name: "configured", query: "", maxValueSize: 1024, expected: 1024},
+		{name: "truncated", query: "?truncate=16", maxValueSize: 1024, expected: 16},
+		{name: "not extended", query: "?truncate=2048", maxValueSize: 1024, expected: 1024},
+		{name: "whole values", query: "", maxValueSize: -1, expected: -1},
+		{name: "whole values truncated", query: "?truncate=16", maxValueSize: -1, expected: 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &api{config: &config.Dcp{API: config.API{Tail: config.Tail{MaxValueSize: tt.maxValueSize}}}}
+
+			app := fiber.New(fiber.Config{DisableStartupMessage: true})
+			app.Get("/tail", func(c *fiber.Ctx) error {
+				filter, err := s.tailFilter(c)
+				if err != nil {
+					return err
+				}
+
+				return c.JSON(filter.MaxValueSize)
+			})
+
+			res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/tail"+tt.query, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+
+			body, _ := io.ReadAll(res.Body)
+			if string(body) != strconv.Itoa(tt.expected) {
+				t.Errorf("Unexpected result. Expected: %v, Got: %s", tt.expected, body)
+			}
+		})
+	}
+}
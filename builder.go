@@ -0,0 +1,269 @@
+package dcp
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Trendyol/go-dcp/api"
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/membership"
+	"github.com/Trendyol/go-dcp/metadata"
+	"github.com/Trendyol/go-dcp/metric"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+var (
+	ErrConfigNotSet   = errors.New("config is not set")
+	ErrListenerNotSet = errors.New("listener is not set")
+)
+
+// Option configures a Dcp created by New.
+type Option func(b *builder)
+
+type builder struct {
+	config           *config.Dcp
+	listener         models.Listener
+	metadata         metadata.Metadata
+	membership       membership.Membership
+	eventHandler     models.EventHandler
+	errorHandler     models.ErrorHandler
+	metricCollectors []prometheus.Collector
+	errs             []error
+	configPath       string
+	options          Options
+	configSet        bool
+}
+
+func (b *builder) addError(option string, err error) {
+	b.errs = append(b.errs, fmt.Errorf("%s: %w", option, err))
+}
+
+// WithConfig sets the configuration of the connector.
+func WithConfig(config *config.Dcp) Option {
+	return func(b *builder) {
+		if config == nil {
+			b.addError("WithConfig", ErrConfigNotSet)
+			return
+		}
+
+		if b.configSet {
+			b.addError("WithConfig", errors.New("config is set more than once"))
+			return
+		}
+
+		b.config = config
+		b.configSet = true
+	}
+}
+
+// WithConfigFile reads the configuration of the connector from a yaml file.
+func WithConfigFile(path string) Option {
+	return func(b *builder) {
+		c, err := newDcpConfig(path)
+		if err != nil {
+			b.addError("WithConfigFile", err)
+			b.configSet = true
+			return
+		}
+
+		WithConfig(&c)(b)
+		b.configPath = path
+	}
+}
+
+// WithListener sets the callback called for every mutation, deletion and expiration.
+func WithListener(listener models.Listener) Option {
+	return func(b *builder) {
+		b.listener = listener
+	}
+}
+
+// WithMetadata replaces the metadata built from metadata.type of the config.
+func WithMetadata(metadata metadata.Metadata) Option {
+	return func(b *builder) {
+		if metadata == nil {
+			b.addError("WithMetadata", errors.New("metadata is nil"))
+			return
+		}
+
+		b.metadata = metadata
+	}
+}
+
+// WithMembership replaces the membership built from dcp.group.membership.type of the config.
+// The streams are rebalanced on its changes if it implements membership.Watcher.
+func WithMembership(membership membership.Membership) Option {
+	return func(b *builder) {
+		if membership == nil {
+			b.addError("WithMembership", errors.New("membership is nil"))
+			return
+		}
+
+		b.membership = membership
+	}
+}
+
+// WithLogger sets the logger of the connector, logger.Log is used by default.
+func WithLogger(logger logger.Logger) Option {
+	return func(b *builder) {
+		if logger == nil {
+			b.addError("WithLogger", errors.New("logger is nil"))
+			return
+		}
+
+		b.options.Logger = logger
+	}
+}
+
+// WithEventHandler sets the handler called before and after stream and rebalance changes.
+func WithEventHandler(handler models.EventHandler) Option {
+	return func(b *builder) {
+		if handler == nil {
+			b.addError("WithEventHandler", errors.New("event handler is nil"))
+			return
+		}
+
+		b.eventHandler = handler
+	}
+}
+
+// WithErrorHandler sets the handler called with failures of background operations.
+func WithErrorHandler(handler models.ErrorHandler) Option {
+	return func(b *builder) {
+		b.errorHandler = handler
+	}
+}
+
+// WithMetricRegistry sets the registry metrics are registered to, the prometheus default registry is used by default.
+func WithMetricRegistry(registry metric.Registry) Option {
+	return func(b *builder) {
+		b.options.MetricRegistry = registry
+	}
+}
+
+// WithMetricCollectors adds collectors served with the metrics of the connector.
+func WithMetricCollectors(collectors ...prometheus.Collector) Option {
+	return func(b *builder) {
+		b.metricCollectors = append(b.metricCollectors, collectors...)
+	}
+}
+
+// WithSharedAPI serves the api of the connector on shared under /connectors/{name}.
+func WithSharedAPI(shared api.SharedAPI, name string) Option {
+	return func(b *builder) {
+		b.options.SharedAPI = shared
+		b.options.Name = name
+	}
+}
+
+// WithName sets the connector label of the metrics.
+func WithName(name string) Option {
+	return func(b *builder) {
+		b.options.Name = name
+	}
+}
+
+// WithTracerProvider records the spans of events, checkpoint saves and rebalances on provider.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(b *builder) {
+		b.options.TracerProvider = provider
+	}
+}
+
+// WithAPIAuthenticator authenticates the callers of the api with authenticator instead of the one of api.auth.type.
+func WithAPIAuthenticator(authenticator api.Authenticator) Option {
+	return func(b *builder) {
+		b.options.APIAuthenticator = authenticator
+	}
+}
+
+// WithoutSignalHandling stops the connector from closing on SIGTERM and SIGINT, use StartContext to stop it.
+func WithoutSignalHandling() Option {
+	return func(b *builder) {
+		b.options.DisableSignalHandling = true
+	}
+}
+
+// validate reports every invalid option and combination at once.
+func (b *builder) validate() error {
+	errs := b.errs
+
+	if b.listener == nil {
+		errs = append(errs, ErrListenerNotSet)
+	}
+
+	if b.config == nil {
+		if !b.configSet {
+			errs = append(errs, ErrConfigNotSet)
+		}
+
+		return errors.Join(errs...)
+	}
+
+	if err := b.config.ApplyDefaults(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := b.config.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if b.metadata != nil && len(b.config.Sources) > 0 {
+		errs = append(errs, errors.New("custom metadata cannot be used with sources, they need their own checkpoints"))
+	}
+
+	if b.membership != nil && b.config.LeaderElection.Enabled {
+		errs = append(errs, errors.New("custom membership cannot be used with leader election"))
+	}
+
+	if b.options.SharedAPI != nil && b.config.API.Disabled {
+		errs = append(errs, errors.New("shared api cannot be used when api is disabled"))
+	}
+
+	if b.options.SharedAPI != nil && b.config.API.Auth.Type == config.APIAuthTypeMTLS {
+		errs = append(errs, api.ErrSharedAPIMTLS)
+	}
+
+	return errors.Join(errs...)
+}
+
+// New creates a Dcp from options, every problem of the options is returned at once before connecting.
+//
+//	connector, err := dcp.New(
+//		dcp.WithConfigFile("config.yml"),
+//		dcp.WithListener(listener),
+//		dcp.WithLogger(myLogger),
+//	)
+func New(options ...Option) (Dcp, error) {
+	b := &builder{}
+
+	for _, option := range options {
+		option(b)
+	}
+
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	connector, err := newDcp(b.config, b.listener, b.options)
+	if err != nil {
+		return nil, err
+	}
+
+	d := connector.(*dcp)
+	d.metadata = b.metadata
+	d.membership = b.membership
+	d.configPath = b.configPath
+	d.errorHandler = b.errorHandler
+	d.metricCollectors = append(d.metricCollectors, b.metricCollectors...)
+
+	if b.eventHandler != nil {
+		d.eventHandler = b.eventHandler
+	}
+
+	return d, nil
+}
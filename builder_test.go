@@ -0,0 +1,70 @@
+package dcp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Trendyol/go-dcp/api"
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/membership"
+	"github.com/Trendyol/go-dcp/metadata"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+func TestNewReturnsAllErrors(t *testing.T) {
+	_, err := New(WithMetadata(nil), WithEventHandler(nil))
+
+	for _, expected := range []error{ErrConfigNotSet, ErrListenerNotSet} {
+		if !errors.Is(err, expected) {
+			t.Errorf("Unexpected result. Expected: %v, Got: %v", expected, err)
+		}
+	}
+
+	if len(err.(interface{ Unwrap() []error }).Unwrap()) != 4 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 4, err)
+	}
+}
+
+func TestNewValidatesCombinations(t *testing.T) {
+	c := getConfig()
+	c.Sources = []config.Source{{BucketName: "users"}}
+	c.LeaderElection = config.LeaderElection{
+		Enabled: true,
+		Config: map[string]string{
+			config.KubernetesLeaderElectorLeaseLockNameConfig:      "lease",
+			config.KubernetesLeaderElectorLeaseLockNamespaceConfig: "default",
+		},
+	}
+
+	_, err := New(
+		WithConfig(c),
+		WithListener(func(_ *models.ListenerContext) {}),
+		WithMetadata(metadata.NewReadMetadata(nil)),
+		WithMembership(membership.NewStaticMembership(c)),
+	)
+
+	if len(err.(interface{ Unwrap() []error }).Unwrap()) != 2 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 2, err)
+	}
+}
+
+func TestNewRejectsMTLSWithSharedAPI(t *testing.T) {
+	c := getConfig()
+	c.API.Auth.Type = config.APIAuthTypeMTLS
+
+	shared := api.NewSharedAPI(8080, "/metrics", prometheus.NewRegistry(), &logger.Loggers{Logrus: logrus.New()})
+
+	_, err := New(
+		WithConfig(c),
+		WithListener(func(_ *models.ListenerContext) {}),
+		WithSharedAPI(shared, "orders"),
+	)
+
+	if !errors.Is(err, api.ErrSharedAPIMTLS) {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", api.ErrSharedAPIMTLS, err)
+	}
+}
@@ -0,0 +1,74 @@
+// Command go-dcp checks go-dcp configuration files without connecting to Couchbase.
+//
+//	go-dcp validate config.yml
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Trendyol/go-dcp/config"
+)
+
+const usage = `Usage:
+  go-dcp validate <config.yml>    checks fields, defaults and combinations of a configuration file
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) != 2 || args[0] != "validate" {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	path := args[1]
+
+	if err := validate(path); err != nil {
+		fmt.Fprintf(stderr, "%s is not valid:\n", path)
+
+		var validationError *config.ValidationError
+		if errors.As(err, &validationError) {
+			for _, fieldError := range validationError.Errors {
+				fmt.Fprintf(stderr, "  %s\n", fieldError)
+			}
+		} else {
+			fmt.Fprintf(stderr, "  %s\n", err)
+		}
+
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "%s is valid\n", path)
+
+	return 0
+}
+
+func validate(path string) error {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// unknown fields are reported, they are mostly misspelled ones
+	decoder := yaml.NewDecoder(bytes.NewReader(file))
+	decoder.KnownFields(true)
+
+	var c config.Dcp
+	if err := decoder.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	if err := c.ApplyDefaults(); err != nil {
+		return err
+	}
+
+	return c.Validate()
+}
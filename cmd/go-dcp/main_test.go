@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yml")
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestValidate(t *testing.T) {
+	path := writeConfig(t, `
+hosts:
+  - localhost:8091
+username: user
+password: password
+bucketName: dcp-test
+dcp:
+  group:
+    name: groupName
+`)
+
+	var stdout, stderr bytes.Buffer
+
+	if code := run([]string{"validate", path}, &stdout, &stderr); code != 0 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v, %s", 0, code, stderr.String())
+	}
+}
+
+func TestValidateReportsFields(t *testing.T) {
+	path := writeConfig(t, `
+hosts:
+  - localhost:8091
+secureConnection: true
+dcp:
+  group:
+    membership:
+      type: kubernetesHa
+`)
+
+	var stdout, stderr bytes.Buffer
+
+	if code := run([]string{"validate", path}, &stdout, &stderr); code != 1 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 1, code)
+	}
+
+	for _, field := range []string{"username", "password", "bucketName", "dcp.group.name", "rootCAPath", "leaderElection.enabled"} {
+		if !strings.Contains(stderr.String(), "invalid config "+field+":") {
+			t.Errorf("Unexpected result. Expected: %v, Got: %v", field, stderr.String())
+		}
+	}
+}
+
+func TestValidateReportsUnknownFields(t *testing.T) {
+	path := writeConfig(t, `bucketNam: dcp-test`)
+
+	var stdout, stderr bytes.Buffer
+
+	if code := run([]string{"validate", path}, &stdout, &stderr); code != 1 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 1, code)
+	}
+
+	if !strings.Contains(stderr.String(), "bucketNam") {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "bucketNam", stderr.String())
+	}
+}
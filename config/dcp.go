@@ -3,7 +3,9 @@ package config
 import (
 	"errors"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Trendyol/go-dcp/helpers"
@@ -34,6 +36,9 @@ const (
 	KubernetesLeaderElectorLeaseDurationConfig      = "leaseDuration"
 	KubernetesLeaderElectorRenewDeadlineConfig      = "renewDeadline"
 	KubernetesLeaderElectorRetryPeriodConfig        = "retryPeriod"
+
+	defaultConnectionBufferSize = 20 * 1024 * 1024
+	defaultDcpBufferSize        = 16 * 1024 * 1024
 )
 
 type DCPGroupMembership struct {
@@ -50,7 +55,20 @@ type DCPGroup struct {
 }
 
 type DCPListener struct {
-	BufferSize uint `yaml:"bufferSize"`
+	Watchdog     Watchdog      `yaml:"watchdog"`
+	BufferSize   uint          `yaml:"bufferSize"`
+	DrainTimeout time.Duration `yaml:"drainTimeout"`
+}
+
+// Watchdog detects a listener which has not acked any event for StallTimeout while events are waiting for it
+// or the lag grows.
+type Watchdog struct {
+	// Action is taken on a stall, none only records it, failHealth fails /health/live and /health/ready,
+	// restart closes and reopens the streams.
+	Action       string        `yaml:"action"`
+	StallTimeout time.Duration `yaml:"stallTimeout"`
+	Interval     time.Duration `yaml:"interval"`
+	Enabled      bool          `yaml:"enabled"`
 }
 
 type ExternalDcpConfig struct {
@@ -67,12 +85,75 @@ type ExternalDcp struct {
 }
 
 type API struct {
-	Disabled bool `yaml:"disabled"`
-	Port     int  `yaml:"port"`
+	Auth      APIAuth   `yaml:"auth"`
+	TLS       APITLS    `yaml:"tls"`
+	Tail      Tail      `yaml:"tail"`
+	Dashboard Dashboard `yaml:"dashboard"`
+	Disabled  bool      `yaml:"disabled"`
+	Port      int       `yaml:"port"`
+}
+
+// APIAuth authenticates the callers of the api. Readers can call GET endpoints, admins every endpoint.
+// Health endpoints and /status are served without authentication for probes.
+type APIAuth struct {
+	// Type is none, basic, bearer or mtls. Every caller is an admin with none.
+	Type   string     `yaml:"type"`
+	Users  []APIUser  `yaml:"users"`
+	Tokens []APIToken `yaml:"tokens"`
+	// AdminCommonNames are the common names of the client certificates which are admins with mtls,
+	// callers with another verified client certificate are readers.
+	AdminCommonNames []string `yaml:"adminCommonNames"`
+}
+
+type APIUser struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password" secret:"true"`
+	Role     string `yaml:"role"`
+}
+
+type APIToken struct {
+	Token string `yaml:"token" secret:"true"`
+	Role  string `yaml:"role"`
+}
+
+// APITLS serves the api over TLS, RootCAPath verifies client certificates for mtls auth.
+type APITLS struct {
+	CertPath   string `yaml:"certPath"`
+	KeyPath    string `yaml:"keyPath"`
+	RootCAPath string `yaml:"rootCAPath"`
+	Enabled    bool   `yaml:"enabled"`
+}
+
+// Dashboard serves a web page of the group on GET /dashboard.
+type Dashboard struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+// Tail configures GET /tail which streams the events handed to the listener, it is served when Enabled or Debug is set.
+type Tail struct {
+	// MaxValueSize is how many bytes of a value are sent at most, a request can ask for less. -1 sends whole values.
+	MaxValueSize int `yaml:"maxValueSize"`
+	// BufferSize is how many events are buffered for a client, events are dropped for a client which is slower.
+	BufferSize int  `yaml:"bufferSize"`
+	Enabled    bool `yaml:"enabled"`
 }
 
 type Metric struct {
 	Path string `yaml:"path"`
+	// CollectionLabelLimit is how many collections are labeled in the collection metrics, 0 labels every collection.
+	CollectionLabelLimit int              `yaml:"collectionLabelLimit"`
+	HighSeqNo            HighSeqNoSampler `yaml:"highSeqNo"`
+}
+
+// HighSeqNoSampler configures how often the high seq nos behind the lag metrics are sampled.
+type HighSeqNoSampler struct {
+	Interval time.Duration `yaml:"interval"`
+	// Staleness is how old a sample can be before the lag metrics are reported as failed.
+	Staleness time.Duration `yaml:"staleness"`
+}
+
+type Tracing struct {
+	Enabled bool `yaml:"enabled"`
 }
 
 type LeaderElection struct {
@@ -83,7 +164,19 @@ type LeaderElection struct {
 }
 
 type RPC struct {
-	Port int `yaml:"port"`
+	Token string `yaml:"token" secret:"true"`
+	TLS   RPCTLS `yaml:"tls"`
+	Port  int    `yaml:"port"`
+}
+
+type RPCTLS struct {
+	CertPath           string `yaml:"certPath"`
+	KeyPath            string `yaml:"keyPath"`
+	RootCAPath         string `yaml:"rootCAPath"`
+	ServerName         string `yaml:"serverName"`
+	Enabled            bool   `yaml:"enabled"`
+	ClientAuth         bool   `yaml:"clientAuth"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify"`
 }
 
 type Checkpoint struct {
@@ -94,9 +187,21 @@ type Checkpoint struct {
 }
 
 type HealthCheck struct {
-	Disabled bool          `yaml:"disabled"`
-	Interval time.Duration `yaml:"interval"`
-	Timeout  time.Duration `yaml:"timeout"`
+	Readiness Readiness     `yaml:"readiness"`
+	Disabled  bool          `yaml:"disabled"`
+	Interval  time.Duration `yaml:"interval"`
+	Timeout   time.Duration `yaml:"timeout"`
+}
+
+// Readiness sets when /health/ready reports the connector as not ready.
+type Readiness struct {
+	// MinOpenStreamRatio is the ratio of assigned vBuckets which must have an open stream.
+	MinOpenStreamRatio float64 `yaml:"minOpenStreamRatio"`
+	// MaxCheckpointSaveFailures is how many checkpoint saves can fail in a row.
+	MaxCheckpointSaveFailures int `yaml:"maxCheckpointSaveFailures"`
+	// ListenerStallTimeout is how long the listener can take to handle an event.
+	ListenerStallTimeout  time.Duration `yaml:"listenerStallTimeout"`
+	ReadyWhileRebalancing bool          `yaml:"readyWhileRebalancing"`
 }
 
 type RollbackMitigation struct {
@@ -111,6 +216,15 @@ type Metadata struct {
 	ReadOnly bool              `yaml:"readOnly"`
 }
 
+// Source is a bucket, scope and collections streamed next to the top level ones.
+// Name keeps its checkpoints apart and defaults to the bucket name.
+type Source struct {
+	Name            string   `yaml:"name"`
+	BucketName      string   `yaml:"bucketName"`
+	ScopeName       string   `yaml:"scopeName"`
+	CollectionNames []string `yaml:"collectionNames"`
+}
+
 type Logging struct {
 	Level string `yaml:"level"`
 }
@@ -119,13 +233,14 @@ type Dcp struct {
 	ConnectionBufferSize any                `yaml:"connectionBufferSize"`
 	BucketName           string             `yaml:"bucketName"`
 	ScopeName            string             `yaml:"scopeName"`
-	Password             string             `yaml:"password"`
+	Password             string             `yaml:"password" secret:"true"`
 	RootCAPath           string             `yaml:"rootCAPath"`
 	Username             string             `yaml:"username"`
 	Logging              Logging            `yaml:"logging"`
 	Metadata             Metadata           `yaml:"metadata"`
 	CollectionNames      []string           `yaml:"collectionNames"`
 	Hosts                []string           `yaml:"hosts"`
+	Sources              []Source           `yaml:"sources"`
 	Metric               Metric             `yaml:"metric"`
 	Checkpoint           Checkpoint         `yaml:"checkpoint"`
 	LeaderElection       LeaderElection     `yaml:"leaderElection"`
@@ -133,9 +248,12 @@ type Dcp struct {
 	HealthCheck          HealthCheck        `yaml:"healthCheck"`
 	RollbackMitigation   RollbackMitigation `yaml:"rollbackMitigation"`
 	API                  API                `yaml:"api"`
+	Reload               Reload             `yaml:"reload"`
+	Tracing              Tracing            `yaml:"tracing"`
 	ConnectionTimeout    time.Duration      `yaml:"connectionTimeout"`
 	SecureConnection     bool               `yaml:"secureConnection"`
 	Debug                bool               `yaml:"debug"`
+	source               *Source
 }
 
 func (c *Dcp) IsCouchbaseMetadata() bool {
@@ -146,24 +264,25 @@ func (c *Dcp) IsFileMetadata() bool {
 	return c.Metadata.Type == MetadataTypeFile
 }
 
-func (c *Dcp) GetFileMetadata() string {
+func (c *Dcp) GetFileMetadata() (string, error) {
 	var fileName string
 
 	if _, ok := c.Metadata.Config[FileMetadataFileNameConfig]; ok {
 		fileName = c.Metadata.Config[FileMetadataFileNameConfig]
 	} else {
-		err := errors.New("file metadata file name is not set")
-		logger.Log.Error("failed to get metadata file name: %v", err)
-		panic(err)
+		return "", newFieldError("metadata.config."+FileMetadataFileNameConfig, errors.New("file metadata file name is not set"))
 	}
 
 	if fileName == "" {
-		err := errors.New("file metadata file name is empty")
-		logger.Log.Error("failed to get metadata file name: %v", err)
-		panic(err)
+		return "", newFieldError("metadata.config."+FileMetadataFileNameConfig, errors.New("file metadata file name is empty"))
 	}
 
-	return fileName
+	if c.source != nil {
+		extension := filepath.Ext(fileName)
+		fileName = strings.TrimSuffix(fileName, extension) + "_" + c.source.Name + extension
+	}
+
+	return fileName, nil
 }
 
 type CouchbaseMembership struct {
@@ -174,7 +293,7 @@ type CouchbaseMembership struct {
 	Timeout                    time.Duration `yaml:"timeout"`
 }
 
-func (c *Dcp) GetCouchbaseMembership() *CouchbaseMembership {
+func (c *Dcp) GetCouchbaseMembership() (*CouchbaseMembership, error) {
 	couchbaseMembership := CouchbaseMembership{
 		ExpirySeconds:              10,
 		HeartbeatInterval:          5 * time.Second,
@@ -186,8 +305,7 @@ func (c *Dcp) GetCouchbaseMembership() *CouchbaseMembership {
 	if expirySeconds, ok := c.Dcp.Group.Membership.Config[CouchbaseMembershipExpirySecondsConfig]; ok {
 		parsedExpirySeconds, err := strconv.ParseUint(expirySeconds, 10, 32)
 		if err != nil {
-			logger.Log.Error("failed to parse membership expiry seconds: %v", err)
-			panic(err)
+			return nil, newFieldError("dcp.group.membership.config."+CouchbaseMembershipExpirySecondsConfig, err)
 		}
 
 		couchbaseMembership.ExpirySeconds = uint32(parsedExpirySeconds)
@@ -196,8 +314,7 @@ func (c *Dcp) GetCouchbaseMembership() *CouchbaseMembership {
 	if heartbeatInterval, ok := c.Dcp.Group.Membership.Config[CouchbaseMembershipHeartbeatIntervalConfig]; ok {
 		parsedHeartbeatInterval, err := time.ParseDuration(heartbeatInterval)
 		if err != nil {
-			logger.Log.Error("failed to parse membership heartbeat interval: %v", err)
-			panic(err)
+			return nil, newFieldError("dcp.group.membership.config."+CouchbaseMembershipHeartbeatIntervalConfig, err)
 		}
 
 		couchbaseMembership.HeartbeatInterval = parsedHeartbeatInterval
@@ -206,8 +323,7 @@ func (c *Dcp) GetCouchbaseMembership() *CouchbaseMembership {
 	if heartbeatToleranceDuration, ok := c.Dcp.Group.Membership.Config[CouchbaseMembershipHeartbeatToleranceConfig]; ok {
 		parsedHeartbeatToleranceDuration, err := time.ParseDuration(heartbeatToleranceDuration)
 		if err != nil {
-			logger.Log.Error("failed to parse membership heartbeat tolerance duration: %v", err)
-			panic(err)
+			return nil, newFieldError("dcp.group.membership.config."+CouchbaseMembershipHeartbeatToleranceConfig, err)
 		}
 
 		couchbaseMembership.HeartbeatToleranceDuration = parsedHeartbeatToleranceDuration
@@ -216,8 +332,7 @@ func (c *Dcp) GetCouchbaseMembership() *CouchbaseMembership {
 	if monitorInterval, ok := c.Dcp.Group.Membership.Config[CouchbaseMembershipMonitorIntervalConfig]; ok {
 		parsedMonitorInterval, err := time.ParseDuration(monitorInterval)
 		if err != nil {
-			logger.Log.Error("failed to parse membership monitor interval: %v", err)
-			panic(err)
+			return nil, newFieldError("dcp.group.membership.config."+CouchbaseMembershipMonitorIntervalConfig, err)
 		}
 
 		couchbaseMembership.MonitorInterval = parsedMonitorInterval
@@ -226,14 +341,13 @@ func (c *Dcp) GetCouchbaseMembership() *CouchbaseMembership {
 	if timeout, ok := c.Dcp.Group.Membership.Config[CouchbaseMembershipTimeoutConfig]; ok {
 		parsedTimeout, err := time.ParseDuration(timeout)
 		if err != nil {
-			logger.Log.Error("failed to parse membership timeout: %v", err)
-			panic(err)
+			return nil, newFieldError("dcp.group.membership.config."+CouchbaseMembershipTimeoutConfig, err)
 		}
 
 		couchbaseMembership.Timeout = parsedTimeout
 	}
 
-	return &couchbaseMembership
+	return &couchbaseMembership, nil
 }
 
 type KubernetesLeaderElector struct {
@@ -244,7 +358,7 @@ type KubernetesLeaderElector struct {
 	RetryPeriod        time.Duration `yaml:"retryPeriod"`
 }
 
-func (c *Dcp) GetKubernetesLeaderElector() *KubernetesLeaderElector {
+func (c *Dcp) GetKubernetesLeaderElector() (*KubernetesLeaderElector, error) {
 	kubernetesLeaderElector := KubernetesLeaderElector{
 		LeaseDuration: 8 * time.Second,
 		RenewDeadline: 5 * time.Second,
@@ -254,24 +368,21 @@ func (c *Dcp) GetKubernetesLeaderElector() *KubernetesLeaderElector {
 	if leaseLockName, ok := c.LeaderElection.Config[KubernetesLeaderElectorLeaseLockNameConfig]; ok {
 		kubernetesLeaderElector.LeaseLockName = leaseLockName
 	} else {
-		err := errors.New("leaseLockName is not defined")
-		logger.Log.Error("error while creating leader elector: %v", err)
-		panic(err)
+		return nil, newFieldError("leaderElection.config."+KubernetesLeaderElectorLeaseLockNameConfig, errors.New("leaseLockName is not defined"))
 	}
 
 	if leaseLockNamespace, ok := c.LeaderElection.Config[KubernetesLeaderElectorLeaseLockNamespaceConfig]; ok {
 		kubernetesLeaderElector.LeaseLockNamespace = leaseLockNamespace
 	} else {
-		err := errors.New("leaseLockNamespace is not defined")
-		logger.Log.Error("error while creating leader elector: %v", err)
-		panic(err)
+		return nil, newFieldError(
+			"leaderElection.config."+KubernetesLeaderElectorLeaseLockNamespaceConfig, errors.New("leaseLockNamespace is not defined"),
+		)
 	}
 
 	if leaseDuration, ok := c.LeaderElection.Config[KubernetesLeaderElectorLeaseDurationConfig]; ok {
 		parsedLeaseDuration, err := time.ParseDuration(leaseDuration)
 		if err != nil {
-			logger.Log.Error("failed to parse leader election lease duration: %v", err)
-			panic(err)
+			return nil, newFieldError("leaderElection.config."+KubernetesLeaderElectorLeaseDurationConfig, err)
 		}
 
 		kubernetesLeaderElector.LeaseDuration = parsedLeaseDuration
@@ -280,8 +391,7 @@ func (c *Dcp) GetKubernetesLeaderElector() *KubernetesLeaderElector {
 	if renewDeadline, ok := c.LeaderElection.Config[KubernetesLeaderElectorRenewDeadlineConfig]; ok {
 		parsedRenewDeadline, err := time.ParseDuration(renewDeadline)
 		if err != nil {
-			logger.Log.Error("failed to parse leader election renew deadline: %v", err)
-			panic(err)
+			return nil, newFieldError("leaderElection.config."+KubernetesLeaderElectorRenewDeadlineConfig, err)
 		}
 
 		kubernetesLeaderElector.RenewDeadline = parsedRenewDeadline
@@ -290,14 +400,13 @@ func (c *Dcp) GetKubernetesLeaderElector() *KubernetesLeaderElector {
 	if retryPeriod, ok := c.LeaderElection.Config[KubernetesLeaderElectorRetryPeriodConfig]; ok {
 		parsedRetryPeriod, err := time.ParseDuration(retryPeriod)
 		if err != nil {
-			logger.Log.Error("failed to parse leader election retry period: %v", err)
-			panic(err)
+			return nil, newFieldError("leaderElection.config."+KubernetesLeaderElectorRetryPeriodConfig, err)
 		}
 
 		kubernetesLeaderElector.RetryPeriod = parsedRetryPeriod
 	}
 
-	return &kubernetesLeaderElector
+	return &kubernetesLeaderElector, nil
 }
 
 type CouchbaseMetadata struct {
@@ -308,7 +417,7 @@ type CouchbaseMetadata struct {
 	ConnectionTimeout    time.Duration `yaml:"connectionTimeout"`
 }
 
-func (c *Dcp) GetCouchbaseMetadata() *CouchbaseMetadata {
+func (c *Dcp) GetCouchbaseMetadata() (*CouchbaseMetadata, error) {
 	couchbaseMetadata := CouchbaseMetadata{
 		Bucket:               c.BucketName,
 		Scope:                DefaultScopeName,
@@ -330,27 +439,40 @@ func (c *Dcp) GetCouchbaseMetadata() *CouchbaseMetadata {
 	}
 
 	if connectionBufferSize, ok := c.Metadata.Config[CouchbaseMetadataConnectionBufferSizeConfig]; ok {
-		couchbaseMetadata.ConnectionBufferSize = uint(helpers.ResolveUnionIntOrStringValue(connectionBufferSize))
+		parsedConnectionBufferSize, err := helpers.ParseUnionIntOrStringValue(connectionBufferSize)
+		if err != nil {
+			return nil, newFieldError("metadata.config."+CouchbaseMetadataConnectionBufferSizeConfig, err)
+		}
+
+		couchbaseMetadata.ConnectionBufferSize = uint(parsedConnectionBufferSize)
 	}
 
 	if connectionTimeout, ok := c.Metadata.Config[CouchbaseMetadataConnectionTimeoutConfig]; ok {
 		parsedConnectionTimeout, err := time.ParseDuration(connectionTimeout)
 		if err != nil {
-			logger.Log.Error("failed to parse metadata connection timeout: %v", err)
-			panic(err)
+			return nil, newFieldError("metadata.config."+CouchbaseMetadataConnectionTimeoutConfig, err)
 		}
 
 		couchbaseMetadata.ConnectionTimeout = parsedConnectionTimeout
 	}
 
-	return &couchbaseMetadata
+	return &couchbaseMetadata, nil
 }
 
-func (c *Dcp) ApplyDefaults() {
+func (c *Dcp) ApplyDefaults() error {
+	if err := c.applyEnvironment(os.Environ()); err != nil {
+		return err
+	}
+
+	if err := c.resolveReferences(); err != nil {
+		return err
+	}
+
 	c.applyDefaultRollbackMitigation()
 	c.applyDefaultCheckpoint()
 	c.applyDefaultHealthCheck()
 	c.applyDefaultGroupMembership()
+
 	c.applyDefaultConnectionTimeout()
 	c.applyDefaultCollections()
 	c.applyDefaultScopeName()
@@ -360,7 +482,71 @@ func (c *Dcp) ApplyDefaults() {
 	c.applyDefaultLeaderElection()
 	c.applyDefaultDcp()
 	c.applyDefaultMetadata()
-	c.applyLogging()
+	c.applyDefaultReload()
+
+	c.applyDefaultSources()
+
+	return c.applyLogging()
+}
+
+func (c *Dcp) applyDefaultSources() {
+	for i := range c.Sources {
+		source := &c.Sources[i]
+
+		if source.Name == "" {
+			source.Name = strings.ReplaceAll(source.BucketName, ".", "_")
+		}
+
+		if source.ScopeName == "" {
+			source.ScopeName = DefaultScopeName
+		}
+
+		if len(source.CollectionNames) == 0 {
+			source.CollectionNames = []string{DefaultCollectionName}
+		}
+	}
+}
+
+// NewSourceConfig returns a copy of the config which streams source, checkpoints of source are kept
+// in the metadata bucket of c under the name of source.
+func (c *Dcp) NewSourceConfig(source Source) *Dcp {
+	sourceConfig := *c
+	sourceConfig.BucketName = source.BucketName
+	sourceConfig.ScopeName = source.ScopeName
+	sourceConfig.CollectionNames = source.CollectionNames
+	sourceConfig.Sources = nil
+	sourceConfig.source = &source
+
+	metadataConfig := make(map[string]string, len(c.Metadata.Config)+1)
+	for key, value := range c.Metadata.Config {
+		metadataConfig[key] = value
+	}
+
+	if _, ok := metadataConfig[CouchbaseMetadataBucketConfig]; !ok && c.IsCouchbaseMetadata() {
+		metadataConfig[CouchbaseMetadataBucketConfig] = c.BucketName
+	}
+
+	sourceConfig.Metadata.Config = metadataConfig
+
+	return &sourceConfig
+}
+
+// GetSourceName returns the name of the source streamed with the config, the bucket name for the top level one.
+func (c *Dcp) GetSourceName() string {
+	if c.source != nil {
+		return c.source.Name
+	}
+
+	return c.BucketName
+}
+
+// GetCheckpointName returns the name checkpoints are stored under, the group name for the top level source.
+func (c *Dcp) GetCheckpointName() string {
+	if c.source != nil {
+		return c.Dcp.Group.Name + ":" + c.source.Name
+	}
+
+	return c.Dcp.Group.Name
 }
 
 func (c *Dcp) applyDefaultRollbackMitigation() {
@@ -399,6 +585,18 @@ func (c *Dcp) applyDefaultHealthCheck() {
 	if c.HealthCheck.Timeout == 0 {
 		c.HealthCheck.Timeout = 5 * time.Second
 	}
+
+	if c.HealthCheck.Readiness.MinOpenStreamRatio == 0 {
+		c.HealthCheck.Readiness.MinOpenStreamRatio = 1
+	}
+
+	if c.HealthCheck.Readiness.MaxCheckpointSaveFailures == 0 {
+		c.HealthCheck.Readiness.MaxCheckpointSaveFailures = 3
+	}
+
+	if c.HealthCheck.Readiness.ListenerStallTimeout == 0 {
+		c.HealthCheck.Readiness.ListenerStallTimeout = time.Minute
+	}
 }
 
 func (c *Dcp) applyDefaultGroupMembership() {
@@ -417,22 +615,6 @@ func (c *Dcp) applyDefaultGroupMembership() {
 	if c.Dcp.Group.Membership.Type == "" {
 		c.Dcp.Group.Membership.Type = MembershipTypeCouchbase
 	}
-
-	if totalMembersFromEnvVariable := os.Getenv("GO_DCP__DCP_GROUP_MEMBERSHIP_TOTALMEMBERS"); totalMembersFromEnvVariable != "" {
-		t, err := strconv.Atoi(totalMembersFromEnvVariable)
-		if err != nil {
-			panic("a non-integer environment variable was entered for 'totalMembers'")
-		}
-		c.Dcp.Group.Membership.TotalMembers = t
-	}
-
-	if memberNumberFromEnvVariable := os.Getenv("GO_DCP__DCP_GROUP_MEMBERSHIP_MEMBERNUMBER"); memberNumberFromEnvVariable != "" {
-		t, err := strconv.Atoi(memberNumberFromEnvVariable)
-		if err != nil {
-			panic("a non-integer environment variable was entered for 'memberNumber'")
-		}
-		c.Dcp.Group.Membership.MemberNumber = t
-	}
 }
 
 func (c *Dcp) applyDefaultConnectionTimeout() {
@@ -459,7 +641,7 @@ func (c *Dcp) applyDefaultScopeName() {
 
 func (c *Dcp) applyDefaultConnectionBufferSize() {
 	if c.ConnectionBufferSize == nil {
-		c.ConnectionBufferSize = helpers.ResolveUnionIntOrStringValue("20mb")
+		c.ConnectionBufferSize = defaultConnectionBufferSize
 	}
 }
 
@@ -467,12 +649,32 @@ func (c *Dcp) applyDefaultMetrics() {
 	if c.Metric.Path == "" {
 		c.Metric.Path = "/metrics"
 	}
+
+	if c.Metric.HighSeqNo.Interval == 0 {
+		c.Metric.HighSeqNo.Interval = 10 * time.Second
+	}
+
+	if c.Metric.HighSeqNo.Staleness == 0 {
+		c.Metric.HighSeqNo.Staleness = 3 * c.Metric.HighSeqNo.Interval
+	}
 }
 
 func (c *Dcp) applyDefaultAPI() {
 	if c.API.Port == 0 {
 		c.API.Port = 8080
 	}
+
+	if c.API.Auth.Type == "" {
+		c.API.Auth.Type = APIAuthTypeNone
+	}
+
+	if c.API.Tail.MaxValueSize == 0 {
+		c.API.Tail.MaxValueSize = 1024
+	}
+
+	if c.API.Tail.BufferSize == 0 {
+		c.API.Tail.BufferSize = 1000
+	}
 }
 
 func (c *Dcp) applyDefaultLeaderElection() {
@@ -487,16 +689,32 @@ func (c *Dcp) applyDefaultLeaderElection() {
 
 func (c *Dcp) applyDefaultDcp() {
 	if c.Dcp.BufferSize == nil {
-		c.Dcp.BufferSize = helpers.ResolveUnionIntOrStringValue("16mb")
+		c.Dcp.BufferSize = defaultDcpBufferSize
 	}
 
 	if c.Dcp.ConnectionBufferSize == nil {
-		c.Dcp.ConnectionBufferSize = helpers.ResolveUnionIntOrStringValue("20mb")
+		c.Dcp.ConnectionBufferSize = defaultConnectionBufferSize
 	}
 
 	if c.Dcp.Listener.BufferSize == 0 {
 		c.Dcp.Listener.BufferSize = 1000
 	}
+
+	if c.Dcp.Listener.Watchdog.StallTimeout == 0 {
+		c.Dcp.Listener.Watchdog.StallTimeout = time.Minute
+	}
+
+	if c.Dcp.Listener.Watchdog.Interval == 0 {
+		c.Dcp.Listener.Watchdog.Interval = 10 * time.Second
+	}
+
+	if c.Dcp.Listener.Watchdog.Action == "" {
+		c.Dcp.Listener.Watchdog.Action = WatchdogActionNone
+	}
+
+	if c.Dcp.Listener.DrainTimeout == 0 {
+		c.Dcp.Listener.DrainTimeout = 10 * time.Second
+	}
 }
 
 func (c *Dcp) applyDefaultMetadata() {
@@ -505,15 +723,18 @@ func (c *Dcp) applyDefaultMetadata() {
 	}
 }
 
-func (c *Dcp) applyLogging() {
+func (c *Dcp) applyLogging() error {
+	if c.Logging.Level == "" {
+		c.Logging.Level = logger.INFO
+	}
+
 	if logger.Log != nil {
-		return
+		return nil
 	}
 
-	loggingLevel := c.Logging.Level
-	if loggingLevel == "" {
-		c.Logging.Level = logger.INFO
+	if err := logger.InitDefaultLogger(c.Logging.Level); err != nil {
+		return newFieldError("logging.level", err)
 	}
 
-	logger.InitDefaultLogger(c.Logging.Level)
+	return nil
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -47,7 +48,10 @@ func TestGetCouchbaseMetadata(t *testing.T) {
 		BucketName: "mybucket2",
 	}
 
-	couchbaseMetadata := dcp.GetCouchbaseMetadata()
+	couchbaseMetadata, err := dcp.GetCouchbaseMetadata()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
 	expectedBucket := "mybucket"
 	expectedScope := "myscope"
@@ -92,7 +96,10 @@ func TestGetCouchbaseMembership(t *testing.T) {
 		},
 	}
 
-	couchbaseMembership := dcp.GetCouchbaseMembership()
+	couchbaseMembership, err := dcp.GetCouchbaseMembership()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
 	expectedExpiryDuration := uint32(5)
 	expectedHeartbeatInterval := 5 * time.Second
@@ -130,13 +137,68 @@ func TestDcp_GetFileMetadata(t *testing.T) {
 		},
 	}
 
-	metadata := dcp.GetFileMetadata()
+	metadata, err := dcp.GetFileMetadata()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
 
 	if metadata != "testfile.json" {
 		t.Errorf("Metadata is not set to expected value")
 	}
 }
 
+func TestDcp_GetFileMetadataWithoutFileName(t *testing.T) {
+	dcp := &Dcp{}
+
+	_, err := dcp.GetFileMetadata()
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("Unexpected error type. Expected: *FieldError, Got: %T", err)
+	}
+
+	if fieldErr.Field != "metadata.config.fileName" {
+		t.Errorf("Unexpected field. Expected: %s, Got: %s", "metadata.config.fileName", fieldErr.Field)
+	}
+}
+
+func TestGetCouchbaseMembershipWithInvalidDuration(t *testing.T) {
+	dcp := &Dcp{
+		Dcp: ExternalDcp{
+			Group: DCPGroup{
+				Membership: DCPGroupMembership{
+					Config: map[string]string{
+						CouchbaseMembershipHeartbeatIntervalConfig: "five seconds",
+					},
+				},
+			},
+		},
+	}
+
+	_, err := dcp.GetCouchbaseMembership()
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("Unexpected error type. Expected: *FieldError, Got: %T", err)
+	}
+
+	expected := "dcp.group.membership.config.heartbeatInterval"
+	if fieldErr.Field != expected {
+		t.Errorf("Unexpected field. Expected: %s, Got: %s", expected, fieldErr.Field)
+	}
+}
+
+func TestGetKubernetesLeaderElectorWithoutLeaseLockName(t *testing.T) {
+	dcp := &Dcp{}
+
+	_, err := dcp.GetKubernetesLeaderElector()
+
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatalf("Unexpected error type. Expected: *FieldError, Got: %T", err)
+	}
+}
+
 func TestApplyDefaultRollbackMitigation(t *testing.T) {
 	c := &Dcp{
 		RollbackMitigation: RollbackMitigation{},
@@ -305,6 +367,10 @@ func TestDcpApplyDefaultDcp(t *testing.T) {
 	if c.Dcp.Listener.BufferSize != 1000 {
 		t.Errorf("Dcp.Listener.BufferSize is not set to expected value")
 	}
+
+	if c.Dcp.Listener.DrainTimeout != 10*time.Second {
+		t.Errorf("Dcp.Listener.DrainTimeout is not set to expected value")
+	}
 }
 
 func TestApplyDefaultMetadata(t *testing.T) {
@@ -322,3 +388,54 @@ func TestApplyDefaultMetadata(t *testing.T) {
 		t.Errorf("Metadata.Type is not set to expected value")
 	}
 }
+
+func TestApplyDefaultSources(t *testing.T) {
+	c := &Dcp{
+		BucketName: "orders",
+		Sources:    []Source{{BucketName: "users.v2"}},
+	}
+
+	c.applyDefaultSources()
+
+	if c.Sources[0].Name != "users_v2" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "users_v2", c.Sources[0].Name)
+	}
+
+	if c.Sources[0].ScopeName != DefaultScopeName {
+		t.Errorf("Sources[0].ScopeName is not set to default")
+	}
+
+	if len(c.Sources[0].CollectionNames) != 1 || c.Sources[0].CollectionNames[0] != DefaultCollectionName {
+		t.Errorf("Sources[0].CollectionNames is not set to default")
+	}
+}
+
+func TestNewSourceConfig(t *testing.T) {
+	c := &Dcp{
+		BucketName: "orders",
+		Metadata:   Metadata{Type: MetadataTypeCouchbase, Config: map[string]string{}},
+	}
+	c.Dcp.Group.Name = "group"
+
+	sourceConfig := c.NewSourceConfig(Source{Name: "users", BucketName: "users", ScopeName: "_default"})
+
+	if sourceConfig.BucketName != "users" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "users", sourceConfig.BucketName)
+	}
+
+	if sourceConfig.GetCheckpointName() != "group:users" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "group:users", sourceConfig.GetCheckpointName())
+	}
+
+	if c.GetCheckpointName() != "group" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "group", c.GetCheckpointName())
+	}
+
+	if sourceConfig.Metadata.Config[CouchbaseMetadataBucketConfig] != "orders" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "orders", sourceConfig.Metadata.Config[CouchbaseMetadataBucketConfig])
+	}
+
+	if _, ok := c.Metadata.Config[CouchbaseMetadataBucketConfig]; ok {
+		t.Errorf("Metadata.Config of the top level config is changed")
+	}
+}
@@ -0,0 +1,295 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvironmentPrefix starts the name of every environment variable overriding a config field.
+// The rest of the name is the upper case field path joined by underscores, like GO_DCP__DCP_GROUP_NAME
+// for dcp.group.name, GO_DCP__SOURCES_0_BUCKETNAME for sources[0].bucketName and
+// GO_DCP__METADATA_CONFIG_bucket for the bucket key of metadata.config.
+const EnvironmentPrefix = "GO_DCP__"
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	referenceRe  = regexp.MustCompile(`\$\{(file|env):([^}]*)\}`)
+)
+
+type environment map[string]string
+
+func newEnvironment(environ []string) environment {
+	env := environment{}
+
+	for _, variable := range environ {
+		name, value, ok := strings.Cut(variable, "=")
+		if ok && strings.HasPrefix(name, EnvironmentPrefix) {
+			env[name] = value
+		}
+	}
+
+	return env
+}
+
+// applyEnvironment overrides the fields which have a GO_DCP__ environment variable.
+func (c *Dcp) applyEnvironment(environ []string) error {
+	env := newEnvironment(environ)
+	if len(env) == 0 {
+		return nil
+	}
+
+	v := &validation{}
+	env.apply(reflect.ValueOf(c).Elem(), "", strings.TrimSuffix(EnvironmentPrefix, "_"), v)
+
+	return v.err()
+}
+
+func (env environment) apply(value reflect.Value, field string, name string, v *validation) {
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.Struct:
+		forEachField(value, field, name, func(child reflect.Value, childField string, childName string) {
+			env.apply(child, childField, childName, v)
+		})
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Struct {
+			env.applySlice(value, field, name, v)
+			return
+		}
+
+		if raw, ok := env[name]; ok {
+			items := strings.Split(raw, ",")
+			for i := range items {
+				items[i] = strings.TrimSpace(items[i])
+			}
+
+			value.Set(reflect.ValueOf(items))
+		}
+	case reflect.Map:
+		env.applyMap(value, name)
+	default:
+		if raw, ok := env[name]; ok {
+			if err := setScalar(value, raw); err != nil {
+				v.add(field, fmt.Errorf("environment variable %s: %w", name, err))
+			}
+		}
+	}
+}
+
+// applySlice overrides the items of a struct slice by index, items after the last one are appended.
+func (env environment) applySlice(value reflect.Value, field string, name string, v *validation) {
+	length := value.Len()
+	prefix := name + "_"
+
+	for variable := range env {
+		if !strings.HasPrefix(variable, prefix) {
+			continue
+		}
+
+		index, _, _ := strings.Cut(strings.TrimPrefix(variable, prefix), "_")
+
+		i, err := strconv.Atoi(index)
+		if err != nil || i < 0 {
+			v.add(field, fmt.Errorf("environment variable %s has no valid index", variable))
+			continue
+		}
+
+		if i >= length {
+			length = i + 1
+		}
+	}
+
+	if length > value.Len() {
+		grown := reflect.MakeSlice(value.Type(), length, length)
+		reflect.Copy(grown, value)
+		value.Set(grown)
+	}
+
+	for i := 0; i < value.Len(); i++ {
+		env.apply(value.Index(i), fmt.Sprintf("%s[%d]", field, i), fmt.Sprintf("%s_%d", name, i), v)
+	}
+}
+
+// applyMap sets the keys after the map prefix, existing keys are matched regardless of case.
+func (env environment) applyMap(value reflect.Value, name string) {
+	prefix := name + "_"
+
+	for variable, raw := range env {
+		if !strings.HasPrefix(variable, prefix) {
+			continue
+		}
+
+		key := strings.TrimPrefix(variable, prefix)
+
+		if value.IsNil() {
+			value.Set(reflect.MakeMap(value.Type()))
+		}
+
+		for _, existing := range value.MapKeys() {
+			if strings.EqualFold(existing.String(), key) {
+				key = existing.String()
+				break
+			}
+		}
+
+		value.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(raw))
+	}
+}
+
+// yamlName returns the name of an exported field in yaml, fields which are not in yaml are skipped.
+func yamlName(structField reflect.StructField) (string, bool) {
+	if !structField.IsExported() {
+		return "", false
+	}
+
+	tag, _, _ := strings.Cut(structField.Tag.Get("yaml"), ",")
+	if tag == "" || tag == "-" {
+		return "", false
+	}
+
+	return tag, true
+}
+
+func forEachField(value reflect.Value, field string, name string, fn func(reflect.Value, string, string)) {
+	for i := 0; i < value.NumField(); i++ {
+		tag, ok := yamlName(value.Type().Field(i))
+		if !ok {
+			continue
+		}
+
+		childField := tag
+		if field != "" {
+			childField = field + "." + tag
+		}
+
+		fn(value.Field(i), childField, name+"_"+strings.ToUpper(tag))
+	}
+}
+
+func setScalar(value reflect.Value, raw string) error {
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Type() == durationType {
+			parsed, err := time.ParseDuration(raw)
+			if err != nil {
+				return err
+			}
+
+			value.SetInt(int64(parsed))
+			return nil
+		}
+
+		parsed, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		value.SetUint(parsed)
+	case reflect.Interface:
+		// union fields like connectionBufferSize accept sizes like 20mb
+		value.Set(reflect.ValueOf(raw))
+	default:
+		return fmt.Errorf("%s fields cannot be set", value.Kind())
+	}
+
+	return nil
+}
+
+// resolveReferences replaces ${file:/path} with the content of the file and ${env:VAR} with the environment variable
+// in every string of the config.
+func (c *Dcp) resolveReferences() error {
+	v := &validation{}
+	resolveReferences(reflect.ValueOf(c).Elem(), "", v)
+
+	return v.err()
+}
+
+func resolveReferences(value reflect.Value, field string, v *validation) {
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.Struct:
+		forEachField(value, field, "", func(child reflect.Value, childField string, _ string) {
+			resolveReferences(child, childField, v)
+		})
+	case reflect.Slice:
+		for i := 0; i < value.Len(); i++ {
+			resolveReferences(value.Index(i), fmt.Sprintf("%s[%d]", field, i), v)
+		}
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			item := value.MapIndex(key)
+			if item.Kind() != reflect.String {
+				continue
+			}
+
+			resolved, err := resolveReference(item.String())
+			if err != nil {
+				v.add(field+"."+key.String(), err)
+				continue
+			}
+
+			value.SetMapIndex(key, reflect.ValueOf(resolved))
+		}
+	case reflect.String:
+		resolved, err := resolveReference(value.String())
+		if err != nil {
+			v.add(field, err)
+			return
+		}
+
+		value.SetString(resolved)
+	}
+}
+
+func resolveReference(raw string) (string, error) {
+	if !strings.Contains(raw, "${") {
+		return raw, nil
+	}
+
+	var errs []error
+
+	resolved := referenceRe.ReplaceAllStringFunc(raw, func(reference string) string {
+		match := referenceRe.FindStringSubmatch(reference)
+
+		switch match[1] {
+		case "file":
+			content, err := os.ReadFile(match[2])
+			if err != nil {
+				errs = append(errs, err)
+				return reference
+			}
+
+			return strings.TrimRight(string(content), "\r\n")
+		default:
+			value, ok := os.LookupEnv(match[2])
+			if !ok {
+				errs = append(errs, fmt.Errorf("environment variable %s is not set", match[2]))
+				return reference
+			}
+
+			return value
+		}
+	})
+
+	return resolved, errors.Join(errs...)
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestApplyEnvironment(t *testing.T) {
+	c := &Dcp{
+		Metadata: Metadata{Config: map[string]string{"fileName": "checkpoint.json"}},
+		Sources:  []Source{{BucketName: "users"}},
+	}
+
+	err := c.applyEnvironment([]string{
+		"GO_DCP__BUCKETNAME=orders",
+		"GO_DCP__HOSTS=host1:8091, host2:8091",
+		"GO_DCP__SECURECONNECTION=true",
+		"GO_DCP__CHECKPOINT_INTERVAL=5s",
+		"GO_DCP__DCP_GROUP_MEMBERSHIP_TOTALMEMBERS=3",
+		"GO_DCP__DCP_LISTENER_BUFFERSIZE=10",
+		"GO_DCP__CONNECTIONBUFFERSIZE=10mb",
+		"GO_DCP__METADATA_CONFIG_FILENAME=offsets.json",
+		"GO_DCP__METADATA_CONFIG_bucket=metadata",
+		"GO_DCP__SOURCES_1_BUCKETNAME=archive",
+		"OTHER=ignored",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if c.BucketName != "orders" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "orders", c.BucketName)
+	}
+
+	if len(c.Hosts) != 2 || c.Hosts[1] != "host2:8091" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "[host1:8091 host2:8091]", c.Hosts)
+	}
+
+	if !c.SecureConnection {
+		t.Errorf("SecureConnection is not set from environment")
+	}
+
+	if c.Checkpoint.Interval != 5*time.Second {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 5*time.Second, c.Checkpoint.Interval)
+	}
+
+	if c.Dcp.Group.Membership.TotalMembers != 3 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 3, c.Dcp.Group.Membership.TotalMembers)
+	}
+
+	if c.Dcp.Listener.BufferSize != 10 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 10, c.Dcp.Listener.BufferSize)
+	}
+
+	if c.ConnectionBufferSize != "10mb" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "10mb", c.ConnectionBufferSize)
+	}
+
+	if c.Metadata.Config["fileName"] != "offsets.json" || c.Metadata.Config["bucket"] != "metadata" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "offsets.json and metadata", c.Metadata.Config)
+	}
+
+	if len(c.Sources) != 2 || c.Sources[0].BucketName != "users" || c.Sources[1].BucketName != "archive" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "users and archive", c.Sources)
+	}
+}
+
+func TestApplyEnvironmentReportsEveryField(t *testing.T) {
+	c := &Dcp{}
+
+	err := c.applyEnvironment([]string{
+		"GO_DCP__DCP_GROUP_MEMBERSHIP_MEMBERNUMBER=first",
+		"GO_DCP__CHECKPOINT_TIMEOUT=soon",
+	})
+
+	var validationError *ValidationError
+	if !errors.As(err, &validationError) || len(validationError.Errors) != 2 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 2, err)
+	}
+}
+
+func TestResolveReferences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("TEST_DCP_USERNAME", "user")
+
+	c := &Dcp{
+		Username: "${env:TEST_DCP_USERNAME}",
+		Password: "${file:" + path + "}",
+		Metadata: Metadata{Config: map[string]string{"bucket": "${env:TEST_DCP_USERNAME}-metadata"}},
+	}
+
+	if err := c.resolveReferences(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if c.Username != "user" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "user", c.Username)
+	}
+
+	if c.Password != "secret" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "secret", c.Password)
+	}
+
+	if c.Metadata.Config["bucket"] != "user-metadata" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "user-metadata", c.Metadata.Config["bucket"])
+	}
+}
+
+func TestResolveReferencesWithMissingEnvironment(t *testing.T) {
+	c := &Dcp{Password: "${env:TEST_DCP_NOT_SET}"}
+
+	err := c.resolveReferences()
+
+	var fieldError *FieldError
+	if !errors.As(err, &fieldError) || fieldError.Field != "password" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "password", err)
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	c := &Dcp{Password: "secret"}
+	c.LeaderElection.RPC.Token = "token"
+
+	redactedConfig := c.Redacted()
+
+	if redactedConfig.Password != redacted || redactedConfig.LeaderElection.RPC.Token != redacted {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", redacted, redactedConfig)
+	}
+
+	if c.Password != "secret" || c.LeaderElection.RPC.Token != "token" {
+		t.Errorf("Redacted changed the original config")
+	}
+}
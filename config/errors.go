@@ -0,0 +1,21 @@
+package config
+
+import "fmt"
+
+// FieldError is returned when a configuration field has an invalid or missing value.
+type FieldError struct {
+	Err   error
+	Field string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid config %s: %v", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+func newFieldError(field string, err error) error {
+	return &FieldError{Field: field, Err: err}
+}
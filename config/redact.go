@@ -0,0 +1,49 @@
+package config
+
+import (
+	"reflect"
+)
+
+const redacted = "*****"
+
+// Redacted returns a copy of the config in which every field tagged with secret:"true" is masked, for logging.
+func (c *Dcp) Redacted() Dcp {
+	copyOfConfig := *c
+	redact(reflect.ValueOf(&copyOfConfig).Elem())
+
+	return copyOfConfig
+}
+
+func redact(value reflect.Value) {
+	switch value.Kind() { //nolint:exhaustive
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			structField := value.Type().Field(i)
+			if !structField.IsExported() {
+				continue
+			}
+
+			field := value.Field(i)
+
+			if structField.Tag.Get("secret") == "true" && field.Kind() == reflect.String && field.String() != "" {
+				field.SetString(redacted)
+				continue
+			}
+
+			redact(field)
+		}
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.Struct {
+			return
+		}
+
+		// items are shared with the original config, they are copied before masking
+		items := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		reflect.Copy(items, value)
+		value.Set(items)
+
+		for i := 0; i < items.Len(); i++ {
+			redact(items.Index(i))
+		}
+	}
+}
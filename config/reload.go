@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ReloadableFields can be changed on a running connector without a restart.
+var ReloadableFields = []string{
+	"logging.level",
+	"checkpoint.interval",
+	"healthCheck.interval",
+	"dcp.listener.bufferSize",
+}
+
+var ErrNotReloadable = errors.New("cannot be changed without a restart")
+
+// reloadableLock guards the fields listed in ReloadableFields, they are written by a reload while streams read them.
+var reloadableLock sync.RWMutex
+
+type Reload struct {
+	Watch    bool          `yaml:"watch"`
+	Interval time.Duration `yaml:"interval"`
+}
+
+// Diff returns the paths of the fields which are different in other.
+func (c *Dcp) Diff(other *Dcp) []string {
+	var fields []string
+	diff(reflect.ValueOf(c).Elem(), reflect.ValueOf(other).Elem(), "", &fields)
+
+	return fields
+}
+
+func diff(value reflect.Value, other reflect.Value, field string, fields *[]string) {
+	if value.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(value.Interface(), other.Interface()) {
+			*fields = append(*fields, field)
+		}
+
+		return
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		tag, ok := yamlName(value.Type().Field(i))
+		if !ok {
+			continue
+		}
+
+		childField := tag
+		if field != "" {
+			childField = field + "." + tag
+		}
+
+		diff(value.Field(i), other.Field(i), childField, fields)
+	}
+}
+
+// ReloadFrom copies the reloadable fields of other into c and returns the changed ones.
+// If any other field is changed c is kept as it is, and every such field is reported in a *ValidationError.
+func (c *Dcp) ReloadFrom(other *Dcp) ([]string, error) {
+	reloaded, err := c.ReloadedFields(other)
+	if err != nil {
+		return nil, err
+	}
+
+	c.CopyReloadableFields(other)
+
+	return reloaded, nil
+}
+
+// ReloadedFields returns the reloadable fields which are different in other without changing c.
+// If any other field is changed every such field is reported in a *ValidationError.
+func (c *Dcp) ReloadedFields(other *Dcp) ([]string, error) {
+	v := &validation{}
+
+	var reloaded []string
+
+	for _, field := range c.Diff(other) {
+		if slices.Contains(ReloadableFields, field) {
+			reloaded = append(reloaded, field)
+		} else {
+			v.add(field, ErrNotReloadable)
+		}
+	}
+
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	return reloaded, nil
+}
+
+// CopyReloadableFields copies the fields listed in ReloadableFields from other.
+func (c *Dcp) CopyReloadableFields(other *Dcp) {
+	reloadableLock.Lock()
+	defer reloadableLock.Unlock()
+
+	c.Logging.Level = other.Logging.Level
+	c.Checkpoint.Interval = other.Checkpoint.Interval
+	c.HealthCheck.Interval = other.HealthCheck.Interval
+	c.Dcp.Listener.BufferSize = other.Dcp.Listener.BufferSize
+}
+
+// GetInterval returns checkpoint.interval of a running connector.
+func (c *Checkpoint) GetInterval() time.Duration {
+	reloadableLock.RLock()
+	defer reloadableLock.RUnlock()
+
+	return c.Interval
+}
+
+// GetInterval returns healthCheck.interval of a running connector.
+func (c *HealthCheck) GetInterval() time.Duration {
+	reloadableLock.RLock()
+	defer reloadableLock.RUnlock()
+
+	return c.Interval
+}
+
+// GetBufferSize returns dcp.listener.bufferSize of a running connector.
+func (c *DCPListener) GetBufferSize() uint {
+	reloadableLock.RLock()
+	defer reloadableLock.RUnlock()
+
+	return c.BufferSize
+}
+
+func (c *Dcp) applyDefaultReload() {
+	if c.Reload.Interval == 0 {
+		c.Reload.Interval = 5 * time.Second
+	}
+}
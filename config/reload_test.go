@@ -0,0 +1,52 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReloadFrom(t *testing.T) {
+	c := getValidConfig()
+
+	other := getValidConfig()
+	other.Logging.Level = "debug"
+	other.Checkpoint.Interval = time.Minute
+
+	fields, err := c.ReloadFrom(other)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(fields) != 2 || fields[0] != "logging.level" || fields[1] != "checkpoint.interval" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "[logging.level checkpoint.interval]", fields)
+	}
+
+	if c.Checkpoint.Interval != time.Minute || c.Logging.Level != "debug" {
+		t.Errorf("Reloadable fields are not copied")
+	}
+}
+
+func TestReloadFromRejectsUnsafeChanges(t *testing.T) {
+	c := getValidConfig()
+
+	other := getValidConfig()
+	other.HealthCheck.Interval = time.Minute
+	other.BucketName = "users"
+	other.Hosts = []string{"localhost:18091"}
+
+	_, err := c.ReloadFrom(other)
+
+	fields := getFields(err)
+	if len(fields) != 2 || fields[0] != "bucketName" || fields[1] != "hosts" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "[bucketName hosts]", fields)
+	}
+
+	if !errors.Is(err, ErrNotReloadable) {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", ErrNotReloadable, err)
+	}
+
+	if c.HealthCheck.Interval == time.Minute {
+		t.Errorf("Config is changed by a rejected reload")
+	}
+}
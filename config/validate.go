@@ -0,0 +1,301 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	MembershipTypeStatic                = "static"
+	MembershipTypeKubernetesStatefulSet = "kubernetesStatefulSet"
+	MembershipTypeKubernetesHa          = "kubernetesHa"
+	LeaderElectionTypeKubernetes        = "kubernetes"
+	CheckpointTypeManual                = "manual"
+	CheckpointAutoResetTypeEarliest     = "earliest"
+	CheckpointAutoResetTypeLatest       = "latest"
+	APIAuthTypeNone                     = "none"
+	APIAuthTypeBasic                    = "basic"
+	APIAuthTypeBearer                   = "bearer"
+	APIAuthTypeMTLS                     = "mtls"
+	APIRoleReader                       = "reader"
+	APIRoleAdmin                        = "admin"
+	WatchdogActionNone                  = "none"
+	WatchdogActionFailHealth            = "failHealth"
+	WatchdogActionRestart               = "restart"
+)
+
+// ValidationError lists every invalid field of a config.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+type validation struct {
+	errors []*FieldError
+}
+
+func (v *validation) add(field string, err error) {
+	var fieldError *FieldError
+	if errors.As(err, &fieldError) {
+		v.errors = append(v.errors, fieldError)
+		return
+	}
+
+	v.errors = append(v.errors, &FieldError{Field: field, Err: err})
+}
+
+func (v *validation) err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Errors: v.errors}
+}
+
+func (v *validation) required(field string, empty bool) {
+	if empty {
+		v.add(field, errors.New("is required"))
+	}
+}
+
+func (v *validation) oneOf(field string, value string, values ...string) {
+	for _, allowed := range values {
+		if value == allowed {
+			return
+		}
+	}
+
+	v.add(field, fmt.Errorf("%q is not one of %s", value, strings.Join(values, ", ")))
+}
+
+func (v *validation) port(field string, port int) {
+	if port < 1 || port > 65535 {
+		v.add(field, fmt.Errorf("%d is not a valid port", port))
+	}
+}
+
+// Validate checks the config after ApplyDefaults. It does not connect anywhere,
+// every invalid field is reported in the returned *ValidationError.
+func (c *Dcp) Validate() error {
+	v := &validation{}
+
+	v.required("hosts", len(c.Hosts) == 0)
+	v.required("username", c.Username == "")
+	v.required("password", c.Password == "")
+	v.required("bucketName", c.BucketName == "")
+	v.required("dcp.group.name", c.Dcp.Group.Name == "")
+
+	if c.SecureConnection {
+		v.required("rootCAPath", c.RootCAPath == "")
+	}
+
+	if _, err := logrus.ParseLevel(c.Logging.Level); c.Logging.Level != "" && err != nil {
+		v.add("logging.level", err)
+	}
+
+	c.validateMembership(v)
+	c.validateLeaderElection(v)
+	c.validateCheckpoint(v)
+	c.validateMetadata(v)
+	c.validateSources(v)
+
+	if !c.API.Disabled {
+		v.port("api.port", c.API.Port)
+		c.validateAPIAuth(v)
+	}
+
+	if c.API.Tail.MaxValueSize < -1 {
+		v.add("api.tail.maxValueSize", errors.New("must be -1 or more"))
+	}
+
+	if c.API.Tail.BufferSize < 0 {
+		v.add("api.tail.bufferSize", errors.New("must not be negative"))
+	}
+
+	v.oneOf(
+		"dcp.listener.watchdog.action", c.Dcp.Listener.Watchdog.Action,
+		WatchdogActionNone, WatchdogActionFailHealth, WatchdogActionRestart,
+	)
+
+	if c.Dcp.Listener.Watchdog.StallTimeout < 0 {
+		v.add("dcp.listener.watchdog.stallTimeout", errors.New("must not be negative"))
+	}
+
+	if c.Dcp.Listener.Watchdog.Interval < 0 {
+		v.add("dcp.listener.watchdog.interval", errors.New("must not be negative"))
+	}
+
+	if ratio := c.HealthCheck.Readiness.MinOpenStreamRatio; ratio < 0 || ratio > 1 {
+		v.add("healthCheck.readiness.minOpenStreamRatio", errors.New("must be between 0 and 1"))
+	}
+
+	if c.Metric.CollectionLabelLimit < 0 {
+		v.add("metric.collectionLabelLimit", errors.New("must not be negative"))
+	}
+
+	if c.Metric.HighSeqNo.Interval < 0 {
+		v.add("metric.highSeqNo.interval", errors.New("must not be negative"))
+	}
+
+	if c.Metric.HighSeqNo.Staleness < c.Metric.HighSeqNo.Interval {
+		v.add("metric.highSeqNo.staleness", errors.New("must not be less than metric.highSeqNo.interval"))
+	}
+
+	return v.err()
+}
+
+func (c *Dcp) validateAPIAuth(v *validation) {
+	auth := c.API.Auth
+
+	v.oneOf("api.auth.type", auth.Type, APIAuthTypeNone, APIAuthTypeBasic, APIAuthTypeBearer, APIAuthTypeMTLS)
+
+	switch auth.Type {
+	case APIAuthTypeBasic:
+		v.required("api.auth.users", len(auth.Users) == 0)
+
+		for i, user := range auth.Users {
+			v.required(fmt.Sprintf("api.auth.users[%d].username", i), user.Username == "")
+			v.required(fmt.Sprintf("api.auth.users[%d].password", i), user.Password == "")
+			v.oneOf(fmt.Sprintf("api.auth.users[%d].role", i), user.Role, APIRoleReader, APIRoleAdmin)
+		}
+	case APIAuthTypeBearer:
+		v.required("api.auth.tokens", len(auth.Tokens) == 0)
+
+		for i, token := range auth.Tokens {
+			v.required(fmt.Sprintf("api.auth.tokens[%d].token", i), token.Token == "")
+			v.oneOf(fmt.Sprintf("api.auth.tokens[%d].role", i), token.Role, APIRoleReader, APIRoleAdmin)
+		}
+	case APIAuthTypeMTLS:
+		if !c.API.TLS.Enabled {
+			v.add("api.tls.enabled", errors.New("must be true for mtls auth"))
+		}
+
+		v.required("api.tls.rootCAPath", c.API.TLS.RootCAPath == "")
+	}
+
+	if tls := c.API.TLS; tls.Enabled {
+		v.required("api.tls.certPath", tls.CertPath == "")
+		v.required("api.tls.keyPath", tls.KeyPath == "")
+	}
+}
+
+func (c *Dcp) validateMembership(v *validation) {
+	membership := c.Dcp.Group.Membership
+
+	v.oneOf("dcp.group.membership.type", membership.Type,
+		MembershipTypeCouchbase, MembershipTypeKubernetesHa, MembershipTypeKubernetesStatefulSet, MembershipTypeStatic,
+	)
+
+	switch membership.Type {
+	case MembershipTypeCouchbase:
+		if _, err := c.GetCouchbaseMembership(); err != nil {
+			v.add("dcp.group.membership.config", err)
+		}
+	case MembershipTypeKubernetesHa:
+		if !c.LeaderElection.Enabled {
+			v.add("leaderElection.enabled", errors.New("must be true for kubernetesHa membership"))
+		}
+	case MembershipTypeStatic:
+		if membership.TotalMembers < 1 {
+			v.add("dcp.group.membership.totalMembers", errors.New("must be at least 1"))
+		}
+
+		if membership.MemberNumber < 1 || membership.MemberNumber > membership.TotalMembers {
+			v.add("dcp.group.membership.memberNumber", fmt.Errorf("must be between 1 and %d", membership.TotalMembers))
+		}
+	}
+}
+
+func (c *Dcp) validateLeaderElection(v *validation) {
+	if !c.LeaderElection.Enabled {
+		return
+	}
+
+	v.oneOf("leaderElection.type", c.LeaderElection.Type, LeaderElectionTypeKubernetes)
+
+	if c.LeaderElection.Type == LeaderElectionTypeKubernetes {
+		if _, err := c.GetKubernetesLeaderElector(); err != nil {
+			v.add("leaderElection.config", err)
+		}
+	}
+
+	v.port("leaderElection.rpc.port", c.LeaderElection.RPC.Port)
+
+	if c.LeaderElection.RPC.Token != "" && !c.LeaderElection.RPC.TLS.Enabled {
+		v.add("leaderElection.rpc.tls.enabled", errors.New("must be true when token is set"))
+	}
+
+	if tls := c.LeaderElection.RPC.TLS; tls.Enabled {
+		v.required("leaderElection.rpc.tls.certPath", tls.CertPath == "")
+		v.required("leaderElection.rpc.tls.keyPath", tls.KeyPath == "")
+
+		if tls.ClientAuth {
+			v.required("leaderElection.rpc.tls.rootCAPath", tls.RootCAPath == "")
+		}
+	}
+}
+
+func (c *Dcp) validateCheckpoint(v *validation) {
+	v.oneOf("checkpoint.type", c.Checkpoint.Type, CheckpointTypeAuto, CheckpointTypeManual)
+	v.oneOf("checkpoint.autoReset", c.Checkpoint.AutoReset, CheckpointAutoResetTypeEarliest, CheckpointAutoResetTypeLatest)
+}
+
+// validateMetadata only checks the built-in metadata types, any other type is set with a custom metadata.
+func (c *Dcp) validateMetadata(v *validation) {
+	switch {
+	case c.IsCouchbaseMetadata():
+		if _, err := c.GetCouchbaseMetadata(); err != nil {
+			v.add("metadata.config", err)
+		}
+
+		// the group name is a part of the checkpoint document ids
+		if strings.Contains(c.Dcp.Group.Name, ".") {
+			v.add("dcp.group.name", errors.New("name includes dot"))
+		}
+	case c.IsFileMetadata():
+		if _, err := c.GetFileMetadata(); err != nil {
+			v.add("metadata.config", err)
+		}
+	}
+}
+
+func (c *Dcp) validateSources(v *validation) {
+	// the top level source is named by its bucket
+	names := map[string]struct{}{c.BucketName: {}}
+
+	for i, source := range c.Sources {
+		field := fmt.Sprintf("sources[%d]", i)
+
+		v.required(field+".bucketName", source.BucketName == "")
+
+		if strings.Contains(source.Name, ".") {
+			v.add(field+".name", errors.New("name includes dot"))
+		}
+
+		if _, ok := names[source.Name]; ok && source.Name != "" {
+			v.add(field+".name", fmt.Errorf("name %s is not unique", source.Name))
+		}
+
+		names[source.Name] = struct{}{}
+	}
+}
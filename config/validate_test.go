@@ -0,0 +1,177 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func getValidConfig() *Dcp {
+	c := &Dcp{
+		Hosts:      []string{"localhost:8091"},
+		Username:   "user",
+		Password:   "password",
+		BucketName: "orders",
+	}
+	c.Dcp.Group.Name = "group"
+	c.Logging.Level = "info"
+
+	_ = c.ApplyDefaults()
+
+	return c
+}
+
+func getFields(err error) []string {
+	var validationError *ValidationError
+	if !errors.As(err, &validationError) {
+		return nil
+	}
+
+	fields := make([]string, 0, len(validationError.Errors))
+	for _, fieldError := range validationError.Errors {
+		fields = append(fields, fieldError.Field)
+	}
+
+	return fields
+}
+
+func TestValidate(t *testing.T) {
+	if err := getValidConfig().Validate(); err != nil {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", nil, err)
+	}
+}
+
+func TestValidateReportsEveryField(t *testing.T) {
+	c := &Dcp{}
+	c.Logging.Level = "info"
+	_ = c.ApplyDefaults()
+
+	fields := getFields(c.Validate())
+	expected := []string{"hosts", "username", "password", "bucketName", "dcp.group.name"}
+
+	if len(fields) != len(expected) {
+		t.Fatalf("Unexpected result. Expected: %v, Got: %v", expected, fields)
+	}
+
+	for i := range expected {
+		if fields[i] != expected[i] {
+			t.Errorf("Unexpected result. Expected: %v, Got: %v", expected[i], fields[i])
+		}
+	}
+}
+
+func TestValidateCombinations(t *testing.T) {
+	c := getValidConfig()
+	c.SecureConnection = true
+	c.Dcp.Group.Membership.Type = MembershipTypeKubernetesHa
+	c.Sources = []Source{{Name: "orders", BucketName: "archive"}}
+
+	fields := getFields(c.Validate())
+	expected := []string{"rootCAPath", "leaderElection.enabled", "sources[0].name"}
+
+	if len(fields) != len(expected) {
+		t.Fatalf("Unexpected result. Expected: %v, Got: %v", expected, fields)
+	}
+
+	for i := range expected {
+		if fields[i] != expected[i] {
+			t.Errorf("Unexpected result. Expected: %v, Got: %v", expected[i], fields[i])
+		}
+	}
+}
+
+func TestValidateGroupNameWithDot(t *testing.T) {
+	c := getValidConfig()
+	c.Dcp.Group.Name = "orders.v2"
+
+	fields := getFields(c.Validate())
+
+	if len(fields) != 1 || fields[0] != "dcp.group.name" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "dcp.group.name", fields)
+	}
+}
+
+func TestValidateLeaderElection(t *testing.T) {
+	c := getValidConfig()
+	c.LeaderElection.Enabled = true
+
+	fields := getFields(c.Validate())
+
+	if len(fields) != 1 || fields[0] != "leaderElection.config."+KubernetesLeaderElectorLeaseLockNameConfig {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", KubernetesLeaderElectorLeaseLockNameConfig, fields)
+	}
+}
+
+func TestValidateLeaderElectionTokenWithoutTLS(t *testing.T) {
+	c := getValidConfig()
+	c.LeaderElection.Enabled = true
+	c.LeaderElection.Config = map[string]string{
+		KubernetesLeaderElectorLeaseLockNameConfig:      "lock",
+		KubernetesLeaderElectorLeaseLockNamespaceConfig: "default",
+	}
+	c.LeaderElection.RPC.Token = "secret"
+
+	fields := getFields(c.Validate())
+
+	if len(fields) != 1 || fields[0] != "leaderElection.rpc.tls.enabled" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "leaderElection.rpc.tls.enabled", fields)
+	}
+}
+
+func TestValidateAPIAuth(t *testing.T) {
+	c := getValidConfig()
+	c.API.Auth.Type = APIAuthTypeBasic
+	c.API.Auth.Users = []APIUser{{Username: "ops", Role: "owner"}}
+
+	fields := getFields(c.Validate())
+	expected := []string{"api.auth.users[0].password", "api.auth.users[0].role"}
+
+	if len(fields) != len(expected) {
+		t.Fatalf("Unexpected result. Expected: %v, Got: %v", expected, fields)
+	}
+
+	for i := range expected {
+		if fields[i] != expected[i] {
+			t.Errorf("Unexpected result. Expected: %v, Got: %v", expected[i], fields[i])
+		}
+	}
+
+	c = getValidConfig()
+	c.API.Auth.Type = APIAuthTypeMTLS
+
+	fields = getFields(c.Validate())
+	expected = []string{"api.tls.enabled", "api.tls.rootCAPath"}
+
+	if len(fields) != len(expected) {
+		t.Fatalf("Unexpected result. Expected: %v, Got: %v", expected, fields)
+	}
+
+	for i := range expected {
+		if fields[i] != expected[i] {
+			t.Errorf("Unexpected result. Expected: %v, Got: %v", expected[i], fields[i])
+		}
+	}
+}
+
+func TestValidateTailMaxValueSize(t *testing.T) {
+	tests := []struct {
+		name         string
+		fields       []string
+		maxValueSize int
+	}{
+		{name: "whole values", maxValueSize: -1},
+		{name: "less than -1", maxValueSize: -2, fields: []string{"api.tail.maxValueSize"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := getValidConfig()
+			c.API.Tail.MaxValueSize = tt.maxValueSize
+
+			fields := getFields(c.Validate())
+
+			if len(fields) != len(tt.fields) || (len(fields) > 0 && fields[0] != tt.fields[0]) {
+				t.Errorf("Unexpected result. Expected: %v, Got: %v", tt.fields, fields)
+			}
+		})
+	}
+}
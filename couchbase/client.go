@@ -24,23 +24,24 @@ import (
 )
 
 type Client interface {
-	Ping() (*models.PingResult, error)
+	Ping(ctx context.Context) (*models.PingResult, error)
 	GetAgent() *gocbcore.Agent
 	GetMetaAgent() *gocbcore.Agent
 	Connect() error
 	Close()
 	DcpConnect(useExpiryOpcode bool, useChangeStreams bool) error
 	DcpClose()
-	GetVBucketSeqNos() (map[uint16]uint64, error)
-	GetNumVBuckets() int
-	GetFailoverLogs(vbID uint16) ([]gocbcore.FailoverEntry, error)
-	OpenStream(vbID uint16, collectionIDs map[uint32]string, offset *models.Offset, observer Observer) error
-	CloseStream(vbID uint16) error
-	GetCollectionIDs(scopeName string, collectionNames []string) map[uint32]string
+	GetVBucketSeqNos(ctx context.Context) (map[uint16]uint64, error)
+	GetNumVBuckets() (int, error)
+	GetFailoverLogs(ctx context.Context, vbID uint16) ([]gocbcore.FailoverEntry, error)
+	OpenStream(ctx context.Context, vbID uint16, collectionIDs map[uint32]string, offset *models.Offset, observer Observer) error
+	CloseStream(ctx context.Context, vbID uint16) error
+	GetCollectionIDs(ctx context.Context, scopeName string, collectionNames []string) (map[uint32]string, error)
 	GetConfigSnapshot() (*gocbcore.ConfigSnapshot, error)
 }
 
 type client struct {
+	logger    logger.Logger
 	agent     *gocbcore.Agent
 	metaAgent *gocbcore.Agent
 	dcpAgent  *gocbcore.DCPAgent
@@ -60,17 +61,18 @@ func getServiceEndpoint(result *gocbcore.PingResult, serviceType gocbcore.Servic
 	return ""
 }
 
-func (s *client) Ping() (*models.PingResult, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), s.config.HealthCheck.Timeout)
+func (s *client) Ping(ctx context.Context) (*models.PingResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.HealthCheck.Timeout)
 	defer cancel()
 
 	opm := NewAsyncOp(ctx)
-	errorCh := make(chan error)
+	errorCh := make(chan error, 1)
 
 	var pingResult models.PingResult
 
 	op, err := s.agent.Ping(gocbcore.PingOptions{
 		ServiceTypes: []gocbcore.ServiceType{gocbcore.MemdService, gocbcore.MgmtService},
+		TraceContext: RequestSpanFromContext(ctx),
 	}, func(result *gocbcore.PingResult, err error) {
 		if err == nil {
 			pingResult.MemdEndpoint = getServiceEndpoint(result, gocbcore.MemdService)
@@ -103,11 +105,10 @@ func (s *client) GetMetaAgent() *gocbcore.Agent {
 	return s.metaAgent
 }
 
-func CreateTLSRootCaProvider(rootCAPath string) func() *x509.CertPool {
+func CreateTLSRootCaProvider(rootCAPath string) (func() *x509.CertPool, error) {
 	cert, err := os.ReadFile(os.ExpandEnv(rootCAPath))
 	if err != nil {
-		logger.Log.Error("error while reading cert file: %v", err)
-		panic(err)
+		return nil, fmt.Errorf("error while reading cert file: %w", err)
 	}
 
 	certPool := x509.NewCertPool()
@@ -115,10 +116,10 @@ func CreateTLSRootCaProvider(rootCAPath string) func() *x509.CertPool {
 
 	return func() *x509.CertPool {
 		return certPool
-	}
+	}, nil
 }
 
-func CreateSecurityConfig(username string, password string, secureConnection bool, rootCAPath string) gocbcore.SecurityConfig {
+func CreateSecurityConfig(username string, password string, secureConnection bool, rootCAPath string) (gocbcore.SecurityConfig, error) {
 	securityConfig := gocbcore.SecurityConfig{
 		Auth: gocbcore.PasswordAuthProvider{
 			Username: username,
@@ -127,24 +128,39 @@ func CreateSecurityConfig(username string, password string, secureConnection boo
 	}
 
 	if secureConnection {
+		rootCaProvider, err := CreateTLSRootCaProvider(rootCAPath)
+		if err != nil {
+			return securityConfig, err
+		}
+
 		securityConfig.UseTLS = true
-		securityConfig.TLSRootCAProvider = CreateTLSRootCaProvider(rootCAPath)
+		securityConfig.TLSRootCAProvider = rootCaProvider
 	}
 
-	return securityConfig
+	return securityConfig, nil
 }
 
 func CreateAgent(httpAddresses []string, bucketName string,
 	username string, password string, secureConnection bool, rootCAPath string,
 	connectionBufferSize uint, connectionTimeout time.Duration,
 ) (*gocbcore.Agent, error) {
+	httpHosts, err := resolveHostsAsHTTP(httpAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	securityConfig, err := CreateSecurityConfig(username, password, secureConnection, rootCAPath)
+	if err != nil {
+		return nil, err
+	}
+
 	agent, err := gocbcore.CreateAgent(
 		&gocbcore.AgentConfig{
 			BucketName: bucketName,
 			SeedConfig: gocbcore.SeedConfig{
-				HTTPAddrs: resolveHostsAsHTTP(httpAddresses),
+				HTTPAddrs: httpHosts,
 			},
-			SecurityConfig: CreateSecurityConfig(username, password, secureConnection, rootCAPath),
+			SecurityConfig: securityConfig,
 			CompressionConfig: gocbcore.CompressionConfig{
 				Enabled: true,
 			},
@@ -187,16 +203,16 @@ func (s *client) connect(bucketName string, connectionBufferSize uint, connectio
 	return CreateAgent(s.config.Hosts, bucketName, s.config.Username, s.config.Password, s.config.SecureConnection, s.config.RootCAPath, connectionBufferSize, connectionTimeout) //nolint:lll
 }
 
-func resolveHostsAsHTTP(hosts []string) []string {
+func resolveHostsAsHTTP(hosts []string) ([]string, error) {
 	if len(hosts) == 1 {
 		parsedConnStr, err := connstr.Parse(hosts[0])
 		if err != nil {
-			panic("error parsing connection string " + hosts[0] + " " + err.Error())
+			return nil, fmt.Errorf("error parsing connection string %s: %w", hosts[0], err)
 		}
 
 		out, err := connstr.Resolve(parsedConnStr)
 		if err != nil {
-			panic("error resolving connection string " + parsedConnStr.String() + " " + err.Error())
+			return nil, fmt.Errorf("error resolving connection string %s: %w", parsedConnStr.String(), err)
 		}
 
 		var httpHosts []string
@@ -204,18 +220,29 @@ func resolveHostsAsHTTP(hosts []string) []string {
 			httpHosts = append(httpHosts, fmt.Sprintf("%s:%d", specHost.Host, specHost.Port))
 		}
 
-		return httpHosts
+		return httpHosts, nil
 	}
 
-	return hosts
+	return hosts, nil
 }
 
 func (s *client) Connect() error {
-	connectionBufferSize := uint(helpers.ResolveUnionIntOrStringValue(s.config.ConnectionBufferSize))
+	parsedConnectionBufferSize, err := helpers.ParseUnionIntOrStringValue(s.config.ConnectionBufferSize)
+	if err != nil {
+		return fmt.Errorf("invalid connectionBufferSize: %w", err)
+	}
+
+	connectionBufferSize := uint(parsedConnectionBufferSize)
 	connectionTimeout := s.config.ConnectionTimeout
 
+	var couchbaseMetadataConfig *config.CouchbaseMetadata
+
 	if s.config.IsCouchbaseMetadata() {
-		couchbaseMetadataConfig := s.config.GetCouchbaseMetadata()
+		couchbaseMetadataConfig, err = s.config.GetCouchbaseMetadata()
+		if err != nil {
+			return err
+		}
+
 		if couchbaseMetadataConfig.Bucket == s.config.BucketName {
 			if couchbaseMetadataConfig.ConnectionBufferSize > connectionBufferSize {
 				connectionBufferSize = couchbaseMetadataConfig.ConnectionBufferSize
@@ -234,8 +261,7 @@ func (s *client) Connect() error {
 
 	s.agent = agent
 
-	if s.config.IsCouchbaseMetadata() {
-		couchbaseMetadataConfig := s.config.GetCouchbaseMetadata()
+	if couchbaseMetadataConfig != nil {
 		if couchbaseMetadataConfig.Bucket == s.config.BucketName {
 			s.metaAgent = agent
 		} else {
@@ -245,17 +271,20 @@ func (s *client) Connect() error {
 				couchbaseMetadataConfig.ConnectionTimeout,
 			)
 			if err != nil {
+				_ = agent.Close()
+				s.agent = nil
+
 				return err
 			}
 
 			s.metaAgent = metaAgent
 		}
 
-		logger.Log.Info("connected to %s, bucket: %s, meta bucket: %s", s.config.Hosts, s.config.BucketName, couchbaseMetadataConfig.Bucket)
+		s.logger.Info("connected to %s, bucket: %s, meta bucket: %s", s.config.Hosts, s.config.BucketName, couchbaseMetadataConfig.Bucket)
 		return nil
 	}
 
-	logger.Log.Info("connected to %s, bucket: %s", s.config.Hosts, s.config.BucketName)
+	s.logger.Info("connected to %s, bucket: %s", s.config.Hosts, s.config.BucketName)
 
 	return nil
 }
@@ -269,21 +298,41 @@ func (s *client) Close() {
 		_ = s.agent.Close()
 	}
 
-	logger.Log.Info("connections closed %s", s.config.Hosts)
+	s.logger.Info("connections closed %s", s.config.Hosts)
 }
 
 func (s *client) DcpConnect(useExpiryOpcode bool, useChangeStreams bool) error {
+	httpHosts, err := resolveHostsAsHTTP(s.config.Hosts)
+	if err != nil {
+		return err
+	}
+
+	securityConfig, err := CreateSecurityConfig(s.config.Username, s.config.Password, s.config.SecureConnection, s.config.RootCAPath)
+	if err != nil {
+		return err
+	}
+
+	bufferSize, err := helpers.ParseUnionIntOrStringValue(s.config.Dcp.BufferSize)
+	if err != nil {
+		return fmt.Errorf("invalid dcp.bufferSize: %w", err)
+	}
+
+	connectionBufferSize, err := helpers.ParseUnionIntOrStringValue(s.config.Dcp.ConnectionBufferSize)
+	if err != nil {
+		return fmt.Errorf("invalid dcp.connectionBufferSize: %w", err)
+	}
+
 	agentConfig := &gocbcore.DCPAgentConfig{
 		BucketName: s.config.BucketName,
 		SeedConfig: gocbcore.SeedConfig{
-			HTTPAddrs: resolveHostsAsHTTP(s.config.Hosts),
+			HTTPAddrs: httpHosts,
 		},
-		SecurityConfig: CreateSecurityConfig(s.config.Username, s.config.Password, s.config.SecureConnection, s.config.RootCAPath),
+		SecurityConfig: securityConfig,
 		CompressionConfig: gocbcore.CompressionConfig{
 			Enabled: true,
 		},
 		DCPConfig: gocbcore.DCPConfig{
-			BufferSize:       helpers.ResolveUnionIntOrStringValue(s.config.Dcp.BufferSize),
+			BufferSize:       bufferSize,
 			UseExpiryOpcode:  useExpiryOpcode,
 			UseChangeStreams: useChangeStreams,
 		},
@@ -291,7 +340,7 @@ func (s *client) DcpConnect(useExpiryOpcode bool, useChangeStreams bool) error {
 			UseCollections: true,
 		},
 		KVConfig: gocbcore.KVConfig{
-			ConnectionBufferSize: uint(helpers.ResolveUnionIntOrStringValue(s.config.Dcp.ConnectionBufferSize)),
+			ConnectionBufferSize: uint(connectionBufferSize),
 		},
 	}
 
@@ -325,17 +374,17 @@ func (s *client) DcpConnect(useExpiryOpcode bool, useChangeStreams bool) error {
 	}
 
 	s.dcpAgent = client
-	logger.Log.Info("connected to %s as dcp, bucket: %s", s.config.Hosts, s.config.BucketName)
+	s.logger.Info("connected to %s as dcp, bucket: %s", s.config.Hosts, s.config.BucketName)
 
 	return nil
 }
 
 func (s *client) DcpClose() {
 	_ = s.dcpAgent.Close()
-	logger.Log.Info("dcp connection closed %s", s.config.Hosts)
+	s.logger.Info("dcp connection closed %s", s.config.Hosts)
 }
 
-func (s *client) GetVBucketSeqNos() (map[uint16]uint64, error) {
+func (s *client) GetVBucketSeqNos(ctx context.Context) (map[uint16]uint64, error) {
 	snapshot, err := s.GetConfigSnapshot()
 	if err != nil {
 		return nil, err
@@ -349,7 +398,7 @@ func (s *client) GetVBucketSeqNos() (map[uint16]uint64, error) {
 	seqNos := make(map[uint16]uint64)
 
 	for i := 1; i <= numNodes; i++ {
-		opm := NewAsyncOp(context.Background())
+		opm := NewAsyncOp(ctx)
 
 		op, err := s.dcpAgent.GetVbucketSeqnos(
 			i,
@@ -376,29 +425,27 @@ func (s *client) GetVBucketSeqNos() (map[uint16]uint64, error) {
 	return seqNos, nil
 }
 
-func (s *client) GetNumVBuckets() int {
+func (s *client) GetNumVBuckets() (int, error) {
 	snapshot, err := s.GetConfigSnapshot()
 	if err != nil {
-		logger.Log.Error("failed to get config snapshot: %v", err)
-		panic(err)
+		return 0, fmt.Errorf("failed to get config snapshot: %w", err)
 	}
 
 	vBuckets, err := snapshot.NumVbuckets()
 	if err != nil {
-		logger.Log.Error("failed to get number of vbucket: %v", err)
-		panic(err)
+		return 0, fmt.Errorf("failed to get number of vbucket: %w", err)
 	}
 
-	return vBuckets
+	return vBuckets, nil
 }
 
 func (s *client) GetConfigSnapshot() (*gocbcore.ConfigSnapshot, error) { //nolint:unused
 	return s.dcpAgent.ConfigSnapshot()
 }
 
-func (s *client) GetFailoverLogs(vbID uint16) ([]gocbcore.FailoverEntry, error) {
-	opm := NewAsyncOp(context.Background())
-	ch := make(chan error)
+func (s *client) GetFailoverLogs(ctx context.Context, vbID uint16) ([]gocbcore.FailoverEntry, error) {
+	opm := NewAsyncOp(ctx)
+	ch := make(chan error, 1)
 
 	var failoverLogs []gocbcore.FailoverEntry
 
@@ -420,20 +467,21 @@ func (s *client) GetFailoverLogs(vbID uint16) ([]gocbcore.FailoverEntry, error)
 	return failoverLogs, <-ch
 }
 
-func (s *client) openStreamWithRollback(vbID uint16,
+func (s *client) openStreamWithRollback(ctx context.Context,
+	vbID uint16,
 	failedSeqNo gocbcore.SeqNo,
 	rollbackSeqNo gocbcore.SeqNo,
 	observer Observer,
 	openStreamOptions gocbcore.OpenStreamOptions,
 ) error {
-	logger.Log.Info(
+	s.logger.Info(
 		"open stream with rollback, vbID: %d, failedSeqNo: %d, rollbackSeqNo: %d",
 		vbID, failedSeqNo, rollbackSeqNo,
 	)
 
-	opm := NewAsyncOp(context.Background())
+	opm := NewAsyncOp(ctx)
 
-	ch := make(chan error)
+	ch := make(chan error, 1)
 
 	op, err := s.dcpAgent.OpenStream(
 		vbID,
@@ -466,12 +514,13 @@ func (s *client) openStreamWithRollback(vbID uint16,
 }
 
 func (s *client) OpenStream(
+	ctx context.Context,
 	vbID uint16,
 	collectionIDs map[uint32]string,
 	offset *models.Offset,
 	observer Observer,
 ) error {
-	opm := NewAsyncOp(context.Background())
+	opm := NewAsyncOp(ctx)
 
 	openStreamOptions := gocbcore.OpenStreamOptions{}
 
@@ -491,7 +540,7 @@ func (s *client) OpenStream(
 		}
 	}
 
-	ch := make(chan error)
+	ch := make(chan error, 1)
 
 	op, err := s.dcpAgent.OpenStream(
 		vbID,
@@ -523,18 +572,18 @@ func (s *client) OpenStream(
 
 	if err != nil {
 		if rollbackErr, ok := err.(gocbcore.DCPRollbackError); ok {
-			logger.Log.Info("need to rollback for vbID: %d, vbUUID: %d", vbID, offset.VbUUID)
-			return s.openStreamWithRollback(vbID, gocbcore.SeqNo(offset.SeqNo), rollbackErr.SeqNo, observer, openStreamOptions)
+			s.logger.With("vbId", vbID, "seqNo", offset.SeqNo).Info("need to rollback, vbUUID: %d", offset.VbUUID)
+			return s.openStreamWithRollback(ctx, vbID, gocbcore.SeqNo(offset.SeqNo), rollbackErr.SeqNo, observer, openStreamOptions)
 		}
 	}
 
 	return err
 }
 
-func (s *client) CloseStream(vbID uint16) error {
-	opm := NewAsyncOp(context.Background())
+func (s *client) CloseStream(ctx context.Context, vbID uint16) error {
+	opm := NewAsyncOp(ctx)
 
-	ch := make(chan error)
+	ch := make(chan error, 1)
 
 	op, err := s.dcpAgent.CloseStream(
 		vbID,
@@ -555,15 +604,20 @@ func (s *client) CloseStream(vbID uint16) error {
 	return <-ch
 }
 
-func (s *client) getCollectionID(scopeName string, collectionName string) (uint32, error) {
-	opm := NewAsyncOp(context.Background())
+func (s *client) getCollectionID(ctx context.Context, scopeName string, collectionName string) (uint32, error) {
+	opm := NewAsyncOp(ctx)
+
+	deadline, _ := ctx.Deadline()
 
-	ch := make(chan error)
+	ch := make(chan error, 1)
 	var collectionID uint32
 	op, err := s.agent.GetCollectionID(
 		scopeName,
 		collectionName,
-		gocbcore.GetCollectionIDOptions{},
+		gocbcore.GetCollectionIDOptions{
+			Deadline:     deadline,
+			TraceContext: RequestSpanFromContext(ctx),
+		},
 		func(result *gocbcore.GetCollectionIDResult, err error) {
 			if err == nil {
 				collectionID = result.CollectionID
@@ -582,26 +636,26 @@ func (s *client) getCollectionID(scopeName string, collectionName string) (uint3
 	return collectionID, <-ch
 }
 
-func (s *client) GetCollectionIDs(scopeName string, collectionNames []string) map[uint32]string {
+func (s *client) GetCollectionIDs(ctx context.Context, scopeName string, collectionNames []string) (map[uint32]string, error) {
 	collectionIDs := map[uint32]string{}
 
 	if s.dcpAgent.HasCollectionsSupport() {
 		for _, collectionName := range collectionNames {
-			collectionID, err := s.getCollectionID(scopeName, collectionName)
+			collectionID, err := s.getCollectionID(ctx, scopeName, collectionName)
 			if err != nil {
-				logger.Log.Error("cannot get collection ids: %v", err)
-				panic(err)
+				return nil, fmt.Errorf("cannot get collection id of %s.%s: %w", scopeName, collectionName, err)
 			}
 
 			collectionIDs[collectionID] = collectionName
 		}
 	}
 
-	return collectionIDs
+	return collectionIDs, nil
 }
 
-func NewClient(config *config.Dcp) Client {
+func NewClient(config *config.Dcp, logger logger.Logger) Client {
 	return &client{
+		logger:   logger,
 		agent:    nil,
 		dcpAgent: nil,
 		config:   config,
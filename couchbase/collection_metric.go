@@ -0,0 +1,102 @@
+package couchbase
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// OtherCollectionName labels the collections after the collection label limit.
+const OtherCollectionName = "_other"
+
+const (
+	MutationEventType   = "mutation"
+	DeletionEventType   = "deletion"
+	ExpirationEventType = "expiration"
+)
+
+type CollectionMetricKey struct {
+	Scope      string
+	Collection string
+	EventType  string
+}
+
+type CollectionMetric struct {
+	Events atomic.Uint64
+	// Bytes is the total size of the keys and the values.
+	Bytes atomic.Uint64
+}
+
+// CollectionMetrics counts events per collection and event type. Once limit collections are labeled,
+// the events of the others are counted under OtherCollectionName, a limit of 0 labels every collection.
+type CollectionMetrics struct {
+	metrics     sync.Map
+	labeled     map[CollectionMetricKey]*CollectionMetric
+	collections map[string]struct{}
+	scopeName   string
+	lock        sync.RWMutex
+	limit       int
+}
+
+func (c *CollectionMetrics) Add(collectionName string, eventType string, bytes int) {
+	key := CollectionMetricKey{Scope: c.scopeName, Collection: collectionName, EventType: eventType}
+
+	metric, ok := c.metrics.Load(key)
+	if !ok {
+		metric = c.register(key)
+	}
+
+	metric.(*CollectionMetric).Events.Add(1)
+	metric.(*CollectionMetric).Bytes.Add(uint64(bytes))
+}
+
+// register maps key to the metric of its label, the metric is shared when the collection is over the limit.
+func (c *CollectionMetrics) register(key CollectionMetricKey) *CollectionMetric {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if metric, ok := c.metrics.Load(key); ok {
+		return metric.(*CollectionMetric)
+	}
+
+	label := key
+
+	if _, ok := c.collections[key.Collection]; !ok {
+		if c.limit > 0 && len(c.collections) >= c.limit {
+			label.Collection = OtherCollectionName
+		} else {
+			c.collections[key.Collection] = struct{}{}
+		}
+	}
+
+	metric, ok := c.labeled[label]
+	if !ok {
+		metric = &CollectionMetric{}
+		c.labeled[label] = metric
+	}
+
+	c.metrics.Store(key, metric)
+
+	return metric
+}
+
+func (c *CollectionMetrics) Range(fn func(key CollectionMetricKey, metric *CollectionMetric)) {
+	c.lock.RLock()
+	labeled := make(map[CollectionMetricKey]*CollectionMetric, len(c.labeled))
+	for key, metric := range c.labeled {
+		labeled[key] = metric
+	}
+	c.lock.RUnlock()
+
+	for key, metric := range labeled {
+		fn(key, metric)
+	}
+}
+
+func NewCollectionMetrics(scopeName string, limit int) *CollectionMetrics {
+	return &CollectionMetrics{
+		scopeName:   scopeName,
+		labeled:     map[CollectionMetricKey]*CollectionMetric{},
+		collections: map[string]struct{}{},
+		limit:       limit,
+	}
+}
@@ -0,0 +1,95 @@
+package couchbase
+
+import (
+	"testing"
+
+	"github.com/asaskevich/EventBus"
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+func TestCollectionMetricsLimit(t *testing.T) {
+	collectionMetrics := NewCollectionMetrics("inventory", 2)
+
+	collectionMetrics.Add("orders", MutationEventType, 10)
+	collectionMetrics.Add("users", MutationEventType, 20)
+	collectionMetrics.Add("orders", DeletionEventType, 5)
+	collectionMetrics.Add("carts", MutationEventType, 30)
+	collectionMetrics.Add("payments", MutationEventType, 40)
+
+	events := map[CollectionMetricKey]uint64{}
+	bytes := map[CollectionMetricKey]uint64{}
+
+	collectionMetrics.Range(func(key CollectionMetricKey, metric *CollectionMetric) {
+		events[key] = metric.Events.Load()
+		bytes[key] = metric.Bytes.Load()
+	})
+
+	if len(events) != 4 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 4, events)
+	}
+
+	other := CollectionMetricKey{Scope: "inventory", Collection: OtherCollectionName, EventType: MutationEventType}
+	if events[other] != 2 || bytes[other] != 70 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "2 events and 70 bytes", events[other])
+	}
+
+	orders := CollectionMetricKey{Scope: "inventory", Collection: "orders", EventType: DeletionEventType}
+	if events[orders] != 1 || bytes[orders] != 5 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "1 event and 5 bytes", events[orders])
+	}
+}
+
+func TestCollectionMetricsWithoutLimit(t *testing.T) {
+	collectionMetrics := NewCollectionMetrics("_default", 0)
+
+	for _, collectionName := range []string{"a", "b", "c", "d"} {
+		collectionMetrics.Add(collectionName, ExpirationEventType, 1)
+	}
+
+	var count int
+	collectionMetrics.Range(func(key CollectionMetricKey, _ *CollectionMetric) {
+		if key.Collection == OtherCollectionName {
+			t.Errorf("Unexpected result. Expected: %v, Got: %v", "no other collection", key)
+		}
+
+		count++
+	})
+
+	if count != 4 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 4, count)
+	}
+}
+
+func TestCollectionMetricsAreKeptAcrossObservers(t *testing.T) {
+	collectionMetrics := NewCollectionMetrics("_default", 0)
+
+	c := &config.Dcp{}
+	c.RollbackMitigation.Disabled = true
+
+	for i := 0; i < 2; i++ {
+		observer, err := NewObserver(c, nil, collectionMetrics, EventBus.New(), &logger.Loggers{Logrus: logrus.New()})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		observer.Expiration(gocbcore.DcpExpiration{VbID: 1, SeqNo: uint64(i + 1), Key: []byte("key")})
+		observer.Close()
+	}
+
+	expiration := CollectionMetricKey{Scope: "_default", Collection: DefaultCollectionName, EventType: ExpirationEventType}
+
+	var events uint64
+	collectionMetrics.Range(func(key CollectionMetricKey, metric *CollectionMetric) {
+		if key == expiration {
+			events = metric.Events.Load()
+		}
+	})
+
+	if events != 2 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 2, events)
+	}
+}
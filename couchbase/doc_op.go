@@ -21,13 +21,14 @@ func CreateDocument(ctx context.Context,
 
 	deadline, _ := ctx.Deadline()
 
-	ch := make(chan error)
+	ch := make(chan error, 1)
 
 	op, err := agent.Set(gocbcore.SetOptions{
 		Key:            id,
 		Value:          value,
 		Flags:          flags,
 		Deadline:       deadline,
+		TraceContext:   RequestSpanFromContext(ctx),
 		Expiry:         expiry,
 		ScopeName:      scopeName,
 		CollectionName: collectionName,
@@ -46,6 +47,86 @@ func CreateDocument(ctx context.Context,
 	return <-ch
 }
 
+// InsertDocument creates the document, it fails with gocbcore.ErrDocumentExists if the document already exists.
+func InsertDocument(ctx context.Context,
+	agent *gocbcore.Agent,
+	scopeName string,
+	collectionName string,
+	id []byte,
+	value []byte,
+	flags uint32,
+	expiry uint32,
+) error {
+	opm := NewAsyncOp(ctx)
+
+	deadline, _ := ctx.Deadline()
+
+	ch := make(chan error, 1)
+
+	op, err := agent.Add(gocbcore.AddOptions{
+		Key:            id,
+		Value:          value,
+		Flags:          flags,
+		Deadline:       deadline,
+		TraceContext:   RequestSpanFromContext(ctx),
+		Expiry:         expiry,
+		ScopeName:      scopeName,
+		CollectionName: collectionName,
+	}, func(result *gocbcore.StoreResult, err error) {
+		opm.Resolve()
+
+		ch <- err
+	})
+
+	err = opm.Wait(op, err)
+
+	if err != nil {
+		return err
+	}
+
+	return <-ch
+}
+
+// ReplaceDocumentWithCas replaces the document only if it is still at the given cas.
+func ReplaceDocumentWithCas(ctx context.Context,
+	agent *gocbcore.Agent,
+	scopeName string,
+	collectionName string,
+	id []byte,
+	value []byte,
+	flags uint32,
+	cas gocbcore.Cas,
+) error {
+	opm := NewAsyncOp(ctx)
+
+	deadline, _ := ctx.Deadline()
+
+	ch := make(chan error, 1)
+
+	op, err := agent.Replace(gocbcore.ReplaceOptions{
+		Key:            id,
+		Value:          value,
+		Flags:          flags,
+		Cas:            cas,
+		Deadline:       deadline,
+		TraceContext:   RequestSpanFromContext(ctx),
+		ScopeName:      scopeName,
+		CollectionName: collectionName,
+	}, func(result *gocbcore.StoreResult, err error) {
+		opm.Resolve()
+
+		ch <- err
+	})
+
+	err = opm.Wait(op, err)
+
+	if err != nil {
+		return err
+	}
+
+	return <-ch
+}
+
 func UpdateDocument(ctx context.Context,
 	agent *gocbcore.Agent,
 	scopeName string,
@@ -58,7 +139,7 @@ func UpdateDocument(ctx context.Context,
 
 	deadline, _ := ctx.Deadline()
 
-	ch := make(chan error)
+	ch := make(chan error, 1)
 
 	op, err := agent.MutateIn(gocbcore.MutateInOptions{
 		Key: id,
@@ -70,6 +151,7 @@ func UpdateDocument(ctx context.Context,
 		},
 		Expiry:         expiry,
 		Deadline:       deadline,
+		TraceContext:   RequestSpanFromContext(ctx),
 		ScopeName:      scopeName,
 		CollectionName: collectionName,
 	}, func(result *gocbcore.MutateInResult, err error) {
@@ -94,11 +176,12 @@ func DeleteDocument(ctx context.Context, agent *gocbcore.Agent, scopeName string
 
 	deadline, _ := ctx.Deadline()
 
-	ch := make(chan error)
+	ch := make(chan error, 1)
 
 	op, err := agent.Delete(gocbcore.DeleteOptions{
 		Key:            id,
 		Deadline:       deadline,
+		TraceContext:   RequestSpanFromContext(ctx),
 		ScopeName:      scopeName,
 		CollectionName: collectionName,
 	}, func(result *gocbcore.DeleteResult, err error) {
@@ -124,12 +207,25 @@ func UpsertXattrs(ctx context.Context,
 	path string,
 	value []byte,
 	expiry uint32,
+) error {
+	return UpsertXattrsWithCas(ctx, agent, scopeName, collectionName, id, path, value, expiry, 0)
+}
+
+func UpsertXattrsWithCas(ctx context.Context,
+	agent *gocbcore.Agent,
+	scopeName string,
+	collectionName string,
+	id []byte,
+	path string,
+	value []byte,
+	expiry uint32,
+	cas gocbcore.Cas,
 ) error {
 	opm := NewAsyncOp(ctx)
 
 	deadline, _ := ctx.Deadline()
 
-	ch := make(chan error)
+	ch := make(chan error, 1)
 
 	op, err := agent.MutateIn(gocbcore.MutateInOptions{
 		Key: id,
@@ -142,7 +238,9 @@ func UpsertXattrs(ctx context.Context,
 			},
 		},
 		Expiry:         expiry,
+		Cas:            cas,
 		Deadline:       deadline,
+		TraceContext:   RequestSpanFromContext(ctx),
 		ScopeName:      scopeName,
 		CollectionName: collectionName,
 	}, func(result *gocbcore.MutateInResult, err error) {
@@ -163,10 +261,23 @@ func UpsertXattrs(ctx context.Context,
 }
 
 func GetXattrs(ctx context.Context, agent *gocbcore.Agent, scopeName string, collectionName string, id []byte, path string) ([]byte, error) { //nolint:lll
+	document, _, err := GetXattrsWithCas(ctx, agent, scopeName, collectionName, id, path)
+	return document, err
+}
+
+func GetXattrsWithCas(ctx context.Context,
+	agent *gocbcore.Agent,
+	scopeName string,
+	collectionName string,
+	id []byte,
+	path string,
+) ([]byte, gocbcore.Cas, error) {
 	opm := NewAsyncOp(ctx)
 
-	errorCh := make(chan error)
-	documentCh := make(chan []byte)
+	deadline, _ := ctx.Deadline()
+
+	errorCh := make(chan error, 1)
+	documentCh := make(chan *gocbcore.LookupInResult, 1)
 
 	op, err := agent.LookupIn(gocbcore.LookupInOptions{
 		Key: id,
@@ -177,13 +288,15 @@ func GetXattrs(ctx context.Context, agent *gocbcore.Agent, scopeName string, col
 				Path:  path,
 			},
 		},
+		Deadline:       deadline,
+		TraceContext:   RequestSpanFromContext(ctx),
 		ScopeName:      scopeName,
 		CollectionName: collectionName,
 	}, func(result *gocbcore.LookupInResult, err error) {
 		opm.Resolve()
 
 		if err == nil {
-			documentCh <- result.Ops[0].Value
+			documentCh <- result
 		} else {
 			documentCh <- nil
 		}
@@ -194,33 +307,48 @@ func GetXattrs(ctx context.Context, agent *gocbcore.Agent, scopeName string, col
 	err = opm.Wait(op, err)
 
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	document := <-documentCh
+	result := <-documentCh
 	err = <-errorCh
 
-	return document, err
+	if result == nil {
+		return nil, 0, err
+	}
+
+	return result.Ops[0].Value, result.Cas, err
 }
 
 func Get(ctx context.Context, agent *gocbcore.Agent, scopeName string, collectionName string, id []byte) ([]byte, error) {
-	opm := NewAsyncOp(context.Background())
+	document, _, err := GetWithCas(ctx, agent, scopeName, collectionName, id)
+	return document, err
+}
+
+func GetWithCas(ctx context.Context,
+	agent *gocbcore.Agent,
+	scopeName string,
+	collectionName string,
+	id []byte,
+) ([]byte, gocbcore.Cas, error) {
+	opm := NewAsyncOp(ctx)
 
 	deadline, _ := ctx.Deadline()
 
-	errorCh := make(chan error)
-	documentCh := make(chan []byte)
+	errorCh := make(chan error, 1)
+	documentCh := make(chan *gocbcore.GetResult, 1)
 
 	op, err := agent.Get(gocbcore.GetOptions{
 		Key:            id,
 		Deadline:       deadline,
+		TraceContext:   RequestSpanFromContext(ctx),
 		ScopeName:      scopeName,
 		CollectionName: collectionName,
 	}, func(result *gocbcore.GetResult, err error) {
 		opm.Resolve()
 
 		if err == nil {
-			documentCh <- result.Value
+			documentCh <- result
 		} else {
 			documentCh <- nil
 		}
@@ -231,13 +359,65 @@ func Get(ctx context.Context, agent *gocbcore.Agent, scopeName string, collectio
 	err = opm.Wait(op, err)
 
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	document := <-documentCh
+	result := <-documentCh
 	err = <-errorCh
 
-	return document, err
+	if result == nil {
+		return nil, 0, err
+	}
+
+	return result.Value, result.Cas, err
+}
+
+// RemovePaths deletes the given sub-document paths only if the document is still at the given cas.
+func RemovePaths(ctx context.Context,
+	agent *gocbcore.Agent,
+	scopeName string,
+	collectionName string,
+	id []byte,
+	paths []string,
+	cas gocbcore.Cas,
+) error {
+	opm := NewAsyncOp(ctx)
+
+	deadline, _ := ctx.Deadline()
+
+	ch := make(chan error, 1)
+
+	ops := make([]gocbcore.SubDocOp, 0, len(paths))
+	for _, path := range paths {
+		ops = append(ops, gocbcore.SubDocOp{
+			Op:   memd.SubDocOpDelete,
+			Path: path,
+		})
+	}
+
+	op, err := agent.MutateIn(gocbcore.MutateInOptions{
+		Key:            id,
+		Ops:            ops,
+		Cas:            cas,
+		Deadline:       deadline,
+		TraceContext:   RequestSpanFromContext(ctx),
+		ScopeName:      scopeName,
+		CollectionName: collectionName,
+	}, func(result *gocbcore.MutateInResult, err error) {
+		opm.Resolve()
+
+		ch <- err
+	})
+
+	err = opm.Wait(op, err)
+
+	if err != nil {
+		return err
+	}
+
+	err = <-ch
+
+	return err
 }
 
 func CreatePath(ctx context.Context,
@@ -253,7 +433,7 @@ func CreatePath(ctx context.Context,
 
 	deadline, _ := ctx.Deadline()
 
-	ch := make(chan error)
+	ch := make(chan error, 1)
 
 	op, err := agent.MutateIn(gocbcore.MutateInOptions{
 		Key:   id,
@@ -266,6 +446,7 @@ func CreatePath(ctx context.Context,
 			},
 		},
 		Deadline:       deadline,
+		TraceContext:   RequestSpanFromContext(ctx),
 		ScopeName:      scopeName,
 		CollectionName: collectionName,
 	}, func(result *gocbcore.MutateInResult, err error) {
@@ -0,0 +1,112 @@
+package couchbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/helpers"
+	"github.com/Trendyol/go-dcp/membership"
+	"github.com/Trendyol/go-dcp/metadata"
+
+	"github.com/json-iterator/go"
+
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/couchbase/gocbcore/v10/memd"
+)
+
+// cbEpochStore keeps the fencing token in a document of the metadata collection,
+// it is only increased with cas so every assignment of the group gets a greater token than the previous ones.
+type cbEpochStore struct {
+	client         Client
+	id             []byte
+	scopeName      string
+	collectionName string
+}
+
+type epochDocument struct {
+	Members []string `json:"members"`
+	Epoch   uint64   `json:"epoch"`
+}
+
+func (s *cbEpochStore) Next(ctx context.Context, members []string) (uint64, error) {
+	for {
+		document, cas, err := s.get(ctx)
+
+		var kvErr *gocbcore.KeyValueError
+		if err != nil && errors.As(err, &kvErr) && kvErr.StatusCode == memd.StatusKeyNotFound {
+			document, cas = &epochDocument{}, 0
+		} else if err != nil {
+			return 0, err
+		}
+
+		if cas != 0 && slices.Equal(document.Members, members) {
+			// another member already stored the same assignment
+			return document.Epoch, nil
+		}
+
+		next := &epochDocument{Members: members, Epoch: document.Epoch + 1}
+		payload, _ := jsoniter.Marshal(next)
+
+		if cas == 0 {
+			err = InsertDocument(ctx, s.client.GetMetaAgent(), s.scopeName, s.collectionName, s.id, payload, helpers.JSONFlags, 0)
+		} else {
+			err = ReplaceDocumentWithCas(
+				ctx, s.client.GetMetaAgent(), s.scopeName, s.collectionName, s.id, payload, helpers.JSONFlags, cas,
+			)
+		}
+
+		if err == nil {
+			return next.Epoch, nil
+		}
+
+		if !errors.Is(err, gocbcore.ErrCasMismatch) && !errors.Is(err, gocbcore.ErrDocumentExists) {
+			return 0, fmt.Errorf("cannot store epoch: %w", err)
+		}
+		// another member stored an assignment in between, it is read again
+	}
+}
+
+func (s *cbEpochStore) Current(ctx context.Context) (uint64, []string, error) {
+	document, _, err := s.get(ctx)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return document.Epoch, document.Members, nil
+}
+
+func (s *cbEpochStore) get(ctx context.Context) (*epochDocument, gocbcore.Cas, error) {
+	data, cas, err := GetWithCas(ctx, s.client.GetMetaAgent(), s.scopeName, s.collectionName, s.id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var document epochDocument
+	if err := jsoniter.Unmarshal(data, &document); err != nil {
+		return nil, 0, fmt.Errorf("cannot unmarshal epoch: %w", err)
+	}
+
+	return &document, cas, nil
+}
+
+// NewCBEpochStore creates the epoch store of the group in the couchbase metadata collection.
+func NewCBEpochStore(client Client, config *config.Dcp) (membership.EpochStore, error) {
+	if !config.IsCouchbaseMetadata() {
+		return nil, fmt.Errorf("cannot initialize couchbase epoch store: %w", metadata.ErrUnsupportedMetadataType)
+	}
+
+	couchbaseMetadataConfig, err := config.GetCouchbaseMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	return &cbEpochStore{
+		client:         client,
+		id:             []byte(helpers.Prefix + config.Dcp.Group.Name + ":epoch"),
+		scopeName:      couchbaseMetadataConfig.Scope,
+		collectionName: couchbaseMetadataConfig.Collection,
+	}, nil
+}
@@ -1,6 +1,8 @@
 package couchbase
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"github.com/Trendyol/go-dcp/config"
@@ -8,38 +10,65 @@ import (
 )
 
 type HealthCheck interface {
-	Start(ch chan struct{})
+	Start(ctx context.Context, ch chan struct{})
+	Reset(interval time.Duration)
 	Stop()
 }
 
 type healthCheck struct {
-	ticker *time.Ticker
-	config *config.HealthCheck
-	client Client
+	logger     logger.Logger
+	ticker     *time.Ticker
+	config     *config.HealthCheck
+	client     Client
+	tickerLock *sync.Mutex
 }
 
-func (h *healthCheck) Start(ch chan struct{}) {
-	h.ticker = time.NewTicker(h.config.Interval)
+func (h *healthCheck) Start(ctx context.Context, ch chan struct{}) {
+	h.tickerLock.Lock()
+	ticker := time.NewTicker(h.config.GetInterval())
+	h.ticker = ticker
+	h.tickerLock.Unlock()
 
 	go func() {
-		for range h.ticker.C {
-			if _, err := h.client.Ping(); err != nil {
-				logger.Log.Error("health check failed: %v", err)
-				h.ticker.Stop()
-				ch <- struct{}{}
+		for range ticker.C {
+			if _, err := h.client.Ping(ctx); err != nil {
+				ticker.Stop()
+
+				// cancelled context means the connector is shutting down
+				if ctx.Err() == nil {
+					h.logger.Error("health check failed: %v", err)
+					ch <- struct{}{}
+				}
 				break
 			}
 		}
 	}()
 }
 
+// Reset applies a reloaded interval to the running health check.
+func (h *healthCheck) Reset(interval time.Duration) {
+	h.tickerLock.Lock()
+	defer h.tickerLock.Unlock()
+
+	if h.ticker != nil {
+		h.ticker.Reset(interval)
+	}
+}
+
 func (h *healthCheck) Stop() {
-	h.ticker.Stop()
+	h.tickerLock.Lock()
+	defer h.tickerLock.Unlock()
+
+	if h.ticker != nil {
+		h.ticker.Stop()
+	}
 }
 
-func NewHealthCheck(config *config.HealthCheck, client Client) HealthCheck {
+func NewHealthCheck(config *config.HealthCheck, client Client, logger logger.Logger) HealthCheck {
 	return &healthCheck{
-		config: config,
-		client: client,
+		logger:     logger,
+		config:     config,
+		client:     client,
+		tickerLock: &sync.Mutex{},
 	}
 }
@@ -0,0 +1,140 @@
+package couchbase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+var ErrHighSeqNosNotSampled = errors.New("high seq nos are not sampled yet")
+
+// HighSeqNos are the high seq nos of the active vBuckets at SampledAt.
+type HighSeqNos struct {
+	SampledAt time.Time
+	SeqNos    map[uint16]uint64
+}
+
+type HighSeqNoSamplerMetric struct {
+	Samples      atomic.Int64
+	Failures     atomic.Int64
+	LastDuration atomic.Int64
+}
+
+// HighSeqNoSampler samples the high seq nos of the bucket in the background, so metric scrapes and the api
+// read them from memory instead of asking every node.
+type HighSeqNoSampler interface {
+	Start()
+	Stop()
+	// Get returns the latest sample, with an error as well when it is older than the staleness.
+	Get() (*HighSeqNos, error)
+	GetMetric() *HighSeqNoSamplerMetric
+}
+
+type highSeqNoSampler struct {
+	logger  logger.Logger
+	client  Client
+	latest  atomic.Pointer[HighSeqNos]
+	metric  *HighSeqNoSamplerMetric
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+	config  *config.HighSeqNoSampler
+	runLock sync.Mutex
+}
+
+func (s *highSeqNoSampler) Start() {
+	s.runLock.Lock()
+	defer s.runLock.Unlock()
+
+	if s.stopCh != nil {
+		return
+	}
+
+	s.stopCh = make(chan struct{})
+	s.doneCh = make(chan struct{})
+
+	go s.run(s.stopCh, s.doneCh)
+}
+
+func (s *highSeqNoSampler) Stop() {
+	s.runLock.Lock()
+	defer s.runLock.Unlock()
+
+	if s.stopCh == nil {
+		return
+	}
+
+	close(s.stopCh)
+	<-s.doneCh
+
+	s.stopCh = nil
+}
+
+func (s *highSeqNoSampler) run(stopCh chan struct{}, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.sample()
+
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *highSeqNoSampler) sample() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Interval)
+	defer cancel()
+
+	start := time.Now()
+
+	seqNos, err := s.client.GetVBucketSeqNos(ctx)
+
+	s.metric.LastDuration.Store(int64(time.Since(start)))
+
+	if err != nil {
+		s.metric.Failures.Add(1)
+		s.logger.Warn("cannot sample high seq nos: %v", err)
+
+		return
+	}
+
+	s.metric.Samples.Add(1)
+	s.latest.Store(&HighSeqNos{SeqNos: seqNos, SampledAt: start})
+}
+
+func (s *highSeqNoSampler) Get() (*HighSeqNos, error) {
+	latest := s.latest.Load()
+	if latest == nil {
+		return nil, ErrHighSeqNosNotSampled
+	}
+
+	if age := time.Since(latest.SampledAt); age > s.config.Staleness {
+		return latest, fmt.Errorf("high seq nos are stale, sampled %v ago", age.Truncate(time.Second))
+	}
+
+	return latest, nil
+}
+
+func (s *highSeqNoSampler) GetMetric() *HighSeqNoSamplerMetric {
+	return s.metric
+}
+
+func NewHighSeqNoSampler(client Client, config *config.HighSeqNoSampler, logger logger.Logger) HighSeqNoSampler {
+	return &highSeqNoSampler{
+		client: client,
+		config: config,
+		metric: &HighSeqNoSamplerMetric{},
+		logger: logger,
+	}
+}
@@ -0,0 +1,97 @@
+package couchbase
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+type seqNoClient struct {
+	Client
+	err   error
+	calls atomic.Int64
+}
+
+func (c *seqNoClient) GetVBucketSeqNos(_ context.Context) (map[uint16]uint64, error) {
+	c.calls.Add(1)
+
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return map[uint16]uint64{0: 10, 1: 20}, nil
+}
+
+func TestHighSeqNoSampler(t *testing.T) {
+	_ = logger.InitDefaultLogger("error")
+
+	client := &seqNoClient{}
+	sampler := NewHighSeqNoSampler(client, &config.HighSeqNoSampler{
+		Interval:  10 * time.Millisecond,
+		Staleness: time.Minute,
+	}, logger.Log)
+
+	if _, err := sampler.Get(); !errors.Is(err, ErrHighSeqNosNotSampled) {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", ErrHighSeqNosNotSampled, err)
+	}
+
+	sampler.Start()
+	sampler.Start()
+	time.Sleep(50 * time.Millisecond)
+	sampler.Stop()
+	sampler.Stop()
+
+	highSeqNos, err := sampler.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if highSeqNos.SeqNos[1] != 20 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 20, highSeqNos.SeqNos[1])
+	}
+
+	calls := client.calls.Load()
+	if calls < 2 || sampler.GetMetric().Samples.Load() != calls {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", calls, sampler.GetMetric().Samples.Load())
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if client.calls.Load() != calls {
+		t.Errorf("Sampler is not stopped")
+	}
+}
+
+func TestHighSeqNoSamplerFailureAndStaleness(t *testing.T) {
+	_ = logger.InitDefaultLogger("error")
+
+	client := &seqNoClient{}
+	sampler := NewHighSeqNoSampler(client, &config.HighSeqNoSampler{
+		Interval:  time.Hour,
+		Staleness: time.Hour,
+	}, logger.Log).(*highSeqNoSampler)
+
+	sampler.sample()
+
+	client.err = errors.New("node is down")
+	sampler.sample()
+
+	if sampler.GetMetric().Failures.Load() != 1 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 1, sampler.GetMetric().Failures.Load())
+	}
+
+	if _, err := sampler.Get(); err != nil {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", nil, err)
+	}
+
+	sampler.latest.Load().SampledAt = time.Now().Add(-2 * time.Hour)
+
+	if highSeqNos, err := sampler.Get(); err == nil || highSeqNos == nil {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "stale sample with error", err)
+	}
+}
@@ -1,6 +1,7 @@
 package couchbase
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 
@@ -41,7 +42,7 @@ type httpClient struct {
 }
 
 func (h *httpClient) Connect() error {
-	pingResult, err := h.client.Ping()
+	pingResult, err := h.client.Ping(context.Background())
 	if err != nil {
 		return err
 	}
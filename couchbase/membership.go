@@ -3,8 +3,11 @@ package couchbase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/asaskevich/EventBus"
@@ -14,6 +17,7 @@ import (
 	"github.com/Trendyol/go-dcp/helpers"
 	"github.com/Trendyol/go-dcp/logger"
 	"github.com/Trendyol/go-dcp/membership"
+	"github.com/Trendyol/go-dcp/metadata"
 
 	"github.com/json-iterator/go"
 
@@ -24,20 +28,36 @@ import (
 )
 
 type cbMembership struct {
+	logger              logger.Logger
 	client              Client
 	bus                 EventBus.Bus
-	info                *membership.Model
+	info                atomic.Pointer[membership.Model]
 	infoChan            chan *membership.Model
 	heartbeatTicker     *time.Ticker
 	config              *config.Dcp
 	membershipConfig    *config.CouchbaseMembership
 	monitorTicker       *time.Ticker
+	metric              *MembershipMetric
+	epochStore          membership.EpochStore
 	scopeName           string
 	collectionName      string
 	lastActiveInstances []Instance
 	instanceAll         []byte
+	instanceLock        sync.RWMutex
 	id                  []byte
 	clusterJoinTime     int64
+	epoch               atomic.Uint64
+}
+
+type MembershipMetricProvider interface {
+	GetMetric() *MembershipMetric
+}
+
+type MembershipMetric struct {
+	Joined        atomic.Int64
+	Left          atomic.Int64
+	Pruned        atomic.Int64
+	PruneConflict atomic.Int64
 }
 
 type Instance struct {
@@ -47,19 +67,23 @@ type Instance struct {
 	ClusterJoinTime int64   `json:"clusterJoinTime"`
 }
 
+var ErrSelfNotFound = errors.New("cant find self in cluster")
+
 const (
 	_type = "instance"
+	// maxPruneBatchSize is the sub-document operation limit of a single mutateIn request.
+	maxPruneBatchSize = 16
 )
 
 func (h *cbMembership) GetInfo() *membership.Model {
-	if h.info != nil {
-		return h.info
+	if info := h.info.Load(); info != nil {
+		return info
 	}
 
 	return <-h.infoChan
 }
 
-func (h *cbMembership) register() {
+func (h *cbMembership) register() error {
 	ctx, cancel := context.WithTimeout(context.Background(), h.membershipConfig.Timeout)
 	defer cancel()
 
@@ -67,8 +91,7 @@ func (h *cbMembership) register() {
 
 	err := h.createIndex(ctx, now)
 	if err != nil {
-		logger.Log.Error("error while create index: %v", err)
-		panic(err)
+		return fmt.Errorf("error while create index: %w", err)
 	}
 
 	h.clusterJoinTime = now
@@ -102,9 +125,10 @@ func (h *cbMembership) register() {
 	}
 
 	if err != nil {
-		logger.Log.Error("error while register: %v", err)
-		panic(err)
+		return fmt.Errorf("error while register: %w", err)
 	}
+
+	return nil
 }
 
 func (h *cbMembership) createIndex(ctx context.Context, clusterJoinTime int64) error {
@@ -141,13 +165,14 @@ func (h *cbMembership) heartbeat() {
 
 	err := UpdateDocument(ctx, h.client.GetMetaAgent(), h.scopeName, h.collectionName, h.id, payload, h.membershipConfig.ExpirySeconds)
 	if err != nil {
-		logger.Log.Error("error while heartbeat: %v", err)
+		h.logger.Error("error while heartbeat: %v", err)
 		return
 	}
 }
 
 func (h *cbMembership) isAlive(heartbeatTime int64) bool {
-	return (time.Now().UnixNano() - heartbeatTime) < heartbeatTime+h.membershipConfig.HeartbeatToleranceDuration.Nanoseconds()
+	maxHeartbeatAge := h.membershipConfig.HeartbeatInterval + h.membershipConfig.HeartbeatToleranceDuration
+	return time.Now().UnixNano()-heartbeatTime < maxHeartbeatAge.Nanoseconds()
 }
 
 //nolint:funlen
@@ -155,9 +180,9 @@ func (h *cbMembership) monitor() {
 	ctx, cancel := context.WithTimeout(context.Background(), h.membershipConfig.Timeout)
 	defer cancel()
 
-	data, err := Get(ctx, h.client.GetMetaAgent(), h.scopeName, h.collectionName, h.instanceAll)
+	data, cas, err := GetWithCas(ctx, h.client.GetMetaAgent(), h.scopeName, h.collectionName, h.instanceAll)
 	if err != nil {
-		logger.Log.Error("error while monitor try to get index: %v", err)
+		h.logger.Error("error while monitor try to get index: %v", err)
 		return
 	}
 
@@ -165,7 +190,18 @@ func (h *cbMembership) monitor() {
 
 	err = jsoniter.Unmarshal(data, &all)
 	if err != nil {
-		logger.Log.Error("error while monitor try to unmarshal index: %v", err)
+		h.logger.Error("error while monitor try to unmarshal index: %v", err)
+		return
+	}
+
+	if _, ok := all[string(h.id)]; !ok {
+		h.logger.Warn("instance %v is not in index, registering again", string(h.id))
+
+		err = h.createIndex(ctx, h.clusterJoinTime)
+		if err != nil {
+			h.logger.Error("error while monitor try to register index again: %v", err)
+		}
+
 		return
 	}
 
@@ -179,6 +215,8 @@ func (h *cbMembership) monitor() {
 	})
 
 	instances := make([]*Instance, len(ids))
+	stale := make([]bool, len(ids))
+	failed := make([]bool, len(ids))
 
 	var wg sync.WaitGroup
 	for i, id := range ids {
@@ -189,11 +227,14 @@ func (h *cbMembership) monitor() {
 			var kvErr *gocbcore.KeyValueError
 			if err != nil {
 				if errors.As(err, &kvErr) && kvErr.StatusCode == memd.StatusKeyNotFound {
+					// the instance document may not be written yet right after registration
+					stale[i] = !h.isAlive(all[id])
 					return
-				} else {
-					logger.Log.Error("error while monitor try to get instance: %v", err)
-					panic(err)
 				}
+
+				h.logger.Error("error while monitor try to get instance: %v", err)
+				failed[i] = true
+				return
 			}
 
 			copyID := id
@@ -201,49 +242,145 @@ func (h *cbMembership) monitor() {
 			err = jsoniter.Unmarshal(doc, instance)
 
 			if err != nil {
-				logger.Log.Error("error while monitor try to unmarshal instance %v, err: %v", string(doc), err)
-				panic(err)
+				h.logger.Error("error while monitor try to unmarshal instance %v, err: %v", string(doc), err)
+				failed[i] = true
+				return
 			}
 
 			if h.isAlive(instance.HeartbeatTime) {
 				instances[i] = instance
 			} else {
-				logger.Log.Info("instance %v is not alive", instance.ID)
+				stale[i] = true
+				h.logger.Info("instance %v is not alive", *instance.ID)
 			}
 		}(i, id)
 	}
 	wg.Wait()
 
+	for i := range failed {
+		if failed[i] {
+			// an incomplete view of the group could cause a wrong rebalance, retry on next monitor
+			return
+		}
+	}
+
 	var filteredInstances []Instance
-	for _, instance := range instances {
+	var staleIDs []string
+	for i, instance := range instances {
 		if instance != nil {
 			filteredInstances = append(filteredInstances, *instance)
+		} else if stale[i] {
+			staleIDs = append(staleIDs, ids[i])
 		}
 	}
 
+	if !h.containsSelf(filteredInstances) {
+		h.logger.Warn("instance %v heartbeat is late, skipping monitor", string(h.id))
+		return
+	}
+
+	if h.isLeader(filteredInstances) && len(staleIDs) > 0 {
+		h.pruneIndex(ctx, staleIDs, cas)
+	}
+
 	if h.isClusterChanged(filteredInstances) {
-		h.rebalance(filteredInstances)
-		h.updateIndex(ctx)
+		epoch, err := h.epochStore.Next(ctx, instanceIDs(filteredInstances))
+		if err != nil {
+			// the assignment is not published without its fencing token, retry on next monitor
+			h.logger.Error("error while monitor try to get epoch: %v", err)
+			return
+		}
+
+		h.updateChurnMetric(filteredInstances)
+		h.rebalance(filteredInstances, epoch)
+
+		return
 	}
+
+	h.refreshEpoch(ctx, filteredInstances)
 }
 
-func (h *cbMembership) updateIndex(ctx context.Context) {
-	all := map[string]int64{}
+// refreshEpoch takes a newer epoch of the same assignment. It is stored by a member which saw another assignment
+// for a while, checkpoints written by that member would fence this one until it streams with the newer epoch.
+func (h *cbMembership) refreshEpoch(ctx context.Context, instances []Instance) {
+	epoch, members, err := h.epochStore.Current(ctx)
+	if err != nil {
+		h.logger.Error("error while monitor try to get current epoch: %v", err)
+		return
+	}
 
-	for _, instance := range h.lastActiveInstances {
-		all[*instance.ID] = instance.ClusterJoinTime
+	current := h.epoch.Load()
+	if epoch <= current || !slices.Equal(members, instanceIDs(instances)) {
+		return
+	}
+
+	h.logger.Info("epoch of the same assignment moved from %v to %v", current, epoch)
+	h.rebalance(instances, epoch)
+}
+
+func instanceIDs(instances []Instance) []string {
+	ids := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		ids = append(ids, *instance.ID)
+	}
+
+	return ids
+}
+
+func (h *cbMembership) containsSelf(instances []Instance) bool {
+	for _, instance := range instances {
+		if *instance.ID == string(h.id) {
+			return true
+		}
 	}
 
-	payload, _ := jsoniter.Marshal(all)
+	return false
+}
 
-	err := UpdateDocument(ctx, h.client.GetMetaAgent(), h.scopeName, h.collectionName, h.instanceAll, payload, 0)
+// isLeader reports whether this instance is the oldest alive member, which is the one responsible for index cleanup.
+func (h *cbMembership) isLeader(instances []Instance) bool {
+	return len(instances) > 0 && *instances[0].ID == string(h.id)
+}
+
+func (h *cbMembership) pruneIndex(ctx context.Context, staleIDs []string, cas gocbcore.Cas) {
+	if len(staleIDs) > maxPruneBatchSize {
+		staleIDs = staleIDs[:maxPruneBatchSize]
+	}
+
+	err := RemovePaths(ctx, h.client.GetMetaAgent(), h.scopeName, h.collectionName, h.instanceAll, staleIDs, cas)
 	if err != nil {
-		logger.Log.Error("error while update instances: %v", err)
+		if errors.Is(err, gocbcore.ErrCasMismatch) || errors.Is(err, gocbcore.ErrDocumentExists) {
+			h.metric.PruneConflict.Add(1)
+			h.logger.Debug("index changed while pruning, will retry on next monitor")
+		} else {
+			h.logger.Error("error while prune index: %v", err)
+		}
+
 		return
 	}
+
+	h.metric.Pruned.Add(int64(len(staleIDs)))
+	h.logger.Info("pruned stale instances from index: %v", staleIDs)
+}
+
+func (h *cbMembership) updateChurnMetric(currentActiveInstances []Instance) {
+	last := make(map[string]struct{}, len(h.lastActiveInstances))
+	for _, instance := range h.lastActiveInstances {
+		last[*instance.ID] = struct{}{}
+	}
+
+	for _, instance := range currentActiveInstances {
+		if _, ok := last[*instance.ID]; ok {
+			delete(last, *instance.ID)
+		} else {
+			h.metric.Joined.Add(1)
+		}
+	}
+
+	h.metric.Left.Add(int64(len(last)))
 }
 
-func (h *cbMembership) rebalance(instances []Instance) {
+func (h *cbMembership) rebalance(instances []Instance, epoch uint64) {
 	selfOrder := 0
 
 	for index, instance := range instances {
@@ -254,17 +391,21 @@ func (h *cbMembership) rebalance(instances []Instance) {
 	}
 
 	if selfOrder == 0 {
-		err := errors.New("cant find self in cluster")
-		logger.Log.Error("error while rebalance, self = %v, err: %v", string(h.id), err)
-		panic(err)
-	} else {
-		h.bus.Publish(helpers.MembershipChangedBusEventName, &membership.Model{
-			MemberNumber: selfOrder,
-			TotalMembers: len(instances),
-		})
-
-		h.lastActiveInstances = instances
+		h.logger.Error("error while rebalance, self = %v, err: %v", string(h.id), ErrSelfNotFound)
+		return
 	}
+
+	h.bus.Publish(helpers.MembershipChangedBusEventName, &membership.Model{
+		MemberNumber: selfOrder,
+		TotalMembers: len(instances),
+		Epoch:        epoch,
+	})
+
+	h.epoch.Store(epoch)
+
+	h.instanceLock.Lock()
+	h.lastActiveInstances = instances
+	h.instanceLock.Unlock()
 }
 
 func (h *cbMembership) startHeartbeat() {
@@ -281,7 +422,7 @@ func (h *cbMembership) startMonitor() {
 	h.monitorTicker = time.NewTicker(h.membershipConfig.MonitorInterval)
 
 	go func() {
-		logger.Log.Info("couchbase membership will start after %v", h.config.Dcp.Group.Membership.RebalanceDelay)
+		h.logger.Info("couchbase membership will start after %v", h.config.Dcp.Group.Membership.RebalanceDelay)
 		time.Sleep(h.config.Dcp.Group.Membership.RebalanceDelay)
 
 		for range h.monitorTicker.C {
@@ -293,30 +434,68 @@ func (h *cbMembership) startMonitor() {
 func (h *cbMembership) Close() {
 	err := h.bus.Unsubscribe(helpers.MembershipChangedBusEventName, h.membershipChangedListener)
 	if err != nil {
-		logger.Log.Error("error while unsubscribe: %v", err)
+		h.logger.Error("error while unsubscribe: %v", err)
 	}
 
 	h.monitorTicker.Stop()
 	h.heartbeatTicker.Stop()
 }
 
+func (h *cbMembership) GetMembers() []membership.Member {
+	h.instanceLock.RLock()
+	instances := h.lastActiveInstances
+	h.instanceLock.RUnlock()
+
+	members := make([]membership.Member, 0, len(instances))
+
+	for index, instance := range instances {
+		members = append(members, membership.Member{
+			Name:            *instance.ID,
+			ClusterJoinTime: instance.ClusterJoinTime,
+			LastHeartbeat:   instance.HeartbeatTime,
+			MemberNumber:    index + 1,
+			TotalMembers:    len(instances),
+			Self:            *instance.ID == string(h.id),
+			Leader:          index == 0,
+		})
+	}
+
+	return members
+}
+
+func (h *cbMembership) GetMetric() *MembershipMetric {
+	return h.metric
+}
+
 func (h *cbMembership) membershipChangedListener(model *membership.Model) {
-	h.info = model
+	h.info.Store(model)
 	go func() {
 		h.infoChan <- model
 	}()
 }
 
-func NewCBMembership(config *config.Dcp, client Client, bus EventBus.Bus) membership.Membership {
+func NewCBMembership(config *config.Dcp, client Client, bus EventBus.Bus, logger logger.Logger) (membership.Membership, error) { //nolint:lll
 	if !config.IsCouchbaseMetadata() {
-		err := errors.New("unsupported metadata type")
-		logger.Log.Error("cannot initialize couchbase membership, err: %v", err)
-		panic(err)
+		return nil, fmt.Errorf("cannot initialize couchbase membership: %w", metadata.ErrUnsupportedMetadataType)
 	}
 
-	couchbaseMetadataConfig := config.GetCouchbaseMetadata()
+	couchbaseMetadataConfig, err := config.GetCouchbaseMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	membershipConfig, err := config.GetCouchbaseMembership()
+	if err != nil {
+		return nil, err
+	}
+
+	epochStore, err := NewCBEpochStore(client, config)
+	if err != nil {
+		return nil, err
+	}
 
 	cbm := &cbMembership{
+		epochStore:       epochStore,
 		infoChan:         make(chan *membership.Model),
 		client:           client,
 		id:               []byte(helpers.Prefix + config.Dcp.Group.Name + ":" + _type + ":" + uuid.New().String()),
@@ -324,20 +503,23 @@ func NewCBMembership(config *config.Dcp, client Client, bus EventBus.Bus) member
 		bus:              bus,
 		scopeName:        couchbaseMetadataConfig.Scope,
 		collectionName:   couchbaseMetadataConfig.Collection,
-		membershipConfig: config.GetCouchbaseMembership(),
+		membershipConfig: membershipConfig,
 		config:           config,
+		metric:           &MembershipMetric{},
+		logger:           logger,
 	}
 
-	cbm.register()
-
-	cbm.startHeartbeat()
-	cbm.startMonitor()
+	if err := cbm.register(); err != nil {
+		return nil, err
+	}
 
-	err := bus.SubscribeAsync(helpers.MembershipChangedBusEventName, cbm.membershipChangedListener, true)
+	err = bus.SubscribeAsync(helpers.MembershipChangedBusEventName, cbm.membershipChangedListener, true)
 	if err != nil {
-		logger.Log.Error("error while subscribe membership changed event: %v", err)
-		panic(err)
+		return nil, fmt.Errorf("error while subscribe membership changed event: %w", err)
 	}
 
-	return cbm
+	cbm.startHeartbeat()
+	cbm.startMonitor()
+
+	return cbm, nil
 }
@@ -0,0 +1,125 @@
+package couchbase
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/logger"
+
+	"github.com/asaskevich/EventBus"
+)
+
+func newTestMembership(id string) *cbMembership {
+	return &cbMembership{
+		id: []byte(id),
+		membershipConfig: &config.CouchbaseMembership{
+			HeartbeatInterval:          5 * time.Second,
+			HeartbeatToleranceDuration: 2 * time.Second,
+		},
+		metric: &MembershipMetric{},
+	}
+}
+
+func newTestInstances(ids ...string) []Instance {
+	instances := make([]Instance, 0, len(ids))
+	for i := range ids {
+		instances = append(instances, Instance{ID: &ids[i]})
+	}
+	return instances
+}
+
+func TestIsAlive(t *testing.T) {
+	h := newTestMembership("self")
+
+	if !h.isAlive(time.Now().Add(-6 * time.Second).UnixNano()) {
+		t.Errorf("Expected instance within heartbeat interval and tolerance to be alive")
+	}
+
+	if h.isAlive(time.Now().Add(-8 * time.Second).UnixNano()) {
+		t.Errorf("Expected instance beyond heartbeat interval and tolerance to be dead")
+	}
+
+	if h.isAlive(time.Now().Add(-time.Hour).UnixNano()) {
+		t.Errorf("Expected crashed instance to be dead")
+	}
+}
+
+func TestIsLeader(t *testing.T) {
+	h := newTestMembership("b")
+
+	if h.isLeader(newTestInstances("a", "b")) {
+		t.Errorf("Expected non oldest instance not to be leader")
+	}
+
+	if !h.isLeader(newTestInstances("b", "c")) {
+		t.Errorf("Expected oldest instance to be leader")
+	}
+
+	if h.isLeader(nil) {
+		t.Errorf("Expected no leader for empty instances")
+	}
+}
+
+func TestUpdateChurnMetric(t *testing.T) {
+	h := newTestMembership("a")
+	h.lastActiveInstances = newTestInstances("a", "b", "c")
+
+	h.updateChurnMetric(newTestInstances("a", "c", "d", "e"))
+
+	if h.metric.Joined.Load() != 2 {
+		t.Errorf("Unexpected joined count. Expected: 2, Got: %d", h.metric.Joined.Load())
+	}
+
+	if h.metric.Left.Load() != 1 {
+		t.Errorf("Unexpected left count. Expected: 1, Got: %d", h.metric.Left.Load())
+	}
+}
+
+type testEpochStore struct {
+	members []string
+	epoch   uint64
+}
+
+func (s *testEpochStore) Next(_ context.Context, members []string) (uint64, error) {
+	if !slices.Equal(s.members, members) {
+		s.members, s.epoch = members, s.epoch+1
+	}
+
+	return s.epoch, nil
+}
+
+func (s *testEpochStore) Current(_ context.Context) (uint64, []string, error) {
+	return s.epoch, s.members, nil
+}
+
+func TestRefreshEpoch(t *testing.T) {
+	tests := []struct {
+		name     string
+		members  []string
+		stored   uint64
+		expected uint64
+	}{
+		{name: "newer epoch of same assignment", members: []string{"a", "b"}, stored: 7, expected: 7},
+		{name: "newer epoch of another assignment", members: []string{"a", "c"}, stored: 7, expected: 5},
+		{name: "same epoch", members: []string{"a", "b"}, stored: 5, expected: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestMembership("a")
+			h.bus = EventBus.New()
+			h.logger = logger.Log
+			h.epoch.Store(5)
+			h.epochStore = &testEpochStore{members: tt.members, epoch: tt.stored}
+
+			h.refreshEpoch(context.Background(), newTestInstances("a", "b"))
+
+			if epoch := h.epoch.Load(); epoch != tt.expected {
+				t.Errorf("Unexpected result. Expected: %v, Got: %v", tt.expected, epoch)
+			}
+		})
+	}
+}
@@ -3,9 +3,9 @@ package couchbase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
-	"sync"
 
 	"github.com/couchbase/gocbcore/v10"
 
@@ -16,7 +16,6 @@ import (
 	"github.com/Trendyol/go-dcp/config"
 
 	"github.com/Trendyol/go-dcp/helpers"
-	"github.com/Trendyol/go-dcp/logger"
 	"github.com/Trendyol/go-dcp/metadata"
 	"github.com/Trendyol/go-dcp/models"
 
@@ -32,8 +31,8 @@ type cbMetadata struct {
 	collectionName string
 }
 
-func (s *cbMetadata) Save(state map[uint16]*models.CheckpointDocument, dirtyOffsets map[uint16]bool, _ string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), s.config.Checkpoint.Timeout)
+func (s *cbMetadata) Save(ctx context.Context, state map[uint16]*models.CheckpointDocument, dirtyOffsets map[uint16]bool, _ string) error { //nolint:lll
+	ctx, cancel := context.WithTimeout(ctx, s.config.Checkpoint.Timeout)
 	defer cancel()
 
 	eg, _ := errgroup.WithContext(ctx)
@@ -48,9 +47,18 @@ func (s *cbMetadata) Save(state map[uint16]*models.CheckpointDocument, dirtyOffs
 
 func (s *cbMetadata) saveVBucketCheckpoint(ctx context.Context, vbID uint16, checkpointDocument *models.CheckpointDocument) func() error {
 	return func() error {
-		id := getCheckpointID(vbID, s.config.Dcp.Group.Name)
+		id, err := getCheckpointID(vbID, s.config.GetCheckpointName())
+		if err != nil {
+			return err
+		}
+
 		payload, _ := jsoniter.Marshal(checkpointDocument)
-		err := UpsertXattrs(ctx, s.client.GetMetaAgent(), s.scopeName, s.collectionName, id, helpers.Name, payload, 0)
+
+		if checkpointDocument.FencingToken != 0 {
+			return s.saveFencedVBucketCheckpoint(ctx, vbID, id, checkpointDocument.FencingToken, payload)
+		}
+
+		err = UpsertXattrs(ctx, s.client.GetMetaAgent(), s.scopeName, s.collectionName, id, helpers.Name, payload, 0)
 
 		var kvErr *gocbcore.KeyValueError
 		if err != nil && errors.As(err, &kvErr) && kvErr.StatusCode == memd.StatusKeyNotFound {
@@ -64,24 +72,78 @@ func (s *cbMetadata) saveVBucketCheckpoint(ctx context.Context, vbID uint16, che
 	}
 }
 
+// saveFencedVBucketCheckpoint writes the checkpoint only if the stored one is not written with a newer fencing token.
+// When another member writes the checkpoint between the read and the write, it is read again and the tokens are compared.
+func (s *cbMetadata) saveFencedVBucketCheckpoint(ctx context.Context, vbID uint16, id []byte, fencingToken uint64, payload []byte) error { //nolint:lll
+	for {
+		data, cas, err := GetXattrsWithCas(ctx, s.client.GetMetaAgent(), s.scopeName, s.collectionName, id, helpers.Name)
+
+		var kvErr *gocbcore.KeyValueError
+		if err != nil && errors.As(err, &kvErr) && kvErr.StatusCode == memd.StatusKeyNotFound {
+			// inserted instead of upserted, so a checkpoint created by another member in between is not overwritten
+			err = InsertDocument(ctx, s.client.GetMetaAgent(), s.scopeName, s.collectionName, id, []byte{}, helpers.JSONFlags, 0)
+			if err != nil && !errors.Is(err, gocbcore.ErrDocumentExists) {
+				return err
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := checkFencingToken(vbID, fencingToken, data); err != nil {
+			return err
+		}
+
+		err = UpsertXattrsWithCas(ctx, s.client.GetMetaAgent(), s.scopeName, s.collectionName, id, helpers.Name, payload, 0, cas)
+		if !errors.Is(err, gocbcore.ErrCasMismatch) && !errors.Is(err, gocbcore.ErrDocumentExists) {
+			return err
+		}
+	}
+}
+
+// checkFencingToken rejects the write when the stored checkpoint is written by a member with a newer fencing token.
+// A stored checkpoint which cannot be read rejects the write as well, its token is not known.
+func checkFencingToken(vbID uint16, fencingToken uint64, storedCheckpoint []byte) error {
+	if len(storedCheckpoint) == 0 {
+		return nil
+	}
+
+	var stored models.CheckpointDocument
+	if err := jsoniter.Unmarshal(storedCheckpoint, &stored); err != nil {
+		return fmt.Errorf("cannot read fencing token of stored checkpoint, vbID: %d: %w", vbID, err)
+	}
+
+	if stored.FencingToken > fencingToken {
+		return fmt.Errorf("%w, vbID: %d, token: %d, stored token: %d", metadata.ErrStaleFencingToken, vbID, fencingToken, stored.FencingToken)
+	}
+
+	return nil
+}
+
 func (s *cbMetadata) Load(
+	ctx context.Context,
 	vbIds []uint16,
 	bucketUUID string,
 ) (*wrapper.ConcurrentSwissMap[uint16, *models.CheckpointDocument], bool, error) {
 	state := wrapper.CreateConcurrentSwissMap[uint16, *models.CheckpointDocument](1024)
 
-	wg := &sync.WaitGroup{}
-	wg.Add(len(vbIds))
+	eg, ctx := errgroup.WithContext(ctx)
 
 	exist := false
 
 	for _, vbID := range vbIds {
-		go func(vbID uint16) {
-			var err error
+		vbID := vbID
 
-			id := getCheckpointID(vbID, s.config.Dcp.Group.Name)
+		eg.Go(func() error {
+			id, err := getCheckpointID(vbID, s.config.GetCheckpointName())
+			if err != nil {
+				return err
+			}
 
-			data, err := GetXattrs(context.Background(), s.client.GetMetaAgent(), s.scopeName, s.collectionName, id, helpers.Name)
+			data, err := GetXattrs(ctx, s.client.GetMetaAgent(), s.scopeName, s.collectionName, id, helpers.Name)
 
 			var doc *models.CheckpointDocument
 
@@ -100,28 +162,31 @@ func (s *cbMetadata) Load(
 			var kvErr *gocbcore.KeyValueError
 			if err == nil || errors.As(err, &kvErr) && kvErr.StatusCode == memd.StatusKeyNotFound {
 				state.Store(vbID, doc)
-			} else {
-				logger.Log.Error("cannot load checkpoint, vbID: %d, err: %v", vbID, err)
-				panic(err)
+				return nil
 			}
 
-			wg.Done()
-		}(vbID)
+			return fmt.Errorf("cannot load checkpoint, vbID: %d, err: %w", vbID, err)
+		})
 	}
 
-	wg.Wait()
+	if err := eg.Wait(); err != nil {
+		return nil, false, err
+	}
 
 	return state, exist, nil
 }
 
-func (s *cbMetadata) Clear(vbIds []uint16) error {
-	ctx, cancel := context.WithTimeout(context.Background(), s.config.Checkpoint.Timeout)
+func (s *cbMetadata) Clear(ctx context.Context, vbIds []uint16) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Checkpoint.Timeout)
 	defer cancel()
 
 	for _, vbID := range vbIds {
-		id := getCheckpointID(vbID, s.config.Dcp.Group.Name)
+		id, err := getCheckpointID(vbID, s.config.GetCheckpointName())
+		if err != nil {
+			return err
+		}
 
-		err := DeleteDocument(ctx, s.client.GetMetaAgent(), s.scopeName, s.collectionName, id)
+		err = DeleteDocument(ctx, s.client.GetMetaAgent(), s.scopeName, s.collectionName, id)
 		if err != nil {
 			return err
 		}
@@ -130,27 +195,34 @@ func (s *cbMetadata) Clear(vbIds []uint16) error {
 	return nil
 }
 
-func NewCBMetadata(client Client, config *config.Dcp) metadata.Metadata {
+func NewCBMetadata(client Client, config *config.Dcp) (metadata.Metadata, error) {
 	if !config.IsCouchbaseMetadata() {
-		err := errors.New("unsupported metadata type")
-		logger.Log.Error("cannot initialize couchbase metadata: %v", err)
-		panic(err)
+		return nil, fmt.Errorf("cannot initialize couchbase metadata: %w", metadata.ErrUnsupportedMetadataType)
 	}
 
-	couchbaseMetadataConfig := config.GetCouchbaseMetadata()
+	if strings.Contains(config.Dcp.Group.Name, ".") {
+		return nil, ErrUnsupportedGroupName
+	}
+
+	couchbaseMetadataConfig, err := config.GetCouchbaseMetadata()
+	if err != nil {
+		return nil, err
+	}
 
 	return &cbMetadata{
 		client:         client,
 		config:         config,
 		scopeName:      couchbaseMetadataConfig.Scope,
 		collectionName: couchbaseMetadataConfig.Collection,
-	}
+	}, nil
 }
 
-func getCheckpointID(vbID uint16, groupName string) []byte {
+var ErrUnsupportedGroupName = errors.New("unsupported group name includes dot")
+
+func getCheckpointID(vbID uint16, groupName string) ([]byte, error) {
 	// _connector:cbgo:groupName:stdout-listener:checkpoint:vbId
 	if strings.Contains(groupName, ".") {
-		panic("can not get checkpoint id. unsupported group name includes dot")
+		return nil, ErrUnsupportedGroupName
 	}
-	return []byte(helpers.Prefix + groupName + ":checkpoint:" + strconv.Itoa(int(vbID)))
+	return []byte(helpers.Prefix + groupName + ":checkpoint:" + strconv.Itoa(int(vbID))), nil
 }
@@ -2,23 +2,63 @@ package couchbase
 
 import (
 	"bytes"
+	"errors"
 	"testing"
+
+	"github.com/Trendyol/go-dcp/metadata"
 )
 
 func TestGetCheckpointID(t *testing.T) {
 	expected := []byte("_connector:cbgo:group1:checkpoint:1")
-	actual := getCheckpointID(uint16(1), "group1")
+	actual, err := getCheckpointID(uint16(1), "group1")
+	if err != nil {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", nil, err)
+	}
+
 	if !bytes.Equal(actual, expected) {
 		t.Errorf("Unexpected result. Expected: %s, Got: %s", expected, actual)
 	}
 }
 
 func TestGetCheckpointIDWithInvalidGroupName(t *testing.T) {
-	defer func() {
-		if r := recover(); r == nil {
-			t.Errorf("Expected panic but did not occur")
-		}
-	}()
+	if _, err := getCheckpointID(uint16(1), "group.with.dot"); !errors.Is(err, ErrUnsupportedGroupName) {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", ErrUnsupportedGroupName, err)
+	}
+}
+
+func TestCheckFencingToken(t *testing.T) {
+	stored := []byte(`{"checkpoint":{"vbuuid":1,"seqNo":10},"bucketUuid":"uuid","fencingToken":5}`)
 
-	getCheckpointID(uint16(1), "group.with.dot")
+	if err := checkFencingToken(1, 5, stored); err != nil {
+		t.Errorf("Unexpected error for same token. Got: %v", err)
+	}
+
+	if err := checkFencingToken(1, 6, stored); err != nil {
+		t.Errorf("Unexpected error for newer token. Got: %v", err)
+	}
+
+	if err := checkFencingToken(1, 4, stored); !errors.Is(err, metadata.ErrStaleFencingToken) {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", metadata.ErrStaleFencingToken, err)
+	}
+}
+
+func TestCheckFencingTokenWithoutStoredToken(t *testing.T) {
+	if err := checkFencingToken(1, 4, []byte(`{"checkpoint":{"seqNo":10},"bucketUuid":"uuid"}`)); err != nil {
+		t.Errorf("Unexpected error for checkpoint without token. Got: %v", err)
+	}
+
+	if err := checkFencingToken(1, 4, nil); err != nil {
+		t.Errorf("Unexpected error for missing checkpoint. Got: %v", err)
+	}
+}
+
+func TestCheckFencingTokenWithCorruptCheckpoint(t *testing.T) {
+	err := checkFencingToken(1, 4, []byte(`{"checkpoint":`))
+	if err == nil || errors.Is(err, metadata.ErrStaleFencingToken) {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "error of stored checkpoint", err)
+	}
+
+	if err := checkFencingToken(1, 4, []byte(`{"fencingToken":"5"}`)); err == nil {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "error of foreign checkpoint", err)
+	}
 }
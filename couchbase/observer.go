@@ -1,6 +1,8 @@
 package couchbase
 
 import (
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/asaskevich/EventBus"
@@ -34,6 +36,7 @@ type Observer interface {
 	GetMetrics() *wrapper.ConcurrentSwissMap[uint16, *ObserverMetric]
 	GetPersistSeqNo() *wrapper.ConcurrentSwissMap[uint16, gocbcore.SeqNo]
 	Listen() models.ListenerCh
+	Stop()
 	Close()
 	CloseEnd()
 	ListenEnd() models.ListenerEndCh
@@ -62,8 +65,10 @@ func (om *ObserverMetric) AddExpiration() {
 }
 
 type observer struct {
+	logger                 logger.Logger
 	bus                    EventBus.Bus
 	metrics                *wrapper.ConcurrentSwissMap[uint16, *ObserverMetric]
+	collectionMetrics      *CollectionMetrics
 	listenerEndCh          models.ListenerEndCh
 	collectionIDs          map[uint32]string
 	catchup                *wrapper.ConcurrentSwissMap[uint16, uint64]
@@ -73,7 +78,7 @@ type observer struct {
 	uuIDMap                *wrapper.ConcurrentSwissMap[uint16, gocbcore.VbUUID]
 	config                 *dcp.Dcp
 	catchupNeededVbIDCount int
-	closed                 bool
+	closed                 atomic.Bool
 }
 
 func (so *observer) AddCatchup(vbID uint16, seqNo gocbcore.SeqNo) {
@@ -89,14 +94,14 @@ func (so *observer) persistSeqNoChangedListener(persistSeqNo models.PersistSeqNo
 			so.persistSeqNo.Store(persistSeqNo.VbID, persistSeqNo.SeqNo)
 		}
 	} else {
-		logger.Log.Debug("persistSeqNo: %v on vbId: %v", persistSeqNo.SeqNo, persistSeqNo.VbID)
+		so.logger.With("vbId", persistSeqNo.VbID, "seqNo", persistSeqNo.SeqNo).Debug("zero persistSeqNo received")
 	}
 }
 
 func (so *observer) checkPersistSeqNo(vbID uint16, seqNo uint64) bool {
 	endSeqNo, ok := so.persistSeqNo.Load(vbID)
 
-	return (ok && gocbcore.SeqNo(seqNo) <= endSeqNo) || so.closed
+	return (ok && gocbcore.SeqNo(seqNo) <= endSeqNo) || so.closed.Load()
 }
 
 func (so *observer) needCatchup(vbID uint16, seqNo uint64) bool {
@@ -109,7 +114,7 @@ func (so *observer) needCatchup(vbID uint16, seqNo uint64) bool {
 			so.catchup.Delete(vbID)
 			so.catchupNeededVbIDCount--
 
-			logger.Log.Info("catchup completed for vbID: %d, remaining catchup: %d", vbID, so.catchupNeededVbIDCount)
+			so.logger.With("vbId", vbID, "seqNo", seqNo).Info("catchup completed, remaining catchup: %d", so.catchupNeededVbIDCount)
 
 			return seqNo == catchupSeqNo
 		}
@@ -154,6 +159,12 @@ func (so *observer) sendOrSkip(args models.ListenerArgs) {
 		}
 	}()
 
+	if so.closed.Load() {
+		return
+	}
+
+	args.ReceivedAt = time.Now()
+
 	so.listenerCh <- args
 }
 
@@ -185,11 +196,16 @@ func (so *observer) Mutation(mutation gocbcore.DcpMutation) { //nolint:dupl
 					SeqNo:          mutation.SeqNo,
 				},
 				CollectionName: so.convertToCollectionName(mutation.CollectionID),
+				BucketName:     so.config.BucketName,
 				EventTime:      time.Unix(int64(mutation.Cas/1000000000), 0),
 			},
 		})
 	}
 
+	so.collectionMetrics.Add(
+		so.convertToCollectionName(mutation.CollectionID), MutationEventType, len(mutation.Key)+len(mutation.Value),
+	)
+
 	if metric, ok := so.metrics.Load(mutation.VbID); ok {
 		metric.AddMutation()
 	} else {
@@ -216,11 +232,16 @@ func (so *observer) Deletion(deletion gocbcore.DcpDeletion) { //nolint:dupl
 					SeqNo:          deletion.SeqNo,
 				},
 				CollectionName: so.convertToCollectionName(deletion.CollectionID),
+				BucketName:     so.config.BucketName,
 				EventTime:      time.Unix(int64(deletion.Cas/1000000000), 0),
 			},
 		})
 	}
 
+	so.collectionMetrics.Add(
+		so.convertToCollectionName(deletion.CollectionID), DeletionEventType, len(deletion.Key)+len(deletion.Value),
+	)
+
 	if metric, ok := so.metrics.Load(deletion.VbID); ok {
 		metric.AddDeletion()
 	} else {
@@ -247,11 +268,14 @@ func (so *observer) Expiration(expiration gocbcore.DcpExpiration) { //nolint:dup
 					SeqNo:          expiration.SeqNo,
 				},
 				CollectionName: so.convertToCollectionName(expiration.CollectionID),
+				BucketName:     so.config.BucketName,
 				EventTime:      time.Unix(int64(expiration.Cas/1000000000), 0),
 			},
 		})
 	}
 
+	so.collectionMetrics.Add(so.convertToCollectionName(expiration.CollectionID), ExpirationEventType, len(expiration.Key))
+
 	if metric, ok := so.metrics.Load(expiration.VbID); ok {
 		metric.AddExpiration()
 	} else {
@@ -383,6 +407,11 @@ func (so *observer) ListenEnd() models.ListenerEndCh {
 	return so.listenerEndCh
 }
 
+// Stop skips the events received after it, buffered events are still delivered to the listener until Close.
+func (so *observer) Stop() {
+	so.closed.Store(true)
+}
+
 // nolint:staticcheck
 func (so *observer) Close() {
 	defer func() {
@@ -391,14 +420,14 @@ func (so *observer) Close() {
 		}
 	}()
 
-	logger.Log.Debug("observer closing")
+	so.logger.Debug("observer closing")
 
 	err := so.bus.Unsubscribe(helpers.PersistSeqNoChangedBusEventName, so.persistSeqNoChangedListener)
 	if err != nil {
-		logger.Log.Error("error while unsubscribe: %v", err)
+		so.logger.Error("error while unsubscribe: %v", err)
 	}
 
-	so.closed = true
+	so.closed.Store(true)
 	close(so.listenerCh)
 
 	// to drain buffered channel
@@ -406,7 +435,7 @@ func (so *observer) Close() {
 	close(closedListenerCh)
 	so.listenerCh = closedListenerCh
 
-	logger.Log.Debug("observer closed")
+	so.logger.Debug("observer closed")
 }
 
 func (so *observer) SetVbUUID(vbID uint16, vbUUID gocbcore.VbUUID) {
@@ -427,26 +456,29 @@ func (so *observer) CloseEnd() {
 func NewObserver(
 	config *dcp.Dcp,
 	collectionIDs map[uint32]string,
+	collectionMetrics *CollectionMetrics,
 	bus EventBus.Bus,
-) Observer {
+	logger logger.Logger,
+) (Observer, error) {
 	observer := &observer{
-		currentSnapshots: wrapper.CreateConcurrentSwissMap[uint16, *models.SnapshotMarker](1024),
-		uuIDMap:          wrapper.CreateConcurrentSwissMap[uint16, gocbcore.VbUUID](100),
-		metrics:          wrapper.CreateConcurrentSwissMap[uint16, *ObserverMetric](100),
-		catchup:          wrapper.CreateConcurrentSwissMap[uint16, uint64](100),
-		collectionIDs:    collectionIDs,
-		listenerCh:       make(models.ListenerCh, config.Dcp.Listener.BufferSize),
-		listenerEndCh:    make(models.ListenerEndCh, 1),
-		bus:              bus,
-		persistSeqNo:     wrapper.CreateConcurrentSwissMap[uint16, gocbcore.SeqNo](100),
-		config:           config,
+		currentSnapshots:  wrapper.CreateConcurrentSwissMap[uint16, *models.SnapshotMarker](1024),
+		uuIDMap:           wrapper.CreateConcurrentSwissMap[uint16, gocbcore.VbUUID](100),
+		metrics:           wrapper.CreateConcurrentSwissMap[uint16, *ObserverMetric](100),
+		collectionMetrics: collectionMetrics,
+		catchup:           wrapper.CreateConcurrentSwissMap[uint16, uint64](100),
+		collectionIDs:     collectionIDs,
+		listenerCh:        make(models.ListenerCh, config.Dcp.Listener.GetBufferSize()),
+		listenerEndCh:     make(models.ListenerEndCh, 1),
+		bus:               bus,
+		persistSeqNo:      wrapper.CreateConcurrentSwissMap[uint16, gocbcore.SeqNo](100),
+		config:            config,
+		logger:            logger,
 	}
 
 	err := observer.bus.Subscribe(helpers.PersistSeqNoChangedBusEventName, observer.persistSeqNoChangedListener)
 	if err != nil {
-		logger.Log.Error("cannot subscribe to persistSeqNo changed event: %v", err)
-		panic(err)
+		return nil, fmt.Errorf("cannot subscribe to persistSeqNo changed event: %w", err)
 	}
 
-	return observer
+	return observer, nil
 }
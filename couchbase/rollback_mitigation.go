@@ -23,7 +23,7 @@ import (
 )
 
 type RollbackMitigation interface {
-	Start()
+	Start(ctx context.Context) error
 	Stop()
 }
 
@@ -50,6 +50,8 @@ func (v *vbUUIDAndSeqNo) SetVbUUID(vbUUID gocbcore.VbUUID) {
 }
 
 type rollbackMitigation struct {
+	logger             logger.Logger
+	ctx                context.Context
 	client             Client
 	config             *config.Dcp
 	bus                EventBus.Bus
@@ -79,11 +81,22 @@ func (r *rollbackMitigation) configWatch() {
 	snapshot, err := r.client.GetConfigSnapshot()
 
 	if err == nil && (r.configSnapshot == nil || r.isConfigSnapshotNewerThan(snapshot)) {
+		previousSnapshot := r.configSnapshot
 		r.configSnapshot = snapshot
-		r.reconfigure()
+
+		if err := r.reconfigure(); err != nil {
+			// the snapshot is taken again on the next watch, so the reconfiguration is retried
+			r.configSnapshot = previousSnapshot
+			r.publishError(err)
+		}
 	}
 }
 
+func (r *rollbackMitigation) publishError(err error) {
+	r.logger.Error("rollback mitigation failed: %v", err)
+	r.bus.Publish(helpers.ErrorBusEventName, &models.OpError{Op: "rollback mitigation", Err: err})
+}
+
 func (r *rollbackMitigation) isConfigSnapshotNewerThan(newConfigSnapshot *gocbcore.ConfigSnapshot) bool {
 	oldEpoch, oldRevID := r.getRevEpochAndID(r.configSnapshot)
 	newEpoch, newRevID := r.getRevEpochAndID(newConfigSnapshot)
@@ -108,12 +121,13 @@ func (r *rollbackMitigation) observeVbID(
 	callback func(*gocbcore.ObserveVbResult, error),
 ) { //nolint:unused
 	_, err := r.client.GetAgent().ObserveVb(gocbcore.ObserveVbOptions{
-		VbID:       vbID,
-		ReplicaIdx: replica,
-		VbUUID:     vbUUID,
+		VbID:         vbID,
+		ReplicaIdx:   replica,
+		VbUUID:       vbUUID,
+		TraceContext: RequestSpanFromContext(r.ctx),
 	}, callback)
 	if err != nil {
-		logger.Log.Error("observeVBID error for vbId: %v, replica:%v, vbUUID: %v, err: %v", vbID, replica, vbUUID, err)
+		r.logger.With("vbId", vbID).Error("observeVBID error for replica: %v, vbUUID: %v, err: %v", replica, vbUUID, err)
 		callback(nil, err)
 	}
 }
@@ -130,7 +144,7 @@ func (r *rollbackMitigation) getMinSeqNo(vbID uint16) gocbcore.SeqNo { //nolint:
 	}
 
 	if startIndex == -1 {
-		logger.Log.Error("all replicas absent")
+		r.logger.Error("all replicas absent")
 		return 0
 	}
 
@@ -144,7 +158,7 @@ func (r *rollbackMitigation) getMinSeqNo(vbID uint16) gocbcore.SeqNo { //nolint:
 		}
 
 		if vbUUID != replica.vbUUID {
-			logger.Log.Debug("vbUUID mismatch %v != %v for %v index of %v", vbUUID, replica.vbUUID, idx, len(replicas))
+			r.logger.Debug("vbUUID mismatch %v != %v for %v index of %v", vbUUID, replica.vbUUID, idx, len(replicas))
 			return 0
 		}
 
@@ -164,7 +178,7 @@ func (r *rollbackMitigation) markAbsentInstances() error { //nolint:unused
 			serverIndex, err := r.configSnapshot.VbucketToServer(vbID, uint32(idx))
 			if err != nil {
 				if errors.Is(err, gocbcore.ErrInvalidReplica) {
-					logger.Log.Debug("invalid replica of vbId: %v, replica: %v, err: %v", vbID, idx, err)
+					r.logger.With("vbId", vbID).Debug("invalid replica: %v, err: %v", idx, err)
 					replica.SetAbsent()
 				} else {
 					outerError = err
@@ -172,7 +186,7 @@ func (r *rollbackMitigation) markAbsentInstances() error { //nolint:unused
 				}
 			} else {
 				if serverIndex < 0 {
-					logger.Log.Debug("invalid server index of vbId: %v, replica: %v, serverIndex: %v", vbID, idx, serverIndex)
+					r.logger.With("vbId", vbID).Debug("invalid server index of replica: %v, serverIndex: %v", idx, serverIndex)
 					replica.SetAbsent()
 				}
 			}
@@ -187,12 +201,18 @@ func (r *rollbackMitigation) markAbsentInstances() error { //nolint:unused
 func (r *rollbackMitigation) startObserve(groupID int) {
 	r.vbUUIDMap = wrapper.CreateConcurrentSwissMap[uint16, gocbcore.VbUUID](1024)
 
-	r.loadVbUUIDMap()
+	loaded := r.tryLoadVbUUIDMap()
 
 	r.observeTimer = time.NewTicker(r.config.RollbackMitigation.Interval)
 	for {
 		select {
 		case <-r.observeTimer.C:
+			if !loaded {
+				if loaded = r.tryLoadVbUUIDMap(); !loaded {
+					continue
+				}
+			}
+
 			r.persistedSeqNos.Range(func(vbID uint16, replicas []*vbUUIDAndSeqNo) bool {
 				for idx, replica := range replicas {
 					if replica.IsAbsent() {
@@ -200,7 +220,7 @@ func (r *rollbackMitigation) startObserve(groupID int) {
 					}
 
 					if r.closed || r.activeGroupID != groupID {
-						logger.Log.Debug("closed(%v) or groupID(%v!=%v) changed on startObserve", r.closed, r.activeGroupID, groupID)
+						r.logger.Debug("closed(%v) or groupID(%v!=%v) changed on startObserve", r.closed, r.activeGroupID, groupID)
 						return false
 					}
 
@@ -211,18 +231,31 @@ func (r *rollbackMitigation) startObserve(groupID int) {
 				return true
 			})
 		case <-r.observeCloseCh:
-			logger.Log.Debug("observe close triggered")
+			r.logger.Debug("observe close triggered")
 			r.observeCloseDoneCh <- struct{}{}
 			return
 		}
 	}
 }
 
-func (r *rollbackMitigation) loadVbUUIDMap() {
+// tryLoadVbUUIDMap reports whether the vbUUIDs are loaded, they are loaded again on the next observe otherwise.
+func (r *rollbackMitigation) tryLoadVbUUIDMap() bool {
+	if err := r.loadVbUUIDMap(); err != nil {
+		r.publishError(err)
+		return false
+	}
+
+	return true
+}
+
+func (r *rollbackMitigation) loadVbUUIDMap() error {
+	var outerError error
+
 	r.persistedSeqNos.Range(func(vbID uint16, _ []*vbUUIDAndSeqNo) bool {
-		failoverLogs, err := r.client.GetFailoverLogs(vbID)
+		failoverLogs, err := r.client.GetFailoverLogs(r.ctx, vbID)
 		if err != nil {
-			panic(err)
+			outerError = fmt.Errorf("cannot get failover logs of vbID: %v, err: %w", vbID, err)
+			return false
 		}
 
 		r.vbUUIDMap.Store(vbID, failoverLogs[0].VbUUID)
@@ -235,56 +268,64 @@ func (r *rollbackMitigation) loadVbUUIDMap() {
 			)
 		}
 
-		logger.Log.Debug(
+		r.logger.Debug(
 			"observing vbID: %v, vbUUID: %v, failoverInfo: %v",
 			vbID, failoverLogs[0].VbUUID, strings.Join(failoverInfos, ", "),
 		)
 
 		return true
 	})
+
+	return outerError
 }
 
-func (r *rollbackMitigation) reconfigure() {
-	logger.Log.Debug("reconfigure triggerred")
+func (r *rollbackMitigation) reconfigure() error {
+	r.logger.Debug("reconfigure triggerred")
 
 	if r.observeTimer != nil {
 		r.observeTimer.Stop()
 		r.observeCloseCh <- struct{}{}
 		<-r.observeCloseDoneCh
-		logger.Log.Debug("observe close done")
+		r.observeTimer = nil
+		r.logger.Debug("observe close done")
 	}
 
 	r.activeGroupID++
-	logger.Log.Info("new cluster config received, groupId = %v", r.activeGroupID)
+	r.logger.Info("new cluster config received, groupId = %v", r.activeGroupID)
 
-	r.reset()
-	err := r.markAbsentInstances()
-	if err != nil {
-		panic(err)
+	if err := r.reset(); err != nil {
+		return err
+	}
+
+	if err := r.markAbsentInstances(); err != nil {
+		return err
 	}
 
 	go r.startObserve(r.activeGroupID)
+
+	return nil
 }
 
 func (r *rollbackMitigation) observe(vbID uint16, replica int, groupID int, vbUUID gocbcore.VbUUID) {
 	r.observeVbID(vbID, replica, vbUUID, func(result *gocbcore.ObserveVbResult, err error) {
 		if r.closed || r.activeGroupID != groupID {
-			logger.Log.Debug("closed(%v) or groupID(%v!=%v) changed on observe", r.closed, r.activeGroupID, groupID)
+			r.logger.Debug("closed(%v) or groupID(%v!=%v) changed on observe", r.closed, r.activeGroupID, groupID)
 			return
 		}
 
 		if err != nil {
 			if errors.Is(err, gocbcore.ErrTemporaryFailure) {
-				logger.Log.Error("error while observe: %v", err)
-				return
+				r.logger.Error("error while observe: %v", err)
 			} else {
-				panic(err)
+				r.publishError(fmt.Errorf("cannot observe vbID: %v, replica: %v, err: %w", vbID, replica, err))
 			}
+
+			return
 		}
 
 		replicas, ok := r.persistedSeqNos.Load(vbID)
 		if !ok {
-			logger.Log.Error("replicas of vbID: %v not found", vbID)
+			r.logger.With("vbId", vbID).Error("replicas not found")
 		}
 
 		if len(replicas) > replica {
@@ -300,15 +341,15 @@ func (r *rollbackMitigation) observe(vbID uint16, replica int, groupID int, vbUU
 				r.vbUUIDMap.Store(vbID, result.VbUUID)
 			}
 		} else {
-			logger.Log.Error("replica: %v not found", replica)
+			r.logger.Error("replica: %v not found", replica)
 		}
 	})
 }
 
-func (r *rollbackMitigation) reset() {
+func (r *rollbackMitigation) reset() error {
 	replicas, err := r.configSnapshot.NumReplicas()
 	if err != nil {
-		panic(err)
+		return err
 	}
 
 	r.persistedSeqNos = wrapper.CreateConcurrentSwissMap[uint16, []*vbUUIDAndSeqNo](1024)
@@ -321,12 +362,14 @@ func (r *rollbackMitigation) reset() {
 
 		r.persistedSeqNos.Store(vbID, replicaArr)
 	}
+
+	return nil
 }
 
 func (r *rollbackMitigation) waitFirstConfig() error {
-	opm := NewAsyncOp(context.Background())
+	opm := NewAsyncOp(r.ctx)
 
-	ch := make(chan error)
+	ch := make(chan error, 1)
 
 	op, err := r.client.GetAgent().WaitForConfigSnapshot(
 		time.Now().Add(r.config.ConnectionTimeout),
@@ -348,16 +391,19 @@ func (r *rollbackMitigation) waitFirstConfig() error {
 	return <-ch
 }
 
-func (r *rollbackMitigation) Start() {
-	logger.Log.Info("rollback mitigation will start with %v interval", r.config.RollbackMitigation.Interval)
+func (r *rollbackMitigation) Start(ctx context.Context) error {
+	r.ctx = ctx
+
+	r.logger.Info("rollback mitigation will start with %v interval", r.config.RollbackMitigation.Interval)
 
 	err := r.waitFirstConfig()
 	if err != nil {
-		logger.Log.Error("cannot get first config: %v", err)
-		panic(err)
+		return fmt.Errorf("cannot get first config: %w", err)
 	}
 
-	r.reconfigure()
+	if err := r.reconfigure(); err != nil {
+		return err
+	}
 
 	go func() {
 		r.configWatchTimer = time.NewTicker(r.config.RollbackMitigation.ConfigWatchInterval)
@@ -365,6 +411,8 @@ func (r *rollbackMitigation) Start() {
 			r.configWatch()
 		}
 	}()
+
+	return nil
 }
 
 func (r *rollbackMitigation) Stop() {
@@ -379,11 +427,18 @@ func (r *rollbackMitigation) Stop() {
 		r.configWatchTimer.Stop()
 	}
 
-	logger.Log.Info("rollback mitigation stopped")
+	r.logger.Info("rollback mitigation stopped")
 }
 
-func NewRollbackMitigation(client Client, config *config.Dcp, vbIds []uint16, bus EventBus.Bus) RollbackMitigation {
+func NewRollbackMitigation(
+	client Client,
+	config *config.Dcp,
+	vbIds []uint16,
+	bus EventBus.Bus,
+	logger logger.Logger,
+) RollbackMitigation {
 	return &rollbackMitigation{
+		logger:             logger,
 		client:             client,
 		config:             config,
 		vbIds:              vbIds,
@@ -0,0 +1,20 @@
+package couchbase
+
+import (
+	"context"
+
+	"github.com/couchbase/gocbcore/v10"
+)
+
+type requestSpanKey struct{}
+
+// ContextWithRequestSpan returns a copy of ctx carrying span, KV operations made with the returned context
+// are traced as children of it.
+func ContextWithRequestSpan(ctx context.Context, span gocbcore.RequestSpanContext) context.Context {
+	return context.WithValue(ctx, requestSpanKey{}, span)
+}
+
+// RequestSpanFromContext returns the span attached by ContextWithRequestSpan, or nil.
+func RequestSpanFromContext(ctx context.Context) gocbcore.RequestSpanContext {
+	return ctx.Value(requestSpanKey{})
+}
@@ -0,0 +1,18 @@
+package couchbase
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRequestSpanFromContext(t *testing.T) {
+	if span := RequestSpanFromContext(context.Background()); span != nil {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", nil, span)
+	}
+
+	ctx := ContextWithRequestSpan(context.Background(), "span")
+
+	if span := RequestSpanFromContext(ctx); span != "span" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "span", span)
+	}
+}
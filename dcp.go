@@ -1,11 +1,13 @@
 package dcp
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"reflect"
+	"sync"
 	"syscall"
 
 	"github.com/asaskevich/EventBus"
@@ -20,6 +22,9 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/Trendyol/go-dcp/api"
 	"github.com/Trendyol/go-dcp/config"
 	"github.com/Trendyol/go-dcp/couchbase"
@@ -32,19 +37,53 @@ import (
 	"github.com/Trendyol/go-dcp/stream"
 )
 
+var ErrInvalidConfig = errors.New("invalid config")
+
+// Options are the dependencies of a Dcp instance, zero values fall back to the process-wide defaults.
+// Instances with their own options can run side by side in one process.
+type Options struct {
+	// Logger defaults to logger.Log.
+	Logger logger.Logger
+	// MetricRegistry defaults to the prometheus default registry.
+	MetricRegistry metric.Registry
+	// SharedAPI serves the api of the instance under /connectors/{Name} instead of listening on api.port.
+	SharedAPI api.SharedAPI
+	// Name tells instances apart, it is the connector label of metrics and defaults to the group name with SharedAPI.
+	Name string
+	// TracerProvider records the spans of events, checkpoint saves and rebalances. The global provider is used
+	// when tracing.enabled is set, spans are not recorded otherwise.
+	TracerProvider trace.TracerProvider
+	// APIAuthenticator authenticates the callers of the api instead of the one of api.auth.type.
+	APIAuthenticator api.Authenticator
+	// DisableSignalHandling stops the instance from closing on SIGTERM and SIGINT, use StartContext to stop it.
+	DisableSignalHandling bool
+}
+
 type Dcp interface {
 	WaitUntilReady() chan struct{}
-	Start()
-	Close()
+	Start() error
+	StartContext(ctx context.Context) error
+	Close() error
 	Commit()
+	CommitContext(ctx context.Context)
 	GetConfig() *config.Dcp
 	GetVersion() *couchbase.Version
+	Reload(config *config.Dcp) error
 	SetMetadata(metadata metadata.Metadata)
 	SetMetricCollectors(collectors ...prometheus.Collector)
 	SetEventHandler(handler models.EventHandler)
+	SetErrorHandler(handler models.ErrorHandler)
 }
 
 type dcp struct {
+	logger            logger.Logger
+	sharedLogger      bool
+	tracer            trace.Tracer
+	tail              *stream.Tail
+	metricRegistry    metric.Registry
+	sharedAPI         api.SharedAPI
+	apiAuthenticator  api.Authenticator
+	ctx               context.Context
 	bus               EventBus.Bus
 	stream            stream.Stream
 	api               api.API
@@ -52,11 +91,13 @@ type dcp struct {
 	vBucketDiscovery  stream.VBucketDiscovery
 	serviceDiscovery  servicediscovery.ServiceDiscovery
 	metadata          metadata.Metadata
+	membership        membership.Membership
 	eventHandler      models.EventHandler
+	errorHandler      models.ErrorHandler
 	client            couchbase.Client
-	apiShutdown       chan struct{}
 	healCheckFailedCh chan struct{}
 	config            *config.Dcp
+	loadedConfig      config.Dcp
 	version           *couchbase.Version
 	bucketInfo        *couchbase.BucketInfo
 	healthCheck       couchbase.HealthCheck
@@ -65,7 +106,17 @@ type dcp struct {
 	cancelCh          chan os.Signal
 	stopCh            chan struct{}
 	metricCollectors  []prometheus.Collector
+	sources           []*source
+	reloadMetric      *metric.ReloadMetric
+	reloadStopCh      chan struct{}
+	name              string
+	configPath        string
+	closeErr          error
+	reloadLock        sync.Mutex
+	closeOnce         sync.Once
+	readyOnce         sync.Once
 	closeWithCancel   bool
+	signalHandling    bool
 }
 
 func (s *dcp) SetMetadata(metadata metadata.Metadata) {
@@ -80,167 +131,322 @@ func (s *dcp) SetEventHandler(eventHandler models.EventHandler) {
 	s.eventHandler = eventHandler
 }
 
+func (s *dcp) SetErrorHandler(errorHandler models.ErrorHandler) {
+	s.errorHandler = errorHandler
+}
+
 func (s *dcp) membershipChangedListener(_ *membership.Model) {
-	s.stream.Rebalance()
+	for _, stream := range s.streams() {
+		stream.Rebalance()
+	}
+}
+
+// streams returns the top level stream and the streams of every opened source.
+func (s *dcp) streams() []stream.Stream {
+	streams := []stream.Stream{s.stream}
+
+	for _, source := range s.sources {
+		if source.stream != nil {
+			streams = append(streams, source.stream)
+		}
+	}
+
+	return streams
+}
+
+// stopped is closed once the streams of the top level bucket and every source are finished.
+func (s *dcp) stopped() <-chan struct{} {
+	if len(s.sources) == 0 {
+		return s.stopCh
+	}
+
+	ch := make(chan struct{})
+
+	go func() {
+		<-s.stopCh
+
+		for _, source := range s.sources {
+			<-source.stopCh
+		}
+
+		close(ch)
+	}()
+
+	return ch
+}
+
+// errorListener receives failures of background operations. They are retried by the failed operation,
+// so they are only reported to the error handler and do not stop the connector.
+func (s *dcp) errorListener(opErr *models.OpError) {
+	if s.errorHandler != nil {
+		s.errorHandler(opErr)
+	}
+}
+
+func (s *dcp) createMetadata() (metadata.Metadata, error) {
+	switch {
+	case s.config.IsCouchbaseMetadata():
+		return couchbase.NewCBMetadata(s.client, s.config)
+	case s.config.IsFileMetadata():
+		return metadata.NewFSMetadata(s.config)
+	default:
+		return nil, fmt.Errorf("metadata: %s, err: %w", s.config.Metadata.Type, metadata.ErrUnsupportedMetadataType)
+	}
+}
+
+func (s *dcp) Start() error {
+	return s.StartContext(context.Background())
+}
+
+// StartContext starts streaming and blocks until the connector stops.
+// Every KV and DCP operation uses ctx, cancelling it stops the connector like a termination signal and returns nil.
+func (s *dcp) StartContext(ctx context.Context) error {
+	err := s.start(ctx)
+
+	// waiters are released when the connector cannot start as well, the error is returned by StartContext
+	s.readyOnce.Do(func() {
+		close(s.readyCh)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-s.stopped():
+	case <-s.cancelCh:
+		s.closeWithCancel = true
+	case <-ctx.Done():
+		s.closeWithCancel = true
+	case <-s.healCheckFailedCh:
+	}
+
+	return nil
 }
 
 //nolint:funlen
-func (s *dcp) Start() {
+func (s *dcp) start(ctx context.Context) error {
+	s.ctx = ctx
+
 	if s.metadata == nil {
-		switch {
-		case s.config.IsCouchbaseMetadata():
-			s.metadata = couchbase.NewCBMetadata(s.client, s.config)
-		case s.config.IsFileMetadata():
-			s.metadata = metadata.NewFSMetadata(s.config)
-		default:
-			panic(errors.New("invalid metadata type"))
+		md, err := s.createMetadata()
+		if err != nil {
+			return err
 		}
+
+		s.metadata = md
 	}
 
 	if s.config.Metadata.ReadOnly {
 		s.metadata = metadata.NewReadMetadata(s.metadata)
 	}
 
-	logger.Log.Info("using %v metadata", reflect.TypeOf(s.metadata))
+	s.logger.Info("using %v metadata", reflect.TypeOf(s.metadata))
+
+	err := s.bus.SubscribeAsync(helpers.ErrorBusEventName, s.errorListener, false)
+	if err != nil {
+		return fmt.Errorf("cannot subscribe to error event: %w", err)
+	}
+
+	vBuckets, err := s.client.GetNumVBuckets()
+	if err != nil {
+		return err
+	}
+
+	collectionIDs, err := s.client.GetCollectionIDs(ctx, s.config.ScopeName, s.config.CollectionNames)
+	if err != nil {
+		return err
+	}
 
-	vBuckets := s.client.GetNumVBuckets()
+	if s.membership != nil {
+		s.vBucketDiscovery = stream.NewVBucketDiscoveryWithMembership(
+			s.membership, reflect.TypeOf(s.membership).String(), vBuckets, s.logger,
+		)
 
-	s.vBucketDiscovery = stream.NewVBucketDiscovery(s.client, s.config, vBuckets, s.bus)
+		if watcher, ok := s.membership.(membership.Watcher); ok {
+			watcher.Watch(func(model *membership.Model) {
+				s.bus.Publish(helpers.MembershipChangedBusEventName, model)
+			})
+		}
+	} else {
+		s.vBucketDiscovery, err = stream.NewVBucketDiscovery(s.client, s.config, vBuckets, s.bus, s.logger)
+		if err != nil {
+			return err
+		}
+	}
 
 	s.stream = stream.NewStream(
 		s.client, s.metadata, s.config, s.version, s.bucketInfo, s.vBucketDiscovery,
-		s.listener, s.client.GetCollectionIDs(s.config.ScopeName, s.config.CollectionNames), s.stopCh, s.bus, s.eventHandler,
+		s.listener, collectionIDs, s.stopCh, s.bus, s.eventHandler, s.tracer, s.tail, s.logger,
 	)
 
 	if s.config.LeaderElection.Enabled {
-		s.serviceDiscovery = servicediscovery.NewServiceDiscovery(s.config, s.bus)
-		s.serviceDiscovery.StartHeartbeat()
+		s.serviceDiscovery = servicediscovery.NewServiceDiscovery(s.config, s.bus, s.logger)
+		s.vBucketDiscovery.SetMemberLister(s.serviceDiscovery)
+		s.serviceDiscovery.SetOffsetProvider(s.getOffsets)
+
+		if s.config.IsCouchbaseMetadata() {
+			epochStore, err := couchbase.NewCBEpochStore(s.client, s.config)
+			if err != nil {
+				return err
+			}
+
+			s.serviceDiscovery.SetEpochStore(epochStore)
+		}
+
 		s.serviceDiscovery.StartMonitor()
 
-		s.leaderElection = stream.NewLeaderElection(s.config, s.serviceDiscovery, s.bus)
-		s.leaderElection.Start()
+		s.leaderElection = stream.NewLeaderElection(s.config, s.serviceDiscovery, s.bus, s.logger)
+		if err := s.leaderElection.Start(ctx); err != nil {
+			return err
+		}
 	}
 
-	s.stream.Open()
+	if err := s.stream.Open(ctx); err != nil {
+		return err
+	}
 
-	err := s.bus.SubscribeAsync(helpers.MembershipChangedBusEventName, s.membershipChangedListener, true)
+	for _, source := range s.sources {
+		err := source.open(ctx, s.vBucketDiscovery, s.listener, s.eventHandler, s.errorListener, vBuckets)
+		if err != nil {
+			return fmt.Errorf("cannot open source %s: %w", source.config.GetSourceName(), err)
+		}
+	}
+
+	err = s.bus.SubscribeAsync(helpers.MembershipChangedBusEventName, s.membershipChangedListener, true)
 	if err != nil {
-		logger.Log.Error("cannot subscribe to membership changed event: %v", err)
-		panic(err)
+		return fmt.Errorf("cannot subscribe to membership changed event: %w", err)
 	}
 
 	if !s.config.API.Disabled {
-		go func() {
-			go func() {
-				<-s.apiShutdown
-				s.api.Shutdown()
-			}()
-
-			s.metricCollectors = append(s.metricCollectors, metric.NewMetricCollector(s.client, s.stream, s.vBucketDiscovery))
-			s.api = api.NewAPI(s.config, s.client, s.stream, s.serviceDiscovery, s.metricCollectors)
-			s.api.Listen()
-		}()
+		if err := s.startAPI(); err != nil {
+			return err
+		}
 	}
 
-	signal.Notify(s.cancelCh, syscall.SIGTERM, syscall.SIGINT, syscall.SIGABRT, syscall.SIGQUIT)
+	if s.signalHandling {
+		signal.Notify(s.cancelCh, syscall.SIGTERM, syscall.SIGINT, syscall.SIGABRT, syscall.SIGQUIT)
+	}
 
 	if !s.config.HealthCheck.Disabled {
-		s.healthCheck = couchbase.NewHealthCheck(&s.config.HealthCheck, s.client)
-		s.healthCheck.Start(s.healCheckFailedCh)
+		// the api is already started, a reload through it must not see a health check which is not started
+		s.reloadLock.Lock()
+		s.healthCheck = couchbase.NewHealthCheck(&s.config.HealthCheck, s.client, s.logger)
+		s.healthCheck.Start(ctx, s.healCheckFailedCh)
+		s.reloadLock.Unlock()
 	}
 
-	logger.Log.Info("dcp stream started")
-
-	s.readyCh <- struct{}{}
-
-	select {
-	case <-s.stopCh:
-	case <-s.cancelCh:
-		s.closeWithCancel = true
-	case <-s.healCheckFailedCh:
+	if s.configPath != "" && s.config.Reload.Watch {
+		s.reloadStopCh = make(chan struct{})
+		go s.watchConfigFile(s.reloadStopCh)
 	}
-}
 
-func (s *dcp) WaitUntilReady() chan struct{} {
-	return s.readyCh
-}
+	s.logger.Info("dcp stream started")
 
-func (s *dcp) Close() {
-	if !s.config.HealthCheck.Disabled {
-		s.healthCheck.Stop()
-	}
-	s.vBucketDiscovery.Close()
+	return nil
+}
 
-	if s.config.Checkpoint.Type == stream.CheckpointTypeAuto {
-		s.stream.Save()
-	}
+func (s *dcp) startAPI() error {
+	s.metricCollectors = append(s.metricCollectors,
+		metric.NewMetricCollector(s.client, s.stream, s.vBucketDiscovery, s.sourceLabels(s.config)),
+		metric.NewMembershipCollector(s.vBucketDiscovery),
+		metric.NewReloadCollector(s.reloadMetric),
+	)
 
-	err := s.bus.Unsubscribe(helpers.MembershipChangedBusEventName, s.membershipChangedListener)
-	if err != nil {
-		logger.Log.Error("cannot while unsubscribe: %v", err)
+	for _, source := range s.sources {
+		s.metricCollectors = append(s.metricCollectors,
+			metric.NewMetricCollector(source.client, source.stream, s.vBucketDiscovery, s.sourceLabels(source.config)),
+		)
 	}
 
-	s.stream.Close(s.closeWithCancel)
+	if s.sharedAPI != nil {
+		api, err := s.sharedAPI.Attach(
+			s.name, s.config, s.client, s.apiStream(), s.serviceDiscovery, s.vBucketDiscovery, s.metricCollectors,
+		)
+		if err != nil {
+			return err
+		}
 
-	if s.config.LeaderElection.Enabled {
-		s.leaderElection.Stop()
+		s.api = api
+		s.setReloader()
+		s.setAuthenticator()
 
-		s.serviceDiscovery.StopMonitor()
-		s.serviceDiscovery.StopHeartbeat()
+		return nil
 	}
 
-	if s.api != nil && !s.config.API.Disabled {
-		s.apiShutdown <- struct{}{}
+	registry := s.metricRegistry
+	if s.name != "" {
+		registry = metric.WrapRegistryWith(prometheus.Labels{api.ConnectorLabel: s.name}, registry)
 	}
 
-	s.client.DcpClose()
-	s.client.Close()
+	s.api = api.NewAPI(
+		s.config, s.client, s.apiStream(), s.serviceDiscovery, s.vBucketDiscovery, s.metricCollectors, registry, s.logger,
+	)
 
-	s.api.UnregisterMetricCollectors()
-	s.metricCollectors = []prometheus.Collector{}
+	s.setReloader()
+	s.setAuthenticator()
+
+	go s.api.Listen()
 
-	logger.Log.Info("dcp stream closed")
+	return nil
 }
 
-func (s *dcp) Commit() {
-	s.stream.Save()
+func (s *dcp) setAuthenticator() {
+	if s.apiAuthenticator != nil {
+		s.api.SetAuthenticator(s.apiAuthenticator)
+	}
 }
 
-func (s *dcp) GetConfig() *config.Dcp {
-	return s.config
+func (s *dcp) setReloader() {
+	if s.configPath != "" {
+		s.api.SetReloader(s.reloadFile)
+	}
 }
 
-func (s *dcp) GetVersion() *couchbase.Version {
-	return s.version
+func (s *dcp) apiStream() stream.Stream {
+	if len(s.sources) == 0 {
+		return s.stream
+	}
+
+	return &sourcesStream{Stream: s.stream, sources: s.sources}
 }
 
-func newDcp(config *config.Dcp, listener models.Listener) (Dcp, error) {
-	config.ApplyDefaults()
-	copyOfConfig := config
-	printConfiguration(*copyOfConfig)
+// sourceLabels tells the stream metrics of sources apart, a connector without sources keeps its metrics unlabeled.
+func (s *dcp) sourceLabels(config *config.Dcp) prometheus.Labels {
+	if len(s.sources) == 0 {
+		return nil
+	}
+
+	return prometheus.Labels{"source": config.GetSourceName()}
+}
 
-	client := couchbase.NewClient(config)
+// connect opens the kv and dcp connections of the bucket in config.
+func connect(config *config.Dcp, logger logger.Logger) (couchbase.Client, *couchbase.Version, *couchbase.BucketInfo, error) {
+	client := couchbase.NewClient(config, logger)
 
 	err := client.Connect()
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	httpClient := couchbase.NewHTTPClient(config, client)
 
 	err = httpClient.Connect()
 	if err != nil {
-		return nil, err
+		client.Close()
+		return nil, nil, nil, err
 	}
 
 	version, err := httpClient.GetVersion()
 	if err != nil {
-		return nil, err
+		client.Close()
+		return nil, nil, nil, err
 	}
 
 	bucketInfo, err := httpClient.GetBucketInfo()
 	if err != nil {
-		return nil, err
+		client.Close()
+		return nil, nil, nil, err
 	}
 
 	var useExpiryOpcode bool
@@ -255,25 +461,220 @@ func newDcp(config *config.Dcp, listener models.Listener) (Dcp, error) {
 	}
 
 	err = client.DcpConnect(useExpiryOpcode, useChangeStreams)
+	if err != nil {
+		client.Close()
+		return nil, nil, nil, err
+	}
+
+	return client, version, bucketInfo, nil
+}
+
+// disconnect closes a client returned by connect.
+func disconnect(client couchbase.Client) {
+	client.DcpClose()
+	client.Close()
+}
+
+// WaitUntilReady is closed once the connector is streaming or Start returned an error.
+func (s *dcp) WaitUntilReady() chan struct{} {
+	return s.readyCh
+}
+
+// Close releases everything opened by Start, it is safe to call after Start returned an error.
+// Only the first call closes the connector, later ones return its result.
+func (s *dcp) Close() error {
+	s.closeOnce.Do(func() {
+		s.closeErr = s.close()
+	})
+
+	return s.closeErr
+}
+
+func (s *dcp) close() error {
+	var errs []error
+
+	if s.healthCheck != nil {
+		s.healthCheck.Stop()
+	}
+
+	if s.reloadStopCh != nil {
+		close(s.reloadStopCh)
+		s.reloadStopCh = nil
+	}
+
+	if s.vBucketDiscovery != nil {
+		s.vBucketDiscovery.Close()
+	}
+
+	if s.stream != nil {
+		if s.bus.HasCallback(helpers.MembershipChangedBusEventName) {
+			if err := s.bus.Unsubscribe(helpers.MembershipChangedBusEventName, s.membershipChangedListener); err != nil {
+				errs = append(errs, fmt.Errorf("cannot unsubscribe from membership changed event: %w", err))
+			}
+		}
+
+		s.stream.Close(s.closeWithCancel)
+	}
+
+	for _, source := range s.sources {
+		if err := source.close(s.closeWithCancel, s.errorListener); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if s.leaderElection != nil {
+		s.leaderElection.Stop()
+	}
+
+	if s.serviceDiscovery != nil {
+		s.serviceDiscovery.StopMonitor()
+	}
+
+	if s.api != nil {
+		if err := s.api.Shutdown(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if s.signalHandling {
+		signal.Stop(s.cancelCh)
+	}
+
+	disconnect(s.client)
+
+	if s.api != nil {
+		s.api.UnregisterMetricCollectors()
+	}
+	s.metricCollectors = []prometheus.Collector{}
+
+	if s.bus.HasCallback(helpers.ErrorBusEventName) {
+		if err := s.bus.Unsubscribe(helpers.ErrorBusEventName, s.errorListener); err != nil {
+			errs = append(errs, fmt.Errorf("cannot unsubscribe from error event: %w", err))
+		}
+	}
+
+	s.logger.Info("dcp stream closed")
+
+	return errors.Join(errs...)
+}
+
+func (s *dcp) getOffsets() map[uint16]uint64 {
+	offsets, _, _ := s.stream.GetOffsets()
+	seqNos := make(map[uint16]uint64, offsets.Count())
 
+	offsets.Range(func(vbID uint16, offset *models.Offset) bool {
+		seqNos[vbID] = offset.SeqNo
+		return true
+	})
+
+	return seqNos
+}
+
+func (s *dcp) Commit() {
+	s.CommitContext(context.Background())
+}
+
+func (s *dcp) CommitContext(ctx context.Context) {
+	for _, stream := range s.streams() {
+		stream.Save(ctx)
+	}
+}
+
+func (s *dcp) GetConfig() *config.Dcp {
+	return s.config
+}
+
+func (s *dcp) GetVersion() *couchbase.Version {
+	return s.version
+}
+
+func newDcp(config *config.Dcp, listener models.Listener, options Options) (Dcp, error) {
+	if err := config.ApplyDefaults(); err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	// the level of the shared default logger is not changed on reload, as it is used by every connector
+	sharedLogger := options.Logger == nil || options.Logger == logger.Log
+	if options.Logger == nil {
+		options.Logger = logger.Log
+	}
+
+	options.Logger = options.Logger.With("group", config.Dcp.Group.Name)
+
+	if options.TracerProvider == nil && config.Tracing.Enabled {
+		options.TracerProvider = otel.GetTracerProvider()
+	}
+
+	tracer := stream.NewTracer(options.TracerProvider)
+
+	// the sources share the tail of the connector, so the api tails every bucket
+	tail := stream.NewTail()
+
+	if options.MetricRegistry == nil {
+		options.MetricRegistry = metric.DefaultRegistry()
+	}
+
+	if options.Name == "" && options.SharedAPI != nil {
+		options.Name = config.Dcp.Group.Name
+	}
+
+	copyOfConfig := config
+	printConfiguration(*copyOfConfig)
+
+	client, version, bucketInfo, err := connect(config, options.Logger)
 	if err != nil {
 		return nil, err
 	}
 
+	sources := make([]*source, 0, len(config.Sources))
+
+	for _, sourceConfig := range config.Sources {
+		sourceDcpConfig := config.NewSourceConfig(sourceConfig)
+
+		source, err := newSource(sourceDcpConfig, tracer, tail, options.Logger.With("source", sourceDcpConfig.GetSourceName()))
+		if err != nil {
+			// connections opened before the failed source are not owned by any connector
+			for _, connected := range sources {
+				disconnect(connected.client)
+			}
+
+			disconnect(client)
+
+			return nil, fmt.Errorf("cannot connect to source %s: %w", sourceConfig.Name, err)
+		}
+
+		sources = append(sources, source)
+	}
+
 	return &dcp{
+		sources:           sources,
 		client:            client,
 		listener:          listener,
 		config:            config,
+		loadedConfig:      *config,
 		version:           version,
 		bucketInfo:        bucketInfo,
-		apiShutdown:       make(chan struct{}, 1),
 		cancelCh:          make(chan os.Signal, 1),
 		stopCh:            make(chan struct{}, 1),
 		healCheckFailedCh: make(chan struct{}, 1),
-		readyCh:           make(chan struct{}, 1),
+		readyCh:           make(chan struct{}),
 		metricCollectors:  []prometheus.Collector{},
+		reloadMetric:      &metric.ReloadMetric{},
 		eventHandler:      models.DefaultEventHandler,
 		bus:               EventBus.New(),
+		logger:            options.Logger,
+		sharedLogger:      sharedLogger,
+		tracer:            tracer,
+		tail:              tail,
+		metricRegistry:    options.MetricRegistry,
+		sharedAPI:         options.SharedAPI,
+		apiAuthenticator:  options.APIAuthenticator,
+		name:              options.Name,
+		signalHandling:    !options.DisableSignalHandling,
 	}, nil
 }
 
@@ -282,24 +683,37 @@ func newDcp(config *config.Dcp, listener models.Listener) (Dcp, error) {
 // config: path to a configuration file or a configuration struct
 // listener is a callback function that will be called when a mutation, deletion or expiration event occurs
 func NewDcp(cfg any, listener models.Listener) (Dcp, error) {
+	return NewDcpWithOptions(cfg, listener, Options{})
+}
+
+// NewDcpWithOptions creates a new Dcp client with its own logger, metric registry, api and signal handling
+func NewDcpWithOptions(cfg any, listener models.Listener, options Options) (Dcp, error) {
 	switch v := cfg.(type) {
 	case *config.Dcp:
-		return newDcp(v, listener)
+		return newDcp(v, listener, options)
 	case config.Dcp:
-		return newDcp(&v, listener)
+		return newDcp(&v, listener, options)
 	case string:
-		return newDcpWithPath(v, listener)
+		return newDcpWithPath(v, listener, options)
 	default:
-		return nil, errors.New("invalid config")
+		return nil, ErrInvalidConfig
 	}
 }
 
-func newDcpWithPath(path string, listener models.Listener) (Dcp, error) {
+func newDcpWithPath(path string, listener models.Listener, options Options) (Dcp, error) {
 	c, err := newDcpConfig(path)
 	if err != nil {
 		return nil, err
 	}
-	return newDcp(&c, listener)
+
+	connector, err := newDcp(&c, listener, options)
+	if err != nil {
+		return nil, err
+	}
+
+	connector.(*dcp).configPath = path
+
+	return connector, nil
 }
 
 func newDcpConfig(path string) (config.Dcp, error) {
@@ -316,14 +730,14 @@ func newDcpConfig(path string) (config.Dcp, error) {
 }
 
 func NewDcpWithLogger(cfg any, listener models.Listener, logrus *logrus.Logger) (Dcp, error) {
-	logger.Log = &logger.Loggers{
-		Logrus: logrus,
-	}
-	return NewDcp(cfg, listener)
+	return NewDcpWithOptions(cfg, listener, Options{
+		Logger: &logger.Loggers{
+			Logrus: logrus,
+		},
+	})
 }
 
 func printConfiguration(config config.Dcp) {
-	config.Password = "*****"
-	configJSON, _ := jsoniter.MarshalIndent(config, "", "  ")
+	configJSON, _ := jsoniter.MarshalIndent(config.Redacted(), "", "  ")
 	fmt.Printf("using config: %v", string(configJSON))
 }
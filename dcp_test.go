@@ -2,6 +2,7 @@ package dcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -15,9 +16,12 @@ import (
 	"github.com/Trendyol/go-dcp/logger"
 
 	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/metadata"
 
 	"github.com/Trendyol/go-dcp/couchbase"
+	"github.com/asaskevich/EventBus"
 	"github.com/couchbase/gocbcore/v10"
+	"github.com/sirupsen/logrus"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/wait"
 )
@@ -125,7 +129,7 @@ func setupContainer(c *config.Dcp, ctx context.Context, version string) (testcon
 func insertDataToContainer(c *config.Dcp, t *testing.T, iteration int, chunkSize int, bulkSize int) {
 	logger.Log.Info("mock data stream started with iteration=%v", iteration)
 
-	client := couchbase.NewClient(c)
+	client := couchbase.NewClient(c, logger.Log)
 
 	err := client.Connect()
 	if err != nil {
@@ -232,7 +236,9 @@ func test(t *testing.T, version string) {
 		dcp.Close()
 	}()
 
-	dcp.Start()
+	if err := dcp.Start(); err != nil {
+		t.Fatal(err)
+	}
 
 	err = container.Terminate(ctx)
 	if err != nil {
@@ -253,3 +259,57 @@ func TestDcp(t *testing.T) {
 		test(t, version)
 	})
 }
+
+func newTestStartDcp(c *config.Dcp) *dcp {
+	return &dcp{
+		config:  c,
+		client:  &testSourceClient{},
+		bus:     EventBus.New(),
+		readyCh: make(chan struct{}),
+		logger:  &logger.Loggers{Logrus: logrus.New()},
+	}
+}
+
+func TestStartWithBadConfigReleasesWaitUntilReady(t *testing.T) {
+	c := getConfig()
+	c.Metadata.Type = "unknown"
+
+	s := newTestStartDcp(c)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.Start()
+	}()
+
+	select {
+	case <-s.WaitUntilReady():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Unexpected result. Expected: %v, Got: %v", "ready channel closed", "blocked")
+	}
+
+	if err := <-errCh; !errors.Is(err, metadata.ErrUnsupportedMetadataType) {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", metadata.ErrUnsupportedMetadataType, err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Errorf("Unexpected error. Got: %v", err)
+	}
+}
+
+func TestCloseTwice(t *testing.T) {
+	s := newTestStartDcp(getConfig())
+
+	sourceStream := &testSourceStream{}
+	s.stream = sourceStream
+
+	for i := 0; i < 2; i++ {
+		if err := s.Close(); err != nil {
+			t.Errorf("Unexpected error. Got: %v", err)
+		}
+	}
+
+	client := s.client.(*testSourceClient)
+	if sourceStream.closed != 1 || client.dcpClosed != 1 || client.closed != 1 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v and %+v", "closed once", sourceStream.closed, client)
+	}
+}
@@ -36,5 +36,7 @@ func main() {
 
 	defer connector.Close()
 
-	connector.Start()
+	if err := connector.Start(); err != nil {
+		logger.Log.Error("dcp stopped with error: %v", err)
+	}
 }
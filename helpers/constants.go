@@ -8,6 +8,7 @@ const (
 
 	MembershipChangedBusEventName   string = "membershipChanged"
 	PersistSeqNoChangedBusEventName string = "persistSeqNoChanged"
+	ErrorBusEventName               string = "error"
 
 	JSONFlags uint32 = 50333696
 )
@@ -6,27 +6,35 @@ import (
 	"strings"
 )
 
+// ResolveUnionIntOrStringValue is like ParseUnionIntOrStringValue but panics for invalid sizes.
+//
+// Deprecated: use ParseUnionIntOrStringValue, the connector does not call it anymore.
 func ResolveUnionIntOrStringValue(input any) int {
+	result, err := ParseUnionIntOrStringValue(input)
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+// ParseUnionIntOrStringValue resolves an int or a size with unit like "5mb" and returns an error for invalid sizes.
+func ParseUnionIntOrStringValue(input any) (int, error) {
 	switch value := input.(type) {
 	case int:
-		return value
+		return value, nil
 	case uint:
-		return int(value)
+		return int(value), nil
 	case string:
 		intValue, err := strconv.ParseInt(value, 10, 64)
 		if err == nil {
-			return int(intValue)
-		}
-
-		result, err := convertSizeUnitToByte(value)
-		if err != nil {
-			panic(err)
+			return int(intValue), nil
 		}
 
-		return result
+		return convertSizeUnitToByte(value)
 	}
 
-	return 0
+	return 0, nil
 }
 
 func convertSizeUnitToByte(str string) (int, error) {
@@ -3,6 +3,7 @@ package kubernetes
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"strings"
 	"time"
@@ -21,6 +22,8 @@ import (
 
 const DefaultNamespace = "default"
 
+var ErrPodIPNotFound = errors.New("after 10 tries, pod ip is still empty")
+
 type Client interface {
 	CoordinationV1() v1.CoordinationV1Interface
 	AddLabel(key string, value string)
@@ -29,6 +32,7 @@ type Client interface {
 }
 
 type client struct {
+	logger     logger.Logger
 	myIdentity *models.Identity
 	clientSet  *clientSet.Clientset
 	namespace  string
@@ -46,7 +50,7 @@ func (le *client) AddLabel(key string, value string) {
 		metaV1.PatchOptions{},
 	)
 	if err != nil {
-		logger.Log.Error("failed to add label: %v", err)
+		le.logger.Error("failed to add label: %v", err)
 	}
 }
 
@@ -58,7 +62,7 @@ func (le *client) RemoveLabel(key string) {
 		metaV1.PatchOptions{},
 	)
 	if err != nil {
-		logger.Log.Error("failed to remove label: %v", err)
+		le.logger.Error("failed to remove label: %v", err)
 	}
 }
 
@@ -77,11 +81,10 @@ func getNamespace() string {
 	return DefaultNamespace
 }
 
-func (le *client) setIdentity() {
+func (le *client) setIdentity() error {
 	hostname, err := os.Hostname()
 	if err != nil {
-		logger.Log.Error("error while getting hostname: %v", err)
-		panic(err)
+		return fmt.Errorf("error while getting hostname: %w", err)
 	}
 
 	var podIP string
@@ -98,8 +101,7 @@ func (le *client) setIdentity() {
 				metaV1.GetOptions{},
 			)
 			if err != nil {
-				logger.Log.Error("error while getting pod: %v", err)
-				panic(err)
+				return fmt.Errorf("error while getting pod: %w", err)
 			}
 
 			if pod.Status.PodIP != "" {
@@ -110,13 +112,11 @@ func (le *client) setIdentity() {
 			tries++
 
 			if tries > 10 {
-				err := errors.New("after 10 tries, pod ip is still empty")
-				logger.Log.Error("failed to get pod ip: %v", err)
-				panic(err)
-			} else {
-				logger.Log.Debug("pod ip is empty, waiting...")
+				return ErrPodIPNotFound
 			}
 
+			le.logger.Debug("pod ip is empty, waiting...")
+
 			time.Sleep(time.Second)
 		}
 	}
@@ -126,29 +126,38 @@ func (le *client) setIdentity() {
 		Name:            hostname,
 		ClusterJoinTime: time.Now().UnixNano(),
 	}
+
+	return nil
 }
 
 func (le *client) GetIdentity() *models.Identity {
 	return le.myIdentity
 }
 
-func NewClient() Client {
+func NewClient(logger logger.Logger) (Client, error) {
 	kubernetesConfig, err := rest.InClusterConfig()
 	if err != nil {
-		logger.Log.Error("failed to get kubernetes config: %v", err)
-		panic(err)
+		return nil, fmt.Errorf("failed to get kubernetes config: %w", err)
 	}
 
 	namespace := getNamespace()
 
-	logger.Log.Debug("kubernetes namespace: %s", namespace)
+	logger.Debug("kubernetes namespace: %s", namespace)
+
+	kubernetesClientSet, err := clientSet.NewForConfig(kubernetesConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
 
 	client := &client{
-		clientSet: clientSet.NewForConfigOrDie(kubernetesConfig),
+		clientSet: kubernetesClientSet,
 		namespace: namespace,
+		logger:    logger,
 	}
 
-	client.setIdentity()
+	if err := client.setIdentity(); err != nil {
+		return nil, err
+	}
 
-	return client
+	return client, nil
 }
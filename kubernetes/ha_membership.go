@@ -1,6 +1,8 @@
 package kubernetes
 
 import (
+	"fmt"
+
 	"github.com/Trendyol/go-dcp/config"
 	"github.com/Trendyol/go-dcp/helpers"
 	"github.com/Trendyol/go-dcp/logger"
@@ -9,6 +11,7 @@ import (
 )
 
 type haMembership struct {
+	logger   logger.Logger
 	info     *membership.Model
 	infoChan chan *membership.Model
 	bus      EventBus.Bus
@@ -25,7 +28,7 @@ func (h *haMembership) GetInfo() *membership.Model {
 func (h *haMembership) Close() {
 	err := h.bus.Unsubscribe(helpers.MembershipChangedBusEventName, h.membershipChangedListener)
 	if err != nil {
-		logger.Log.Error("error while unsubscribe: %v", err)
+		h.logger.Error("error while unsubscribe: %v", err)
 	}
 }
 
@@ -36,17 +39,17 @@ func (h *haMembership) membershipChangedListener(model *membership.Model) {
 	}()
 }
 
-func NewHaMembership(_ *config.Dcp, bus EventBus.Bus) membership.Membership {
+func NewHaMembership(_ *config.Dcp, bus EventBus.Bus, logger logger.Logger) (membership.Membership, error) {
 	ham := &haMembership{
+		logger:   logger,
 		infoChan: make(chan *membership.Model),
 		bus:      bus,
 	}
 
 	err := bus.SubscribeAsync(helpers.MembershipChangedBusEventName, ham.membershipChangedListener, true)
 	if err != nil {
-		logger.Log.Error("error while subscribe membership changed event: %v", err)
-		panic(err)
+		return nil, fmt.Errorf("error while subscribe membership changed event: %w", err)
 	}
 
-	return ham
+	return ham, nil
 }
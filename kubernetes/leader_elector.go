@@ -22,6 +22,7 @@ import (
 )
 
 type leaderElector struct {
+	logger              logger.Logger
 	client              Client
 	handler             leaderelector.Handler
 	bus                 EventBus.Bus
@@ -29,58 +30,73 @@ type leaderElector struct {
 	leaderElectorConfig *config.KubernetesLeaderElector
 }
 
-func (le *leaderElector) Run(ctx context.Context) {
+func (le *leaderElector) Run(ctx context.Context) error {
 	callback := leaderelection.LeaderCallbacks{
 		OnStartedLeading: func(c context.Context) {
-			logger.Log.Debug("granted to leader")
+			le.logger.Debug("granted to leader")
 
 			le.client.AddLabel("role", "leader")
 			le.handler.OnBecomeLeader()
 		},
 		OnStoppedLeading: func() {
-			logger.Log.Debug("revoked from leader")
+			le.logger.Debug("revoked from leader")
 
 			le.client.RemoveLabel("role")
 			le.handler.OnResignLeader()
 		},
 		OnNewLeader: func(leaderIdentityStr string) {
-			leaderIdentity := models.NewIdentityFromStr(leaderIdentityStr)
+			leaderIdentity, err := models.NewIdentityFromStr(leaderIdentityStr)
+			if err != nil {
+				le.logger.Error("error while unmarshalling leader identity: %v", err)
+				return
+			}
+
 			if le.myIdentity.Equal(leaderIdentity) {
 				return
 			}
 
-			logger.Log.Debug("granted to follower for leader: %s", leaderIdentity.Name)
+			le.logger.Debug("granted to follower for leader: %s", leaderIdentity.Name)
 
 			le.client.AddLabel("role", "follower")
 			le.handler.OnBecomeFollower(leaderIdentity)
 		},
 	}
 
-	go func() {
-		leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
-			Lock: &resourcelock.LeaseLock{
-				LeaseMeta: v1.ObjectMeta{
-					Name:      le.leaderElectorConfig.LeaseLockName,
-					Namespace: le.leaderElectorConfig.LeaseLockNamespace,
-				},
-				Client: le.client.CoordinationV1(),
-				LockConfig: resourcelock.ResourceLockConfig{
-					Identity: le.myIdentity.String(),
-				},
+	identity, err := le.myIdentity.Marshal()
+	if err != nil {
+		return fmt.Errorf("cannot marshal identity: %w", err)
+	}
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta: v1.ObjectMeta{
+				Name:      le.leaderElectorConfig.LeaseLockName,
+				Namespace: le.leaderElectorConfig.LeaseLockNamespace,
 			},
-			ReleaseOnCancel: true,
-			LeaseDuration:   le.leaderElectorConfig.LeaseDuration,
-			RenewDeadline:   le.leaderElectorConfig.RenewDeadline,
-			RetryPeriod:     le.leaderElectorConfig.RetryPeriod,
-			Callbacks:       callback,
-		})
-	}()
+			Client: le.client.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{
+				Identity: identity,
+			},
+		},
+		ReleaseOnCancel: true,
+		LeaseDuration:   le.leaderElectorConfig.LeaseDuration,
+		RenewDeadline:   le.leaderElectorConfig.RenewDeadline,
+		RetryPeriod:     le.leaderElectorConfig.RetryPeriod,
+		Callbacks:       callback,
+	})
+	if err != nil {
+		return fmt.Errorf("error while creating leader elector: %w", err)
+	}
+
+	go elector.Run(ctx)
+
+	return nil
 }
 
 func (le *leaderElector) Close() {
 	err := le.bus.Unsubscribe(helpers.MembershipChangedBusEventName, le.membershipChangedListener)
 	if err != nil {
-		logger.Log.Error("error while unsubscribe: %v", err)
+		le.logger.Error("error while unsubscribe: %v", err)
 	}
 }
 
@@ -97,20 +113,26 @@ func NewLeaderElector(
 	myIdentity *models.Identity,
 	handler leaderelector.Handler,
 	bus EventBus.Bus,
-) leaderelector.LeaderElector {
+	logger logger.Logger,
+) (leaderelector.LeaderElector, error) {
+	leaderElectorConfig, err := config.GetKubernetesLeaderElector()
+	if err != nil {
+		return nil, err
+	}
+
 	le := &leaderElector{
 		client:              client,
 		myIdentity:          myIdentity,
 		handler:             handler,
-		leaderElectorConfig: config.GetKubernetesLeaderElector(),
+		leaderElectorConfig: leaderElectorConfig,
 		bus:                 bus,
+		logger:              logger,
 	}
 
-	err := bus.SubscribeAsync(helpers.MembershipChangedBusEventName, le.membershipChangedListener, true)
+	err = bus.SubscribeAsync(helpers.MembershipChangedBusEventName, le.membershipChangedListener, true)
 	if err != nil {
-		logger.Log.Error("cannot subscribe to membership changed event: %v", err)
-		panic(err)
+		return nil, fmt.Errorf("cannot subscribe to membership changed event: %w", err)
 	}
 
-	return le
+	return le, nil
 }
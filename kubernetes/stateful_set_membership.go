@@ -1,6 +1,7 @@
 package kubernetes
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -8,10 +9,11 @@ import (
 
 	"github.com/Trendyol/go-dcp/config"
 
-	"github.com/Trendyol/go-dcp/logger"
 	"github.com/Trendyol/go-dcp/membership"
 )
 
+var ErrMemberNumberOutOfRange = errors.New("memberNumber is greater than totalMembers")
+
 type statefulSetMembership struct {
 	info *membership.Model
 }
@@ -43,19 +45,19 @@ func getPodOrdinalFromHostname() (int, error) {
 	return podOrdinal, nil
 }
 
-func NewStatefulSetMembership(config *config.Dcp) membership.Membership {
+func NewStatefulSetMembership(config *config.Dcp) (membership.Membership, error) {
 	podOrdinal, err := getPodOrdinalFromHostname()
 	if err != nil {
-		logger.Log.Error("error while get pod ordinal from hostname: %v", err)
-		panic(err)
+		return nil, fmt.Errorf("error while get pod ordinal from hostname: %w", err)
 	}
 
 	memberNumber := podOrdinal + 1
 
 	if memberNumber > config.Dcp.Group.Membership.TotalMembers {
-		err := fmt.Errorf("memberNumber is greater than totalMembers")
-		logger.Log.Error("memberNumber: %v, totalMembers: %v, err: %v", memberNumber, config.Dcp.Group.Membership.TotalMembers, err)
-		panic(err)
+		return nil, fmt.Errorf(
+			"memberNumber: %v, totalMembers: %v, err: %w",
+			memberNumber, config.Dcp.Group.Membership.TotalMembers, ErrMemberNumberOutOfRange,
+		)
 	}
 
 	return &statefulSetMembership{
@@ -63,5 +65,5 @@ func NewStatefulSetMembership(config *config.Dcp) membership.Membership {
 			MemberNumber: memberNumber,
 			TotalMembers: config.Dcp.Group.Membership.TotalMembers,
 		},
-	}
+	}, nil
 }
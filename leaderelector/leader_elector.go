@@ -7,7 +7,7 @@ import (
 )
 
 type LeaderElector interface {
-	Run(ctx context.Context)
+	Run(ctx context.Context) error
 	Close()
 }
 
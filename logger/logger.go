@@ -16,6 +16,8 @@ const (
 	TRACE = "TRACE"
 )
 
+const badKey = "!BADKEY"
+
 type Logger interface {
 	Trace(message string, args ...interface{})
 	Debug(message string, args ...interface{})
@@ -23,10 +25,18 @@ type Logger interface {
 	Warn(message string, args ...interface{})
 	Error(message string, args ...interface{})
 	Log(level string, message string, args ...interface{})
+	// With returns a logger adding the key-value pairs as fields to every message, like With("vbId", 12).
+	With(keysAndValues ...interface{}) Logger
+}
+
+// LevelSetter is implemented by loggers whose level can be changed at runtime, like on a config reload.
+type LevelSetter interface {
+	SetLevel(level string) error
 }
 
 type Loggers struct {
 	Logrus *logrus.Logger
+	fields logrus.Fields
 }
 
 func (loggers *Loggers) Trace(message string, args ...interface{}) {
@@ -51,15 +61,65 @@ func (loggers *Loggers) Error(message string, args ...interface{}) {
 
 func (loggers *Loggers) Log(level string, message string, args ...interface{}) {
 	logLevel, _ := logrus.ParseLevel(level)
-	loggers.Logrus.Log(logLevel, fmt.Sprintf(message, args...))
+	if len(loggers.fields) == 0 {
+		loggers.Logrus.Log(logLevel, fmt.Sprintf(message, args...))
+		return
+	}
+
+	loggers.Logrus.WithFields(loggers.fields).Log(logLevel, fmt.Sprintf(message, args...))
+}
+
+func (loggers *Loggers) With(keysAndValues ...interface{}) Logger {
+	fields := make(logrus.Fields, len(loggers.fields)+len(keysAndValues)/2)
+
+	for key, value := range loggers.fields {
+		fields[key] = value
+	}
+
+	forEachPair(keysAndValues, func(key string, value interface{}) {
+		fields[key] = value
+	})
+
+	return &Loggers{
+		Logrus: loggers.Logrus,
+		fields: fields,
+	}
 }
 
-func InitDefaultLogger(logLevel string) {
+// forEachPair calls fn with the key-value pairs, a value without a key is passed with the !BADKEY key like slog does.
+func forEachPair(keysAndValues []interface{}, fn func(key string, value interface{})) {
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 == len(keysAndValues) {
+			fn(badKey, keysAndValues[i])
+			return
+		}
+
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+
+		fn(key, keysAndValues[i+1])
+	}
+}
+
+func (loggers *Loggers) SetLevel(level string) error {
+	logLevel, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	loggers.Logrus.SetLevel(logLevel)
+
+	return nil
+}
+
+func InitDefaultLogger(logLevel string) error {
 	logger := logrus.New()
 
 	level, err := logrus.ParseLevel(logLevel)
 	if err != nil {
-		panic(err)
+		return err
 	}
 	logger.SetLevel(level)
 
@@ -73,4 +133,6 @@ func InitDefaultLogger(logLevel string) {
 	Log = &Loggers{
 		Logrus: logger,
 	}
+
+	return nil
 }
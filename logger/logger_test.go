@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestLoggersWith(t *testing.T) {
+	var buffer bytes.Buffer
+
+	logrusLogger := logrus.New()
+	logrusLogger.SetOutput(&buffer)
+	logrusLogger.SetFormatter(&logrus.JSONFormatter{})
+
+	var loggers Logger = &Loggers{Logrus: logrusLogger}
+	loggers.With("group", "orders").With("vbId", 12).Info("stream %s", "opened")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buffer.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if entry["msg"] != "stream opened" || entry["group"] != "orders" || entry["vbId"] != float64(12) {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "stream opened with group and vbId", entry)
+	}
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buffer bytes.Buffer
+
+	l, err := NewSlogLoggerWithLevel(func(level slog.Leveler) slog.Handler {
+		return slog.NewJSONHandler(&buffer, &slog.HandlerOptions{Level: level})
+	}, "info")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Debug("skipped")
+
+	l.With("vbId", 3, "seqNo", uint64(42), "orphan").Warn("lag %d", 5)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buffer.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+
+	if entry["msg"] != "lag 5" || entry["level"] != "WARN" || entry["vbId"] != float64(3) ||
+		entry["seqNo"] != float64(42) || entry[badKey] != "orphan" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "lag 5 with vbId and seqNo", entry)
+	}
+
+	if err := l.(LevelSetter).SetLevel("debug"); err != nil {
+		t.Fatal(err)
+	}
+
+	buffer.Reset()
+	l.Debug("written")
+
+	if buffer.Len() == 0 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "debug message", "nothing")
+	}
+}
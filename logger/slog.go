@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// LevelTrace is the slog level of the TRACE messages, slog has no trace level.
+const LevelTrace = slog.LevelDebug - 4
+
+type slogLogger struct {
+	logger *slog.Logger
+	level  *slog.LevelVar
+}
+
+func (s *slogLogger) Trace(message string, args ...interface{}) {
+	s.log(LevelTrace, message, args...)
+}
+
+func (s *slogLogger) Debug(message string, args ...interface{}) {
+	s.log(slog.LevelDebug, message, args...)
+}
+
+func (s *slogLogger) Info(message string, args ...interface{}) {
+	s.log(slog.LevelInfo, message, args...)
+}
+
+func (s *slogLogger) Warn(message string, args ...interface{}) {
+	s.log(slog.LevelWarn, message, args...)
+}
+
+func (s *slogLogger) Error(message string, args ...interface{}) {
+	s.log(slog.LevelError, message, args...)
+}
+
+func (s *slogLogger) Log(level string, message string, args ...interface{}) {
+	logLevel, err := parseSlogLevel(level)
+	if err != nil {
+		logLevel = slog.LevelInfo
+	}
+
+	s.log(logLevel, message, args...)
+}
+
+func (s *slogLogger) log(level slog.Level, message string, args ...interface{}) {
+	ctx := context.Background()
+	if !s.logger.Enabled(ctx, level) {
+		return
+	}
+
+	if len(args) > 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+
+	s.logger.Log(ctx, level, message)
+}
+
+func (s *slogLogger) With(keysAndValues ...interface{}) Logger {
+	attrs := make([]interface{}, 0, len(keysAndValues))
+
+	forEachPair(keysAndValues, func(key string, value interface{}) {
+		attrs = append(attrs, slog.Any(key, value))
+	})
+
+	return &slogLogger{
+		logger: s.logger.With(attrs...),
+		level:  s.level,
+	}
+}
+
+// SetLevel changes the level of a logger created by NewSlogLoggerWithLevel.
+func (s *slogLogger) SetLevel(level string) error {
+	if s.level == nil {
+		return errors.New("level of the slog logger is not managed, use NewSlogLoggerWithLevel")
+	}
+
+	logLevel, err := parseSlogLevel(level)
+	if err != nil {
+		return err
+	}
+
+	s.level.Set(logLevel)
+
+	return nil
+}
+
+func parseSlogLevel(level string) (slog.Level, error) {
+	switch strings.ToUpper(level) {
+	case TRACE:
+		return LevelTrace, nil
+	case DEBUG:
+		return slog.LevelDebug, nil
+	case INFO:
+		return slog.LevelInfo, nil
+	case WARN, "WARNING":
+		return slog.LevelWarn, nil
+	case ERROR:
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("not a valid level: %q", level)
+	}
+}
+
+// NewSlogLogger adapts a slog logger, messages are formatted with their args and the key-values of With
+// are added as attributes.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return &slogLogger{
+		logger: logger,
+	}
+}
+
+// NewSlogLoggerWithLevel creates a slog logger on handler whose level follows logging.level, also on config reload.
+//
+//	logger.NewSlogLoggerWithLevel(func(level slog.Leveler) slog.Handler {
+//		return slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+//	}, "info")
+func NewSlogLoggerWithLevel(handler func(level slog.Leveler) slog.Handler, level string) (Logger, error) {
+	logLevel, err := parseSlogLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	levelVar := &slog.LevelVar{}
+	levelVar.Set(logLevel)
+
+	return &slogLogger{
+		logger: slog.New(handler(levelVar)),
+		level:  levelVar,
+	}, nil
+}
@@ -1,5 +1,7 @@
 package membership
 
+import "context"
+
 type Membership interface {
 	GetInfo() *Model
 	Close()
@@ -12,9 +14,52 @@ const (
 	KubernetesHaMembershipType          = "kubernetesHa"
 )
 
+// Watcher is implemented by memberships which change at runtime, changed is called with every new assignment
+// to rebalance the streams.
+type Watcher interface {
+	Watch(changed func(model *Model))
+}
+
+// EpochStore keeps the fencing token of the group outside of the members, so it does not depend on their clocks.
+type EpochStore interface {
+	// Next returns the epoch of the assignment of members, which are given in the order of their member numbers.
+	// The stored epoch is increased only when members differ from the assignment it belongs to.
+	Next(ctx context.Context, members []string) (uint64, error)
+	// Current returns the stored epoch and the members of its assignment.
+	Current(ctx context.Context) (uint64, []string, error)
+}
+
+// MemberLister is implemented by memberships that know about the other members of the group.
+type MemberLister interface {
+	GetMembers() []Member
+}
+
+// EpochProvider is implemented by memberships whose fencing token moves forward without publishing a new model.
+type EpochProvider interface {
+	// LatestEpoch returns the latest epoch of the assignment of model.
+	LatestEpoch(model *Model) uint64
+}
+
+type Member struct {
+	Offsets           map[uint16]uint64 `json:"offsets,omitempty"`
+	Name              string            `json:"name"`
+	IP                string            `json:"ip,omitempty"`
+	ClusterJoinTime   int64             `json:"clusterJoinTime"`
+	LastHeartbeat     int64             `json:"lastHeartbeat"`
+	Epoch             uint64            `json:"epoch,omitempty"`
+	MemberNumber      int               `json:"memberNumber"`
+	TotalMembers      int               `json:"totalMembers"`
+	VBucketRangeStart uint16            `json:"vBucketRangeStart"`
+	VBucketRangeEnd   uint16            `json:"vBucketRangeEnd"`
+	Self              bool              `json:"self"`
+	Leader            bool              `json:"leader"`
+}
+
 type Model struct {
 	MemberNumber int
 	TotalMembers int
+	// Epoch is the fencing token of the assignment, zero means checkpoint writes are not fenced.
+	Epoch uint64
 }
 
 func (s *Model) IsChanged(other *Model) bool {
@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"context"
 	"errors"
 	"os"
 
@@ -10,8 +11,6 @@ import (
 
 	"github.com/Trendyol/go-dcp/models"
 
-	"github.com/Trendyol/go-dcp/logger"
-
 	"github.com/json-iterator/go"
 )
 
@@ -19,13 +18,13 @@ type fileMetadata struct { //nolint:unused
 	fileName string
 }
 
-func (s *fileMetadata) Save(state map[uint16]*models.CheckpointDocument, _ map[uint16]bool, _ string) error { //nolint:unused
+func (s *fileMetadata) Save(_ context.Context, state map[uint16]*models.CheckpointDocument, _ map[uint16]bool, _ string) error { //nolint:unused
 	file, _ := jsoniter.MarshalIndent(state, "", "  ")
 	_ = os.WriteFile(s.fileName, file, 0o644) //nolint:gosec
 	return nil
 }
 
-func (s *fileMetadata) Load(vbIds []uint16, bucketUUID string) (*wrapper.ConcurrentSwissMap[uint16, *models.CheckpointDocument], bool, error) { //nolint:lll,unused
+func (s *fileMetadata) Load(_ context.Context, vbIds []uint16, bucketUUID string) (*wrapper.ConcurrentSwissMap[uint16, *models.CheckpointDocument], bool, error) { //nolint:lll,unused
 	file, err := os.ReadFile(s.fileName)
 
 	state := wrapper.CreateConcurrentSwissMap[uint16, *models.CheckpointDocument](1024)
@@ -48,19 +47,22 @@ func (s *fileMetadata) Load(vbIds []uint16, bucketUUID string) (*wrapper.Concurr
 	return state, exist, nil
 }
 
-func (s *fileMetadata) Clear(_ []uint16) error { //nolint:unused
+func (s *fileMetadata) Clear(_ context.Context, _ []uint16) error { //nolint:unused
 	_ = os.Remove(s.fileName)
 	return nil
 }
 
-func NewFSMetadata(config *config.Dcp) Metadata { //nolint:unused
+func NewFSMetadata(config *config.Dcp) (Metadata, error) { //nolint:unused
 	if !config.IsFileMetadata() {
-		err := errors.New("unsupported metadata type")
-		logger.Log.Error("cannot initialize file metadata: %s", err)
-		panic(err)
+		return nil, ErrUnsupportedMetadataType
 	}
 
-	return &fileMetadata{
-		fileName: config.GetFileMetadata(),
+	fileName, err := config.GetFileMetadata()
+	if err != nil {
+		return nil, err
 	}
+
+	return &fileMetadata{
+		fileName: fileName,
+	}, nil
 }
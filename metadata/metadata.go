@@ -1,12 +1,21 @@
 package metadata
 
 import (
+	"context"
+	"errors"
+
 	"github.com/Trendyol/go-dcp/models"
 	"github.com/Trendyol/go-dcp/wrapper"
 )
 
+var ErrUnsupportedMetadataType = errors.New("unsupported metadata type")
+
+// ErrStaleFencingToken is returned by Save when a newer owner of the vBucket has already written its checkpoint.
+var ErrStaleFencingToken = errors.New("stale fencing token")
+
+// Metadata stores checkpoints, the context carries the deadline and the trace span of the operation.
 type Metadata interface {
-	Save(state map[uint16]*models.CheckpointDocument, dirtyOffsets map[uint16]bool, bucketUUID string) error
-	Load(vbIds []uint16, bucketUUID string) (*wrapper.ConcurrentSwissMap[uint16, *models.CheckpointDocument], bool, error)
-	Clear(vbIds []uint16) error
+	Save(ctx context.Context, state map[uint16]*models.CheckpointDocument, dirtyOffsets map[uint16]bool, bucketUUID string) error
+	Load(ctx context.Context, vbIds []uint16, bucketUUID string) (*wrapper.ConcurrentSwissMap[uint16, *models.CheckpointDocument], bool, error) //nolint:lll
+	Clear(ctx context.Context, vbIds []uint16) error
 }
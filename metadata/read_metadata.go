@@ -1,6 +1,8 @@
 package metadata
 
 import (
+	"context"
+
 	"github.com/Trendyol/go-dcp/models"
 	"github.com/Trendyol/go-dcp/wrapper"
 )
@@ -9,18 +11,19 @@ type readMetadata struct {
 	metadata Metadata
 }
 
-func (s *readMetadata) Save(_ map[uint16]*models.CheckpointDocument, _ map[uint16]bool, _ string) error {
+func (s *readMetadata) Save(_ context.Context, _ map[uint16]*models.CheckpointDocument, _ map[uint16]bool, _ string) error {
 	return nil
 }
 
 func (s *readMetadata) Load(
+	ctx context.Context,
 	vbIds []uint16,
 	bucketUUID string,
 ) (*wrapper.ConcurrentSwissMap[uint16, *models.CheckpointDocument], bool, error) {
-	return s.metadata.Load(vbIds, bucketUUID)
+	return s.metadata.Load(ctx, vbIds, bucketUUID)
 }
 
-func (s *readMetadata) Clear(_ []uint16) error {
+func (s *readMetadata) Clear(_ context.Context, _ []uint16) error {
 	return nil
 }
 
@@ -2,6 +2,7 @@ package metric
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/couchbase/gocbcore/v10"
 
@@ -23,6 +24,9 @@ type metricCollector struct {
 	deletion   *prometheus.Desc
 	expiration *prometheus.Desc
 
+	collectionEvents *prometheus.Desc
+	collectionBytes  *prometheus.Desc
+
 	currentSeqNo *prometheus.Desc
 	startSeqNo   *prometheus.Desc
 	endSeqNo     *prometheus.Desc
@@ -31,8 +35,23 @@ type metricCollector struct {
 	processLatency *prometheus.Desc
 	dcpLatency     *prometheus.Desc
 	rebalance      *prometheus.Desc
+	fenced         *prometheus.Desc
+
+	listenerStalls     *prometheus.Desc
+	listenerLastAckAge *prometheus.Desc
+
+	processLatencySeconds *prometheus.Desc
+	dcpLatencySeconds     *prometheus.Desc
+	queueWaitSeconds      *prometheus.Desc
+	checkpointSaveSeconds *prometheus.Desc
 
-	lag *prometheus.Desc
+	lag           *prometheus.Desc
+	secondsBehind *prometheus.Desc
+
+	highSeqNoSamples        *prometheus.Desc
+	highSeqNoSampleFailures *prometheus.Desc
+	highSeqNoSampleDuration *prometheus.Desc
+	highSeqNoSampleAge      *prometheus.Desc
 
 	activeStream      *prometheus.Desc
 	totalMembers      *prometheus.Desc
@@ -41,9 +60,15 @@ type metricCollector struct {
 	vBucketCount      *prometheus.Desc
 	vBucketRangeStart *prometheus.Desc
 	vBucketRangeEnd   *prometheus.Desc
+	fencingToken      *prometheus.Desc
 
 	offsetWrite        *prometheus.Desc
 	offsetWriteLatency *prometheus.Desc
+
+	membershipJoined        *prometheus.Desc
+	membershipLeft          *prometheus.Desc
+	membershipPruned        *prometheus.Desc
+	membershipPruneConflict *prometheus.Desc
 }
 
 func (s *metricCollector) Describe(ch chan<- *prometheus.Desc) {
@@ -57,7 +82,7 @@ func (s *metricCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	seqNoMap, err := s.client.GetVBucketSeqNos()
+	s.collectLags(ch)
 
 	observer.GetPersistSeqNo().Range(func(vbID uint16, seqNo gocbcore.SeqNo) bool {
 		ch <- prometheus.MustNewConstMetric(
@@ -119,26 +144,6 @@ func (s *metricCollector) Collect(ch chan<- prometheus.Metric) {
 			strconv.Itoa(int(vbID)),
 		)
 
-		var lag float64
-
-		if seqNoMap[vbID] > offset.SeqNo {
-			lag = float64(seqNoMap[vbID] - offset.SeqNo)
-		}
-
-		if err != nil {
-			ch <- prometheus.NewInvalidMetric(
-				s.lag,
-				err,
-			)
-		} else {
-			ch <- prometheus.MustNewConstMetric(
-				s.lag,
-				prometheus.GaugeValue,
-				lag,
-				strconv.Itoa(int(vbID)),
-			)
-		}
-
 		return true
 	})
 
@@ -172,6 +177,33 @@ func (s *metricCollector) Collect(ch chan<- prometheus.Metric) {
 		[]string{}...,
 	)
 
+	ch <- prometheus.MustNewConstMetric(
+		s.fenced,
+		prometheus.CounterValue,
+		float64(streamMetric.Fenced.Load()),
+		[]string{}...,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		s.listenerStalls,
+		prometheus.CounterValue,
+		float64(streamMetric.ListenerStalls.Load()),
+		[]string{}...,
+	)
+
+	// it is known only while the listener watchdog is enabled
+	if health := s.stream.GetHealth(); health.SecondsSinceLastAck > 0 {
+		ch <- prometheus.MustNewConstMetric(
+			s.listenerLastAckAge,
+			prometheus.GaugeValue,
+			health.SecondsSinceLastAck,
+			[]string{}...,
+		)
+	}
+
+	s.collectHistograms(ch, streamMetric)
+	s.collectCollectionMetrics(ch, streamMetric.CollectionMetrics)
+
 	vBucketDiscoveryMetric := s.vBucketDiscovery.GetMetric()
 
 	ch <- prometheus.MustNewConstMetric(
@@ -216,6 +248,13 @@ func (s *metricCollector) Collect(ch chan<- prometheus.Metric) {
 		[]string{}...,
 	)
 
+	ch <- prometheus.MustNewConstMetric(
+		s.fencingToken,
+		prometheus.GaugeValue,
+		float64(vBucketDiscoveryMetric.Epoch),
+		[]string{}...,
+	)
+
 	checkpointMetric := s.stream.GetCheckpointMetric()
 
 	ch <- prometheus.MustNewConstMetric(
@@ -231,10 +270,159 @@ func (s *metricCollector) Collect(ch chan<- prometheus.Metric) {
 		float64(checkpointMetric.OffsetWriteLatency),
 		[]string{}...,
 	)
+
+	if ms, ok := s.vBucketDiscovery.GetMembership().(couchbase.MembershipMetricProvider); ok {
+		s.collectMembershipMetric(ch, ms.GetMetric())
+	}
+}
+
+// collectLags reads the lags from the latest high seq no sample, they are left out while there is no fresh sample.
+func (s *metricCollector) collectLags(ch chan<- prometheus.Metric) {
+	sampler := s.stream.GetHighSeqNoSampler()
+	samplerMetric := sampler.GetMetric()
+
+	ch <- prometheus.MustNewConstMetric(
+		s.highSeqNoSamples,
+		prometheus.CounterValue,
+		float64(samplerMetric.Samples.Load()),
+		[]string{}...,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		s.highSeqNoSampleFailures,
+		prometheus.CounterValue,
+		float64(samplerMetric.Failures.Load()),
+		[]string{}...,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		s.highSeqNoSampleDuration,
+		prometheus.GaugeValue,
+		time.Duration(samplerMetric.LastDuration.Load()).Seconds(),
+		[]string{}...,
+	)
+
+	if highSeqNos, _ := sampler.Get(); highSeqNos != nil {
+		ch <- prometheus.MustNewConstMetric(
+			s.highSeqNoSampleAge,
+			prometheus.GaugeValue,
+			time.Since(highSeqNos.SampledAt).Seconds(),
+			[]string{}...,
+		)
+	}
+
+	lags, err := s.stream.GetLags()
+	if err != nil {
+		return
+	}
+
+	for _, lag := range lags {
+		ch <- prometheus.MustNewConstMetric(
+			s.lag,
+			prometheus.GaugeValue,
+			float64(lag.SeqNoLag),
+			strconv.Itoa(int(lag.VbID)),
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			s.secondsBehind,
+			prometheus.GaugeValue,
+			lag.SecondsBehind,
+			strconv.Itoa(int(lag.VbID)),
+		)
+	}
+}
+
+func (s *metricCollector) collectCollectionMetrics(ch chan<- prometheus.Metric, collectionMetrics *couchbase.CollectionMetrics) {
+	if collectionMetrics == nil {
+		return
+	}
+
+	collectionMetrics.Range(func(key couchbase.CollectionMetricKey, metric *couchbase.CollectionMetric) {
+		ch <- prometheus.MustNewConstMetric(
+			s.collectionEvents,
+			prometheus.CounterValue,
+			float64(metric.Events.Load()),
+			key.Scope, key.Collection, key.EventType,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			s.collectionBytes,
+			prometheus.CounterValue,
+			float64(metric.Bytes.Load()),
+			key.Scope, key.Collection, key.EventType,
+		)
+	})
+}
+
+func (s *metricCollector) collectHistograms(ch chan<- prometheus.Metric, streamMetric *stream.Metric) {
+	collectionHistograms := []struct {
+		desc       *prometheus.Desc
+		histograms *stream.CollectionHistograms
+	}{
+		{s.processLatencySeconds, streamMetric.ProcessLatencies},
+		{s.dcpLatencySeconds, streamMetric.DcpLatencies},
+		{s.queueWaitSeconds, streamMetric.QueueWaits},
+	}
+
+	for _, collectionHistogram := range collectionHistograms {
+		if collectionHistogram.histograms == nil {
+			continue
+		}
+
+		desc := collectionHistogram.desc
+
+		collectionHistogram.histograms.Range(func(collectionName string, histogram *stream.Histogram) {
+			count, sum, buckets := histogram.Snapshot()
+			ch <- prometheus.MustNewConstHistogram(desc, count, sum, buckets, collectionName)
+		})
+	}
+
+	if streamMetric.CheckpointSaveLatency != nil {
+		count, sum, buckets := streamMetric.CheckpointSaveLatency.Snapshot()
+		ch <- prometheus.MustNewConstHistogram(s.checkpointSaveSeconds, count, sum, buckets)
+	}
+}
+
+func (s *metricCollector) collectMembershipMetric(ch chan<- prometheus.Metric, membershipMetric *couchbase.MembershipMetric) {
+	ch <- prometheus.MustNewConstMetric(
+		s.membershipJoined,
+		prometheus.CounterValue,
+		float64(membershipMetric.Joined.Load()),
+		[]string{}...,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		s.membershipLeft,
+		prometheus.CounterValue,
+		float64(membershipMetric.Left.Load()),
+		[]string{}...,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		s.membershipPruned,
+		prometheus.CounterValue,
+		float64(membershipMetric.Pruned.Load()),
+		[]string{}...,
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		s.membershipPruneConflict,
+		prometheus.CounterValue,
+		float64(membershipMetric.PruneConflict.Load()),
+		[]string{}...,
+	)
 }
 
+// NewMetricCollector creates the collector of a stream, labels are added to its metrics to tell sources apart.
+//
 //nolint:funlen
-func NewMetricCollector(client couchbase.Client, stream stream.Stream, vBucketDiscovery stream.VBucketDiscovery) *metricCollector {
+func NewMetricCollector(
+	client couchbase.Client,
+	stream stream.Stream,
+	vBucketDiscovery stream.VBucketDiscovery,
+	labels prometheus.Labels,
+) *metricCollector {
 	return &metricCollector{
 		stream:           stream,
 		client:           client,
@@ -244,121 +432,235 @@ func NewMetricCollector(client couchbase.Client, stream stream.Stream, vBucketDi
 			prometheus.BuildFQName(helpers.Name, "mutation", "total"),
 			"Mutation count",
 			[]string{"vbId"},
-			nil,
+			labels,
 		),
 		deletion: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "deletion", "total"),
 			"Deletion count",
 			[]string{"vbId"},
-			nil,
+			labels,
 		),
 		expiration: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "expiration", "total"),
 			"Expiration count",
 			[]string{"vbId"},
-			nil,
+			labels,
+		),
+		collectionEvents: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "collection_event", "total"),
+			"Event count per collection and event type",
+			[]string{"scope", "collection", "type"},
+			labels,
+		),
+		collectionBytes: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "collection_bytes", "total"),
+			"Key and value bytes per collection and event type",
+			[]string{"scope", "collection", "type"},
+			labels,
 		),
 		currentSeqNo: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "seq_no", "current"),
 			"Current seq no",
 			[]string{"vbId"},
-			nil,
+			labels,
 		),
 		startSeqNo: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "start_seq_no", "current"),
 			"Start seq no",
 			[]string{"vbId"},
-			nil,
+			labels,
 		),
 		endSeqNo: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "end_seq_no", "current"),
 			"End seq no",
 			[]string{"vbId"},
-			nil,
+			labels,
 		),
 		persistSeqNo: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "persist_seq_no", "current"),
 			"Persist seq no",
 			[]string{"vbId"},
-			nil,
+			labels,
 		),
 		lag: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "lag", "current"),
 			"Lag",
 			[]string{"vbId"},
-			nil,
+			labels,
+		),
+		secondsBehind: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "seconds_behind", "current"),
+			"Seconds the acked events are behind the high seq no",
+			[]string{"vbId"},
+			labels,
+		),
+		highSeqNoSamples: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "high_seq_no_sample", "total"),
+			"High seq no sample count",
+			[]string{},
+			labels,
+		),
+		highSeqNoSampleFailures: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "high_seq_no_sample_failure", "total"),
+			"Failed high seq no sample count",
+			[]string{},
+			labels,
+		),
+		highSeqNoSampleDuration: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "high_seq_no_sample_duration", "seconds"),
+			"Seconds the latest high seq no sample took",
+			[]string{},
+			labels,
+		),
+		highSeqNoSampleAge: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "high_seq_no_sample_age", "seconds"),
+			"Seconds since the latest successful high seq no sample",
+			[]string{},
+			labels,
 		),
 		processLatency: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "process_latency_ms", "current"),
 			"Average process latency ms",
 			[]string{},
-			nil,
+			labels,
 		),
 		dcpLatency: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "dcp_latency_ms", "current"),
 			"Latest consumed dcp message latency ms",
 			[]string{},
-			nil,
+			labels,
+		),
+		processLatencySeconds: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "process_latency", "seconds"),
+			"Seconds the listener takes to process an event",
+			[]string{"collection"},
+			labels,
+		),
+		dcpLatencySeconds: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "dcp_latency", "seconds"),
+			"Seconds from the event time to its receipt",
+			[]string{"collection"},
+			labels,
+		),
+		queueWaitSeconds: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "listener_queue_wait", "seconds"),
+			"Seconds an event waits for the listener",
+			[]string{"collection"},
+			labels,
+		),
+		checkpointSaveSeconds: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "checkpoint_save", "seconds"),
+			"Seconds a checkpoint save takes",
+			[]string{},
+			labels,
 		),
 		rebalance: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "rebalance", "current"),
 			"Rebalance count",
 			[]string{},
-			nil,
+			labels,
+		),
+		fenced: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "fenced", "total"),
+			"Times streams are stopped because of a stale fencing token",
+			[]string{},
+			labels,
+		),
+		listenerStalls: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "listener_stall", "total"),
+			"Times the listener watchdog detects a stalled listener",
+			[]string{},
+			labels,
+		),
+		listenerLastAckAge: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "listener_last_ack_age", "seconds"),
+			"Seconds since the listener acked an event",
+			[]string{},
+			labels,
 		),
 		activeStream: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "active_stream", "current"),
 			"Active stream",
 			[]string{},
-			nil,
+			labels,
 		),
 		totalMembers: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "total_members", "current"),
 			"Total members",
 			[]string{},
-			nil,
+			labels,
 		),
 		memberNumber: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "member_number", "current"),
 			"Member number",
 			[]string{},
-			nil,
+			labels,
 		),
 		membershipType: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "membership_type", "current"),
 			"Membership type",
 			[]string{"type"},
-			nil,
+			labels,
 		),
 		vBucketCount: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "vbucket_count", "current"),
 			"VBucket count",
 			[]string{},
-			nil,
+			labels,
 		),
 		vBucketRangeStart: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "vbucket_range_start", "current"),
 			"VBucket range start",
 			[]string{},
-			nil,
+			labels,
 		),
 		vBucketRangeEnd: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "vbucket_range_end", "current"),
 			"VBucket range end",
 			[]string{},
-			nil,
+			labels,
+		),
+		fencingToken: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "fencing_token", "current"),
+			"Fencing token of the current vBucket assignment",
+			[]string{},
+			labels,
 		),
 		offsetWrite: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "offset_write", "current"),
 			"Average offset write",
 			[]string{},
-			nil,
+			labels,
 		),
 		offsetWriteLatency: prometheus.NewDesc(
 			prometheus.BuildFQName(helpers.Name, "offset_write_latency_ms", "current"),
 			"Average offset write latency ms",
 			[]string{},
-			nil,
+			labels,
+		),
+		membershipJoined: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "membership_joined", "total"),
+			"Membership joined instance count",
+			[]string{},
+			labels,
+		),
+		membershipLeft: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "membership_left", "total"),
+			"Membership left instance count",
+			[]string{},
+			labels,
+		),
+		membershipPruned: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "membership_pruned", "total"),
+			"Membership pruned stale instance count",
+			[]string{},
+			labels,
+		),
+		membershipPruneConflict: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "membership_prune_conflict", "total"),
+			"Membership index prune cas conflict count",
+			[]string{},
+			labels,
 		),
 	}
 }
@@ -0,0 +1,102 @@
+package metric
+
+import (
+	"strconv"
+
+	"github.com/Trendyol/go-dcp/helpers"
+	"github.com/Trendyol/go-dcp/stream"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type membershipCollector struct {
+	vBucketDiscovery stream.VBucketDiscovery
+
+	info              *prometheus.Desc
+	clusterJoinTime   *prometheus.Desc
+	lastHeartbeat     *prometheus.Desc
+	vBucketRangeStart *prometheus.Desc
+	vBucketRangeEnd   *prometheus.Desc
+}
+
+func (s *membershipCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(s, ch)
+}
+
+func (s *membershipCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, member := range s.vBucketDiscovery.GetMembers() {
+		memberNumber := strconv.Itoa(member.MemberNumber)
+
+		ch <- prometheus.MustNewConstMetric(
+			s.info,
+			prometheus.GaugeValue,
+			1,
+			memberNumber, member.Name, member.IP, strconv.FormatBool(member.Self), strconv.FormatBool(member.Leader),
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			s.clusterJoinTime,
+			prometheus.GaugeValue,
+			float64(member.ClusterJoinTime)/1e9,
+			memberNumber, member.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			s.lastHeartbeat,
+			prometheus.GaugeValue,
+			float64(member.LastHeartbeat)/1e9,
+			memberNumber, member.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			s.vBucketRangeStart,
+			prometheus.GaugeValue,
+			float64(member.VBucketRangeStart),
+			memberNumber, member.Name,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			s.vBucketRangeEnd,
+			prometheus.GaugeValue,
+			float64(member.VBucketRangeEnd),
+			memberNumber, member.Name,
+		)
+	}
+}
+
+func NewMembershipCollector(vBucketDiscovery stream.VBucketDiscovery) prometheus.Collector {
+	return &membershipCollector{
+		vBucketDiscovery: vBucketDiscovery,
+
+		info: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "group_member", "info"),
+			"Group member info",
+			[]string{"memberNumber", "name", "ip", "self", "leader"},
+			nil,
+		),
+		clusterJoinTime: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "group_member_cluster_join", "timestamp_seconds"),
+			"Group member cluster join time",
+			[]string{"memberNumber", "name"},
+			nil,
+		),
+		lastHeartbeat: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "group_member_last_heartbeat", "timestamp_seconds"),
+			"Group member last heartbeat time",
+			[]string{"memberNumber", "name"},
+			nil,
+		),
+		vBucketRangeStart: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "group_member_vbucket_range_start", "current"),
+			"Group member vBucket range start",
+			[]string{"memberNumber", "name"},
+			nil,
+		),
+		vBucketRangeEnd: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "group_member_vbucket_range_end", "current"),
+			"Group member vBucket range end",
+			[]string{"memberNumber", "name"},
+			nil,
+		),
+	}
+}
@@ -0,0 +1,63 @@
+package metric
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/Trendyol/go-dcp/membership"
+	"github.com/Trendyol/go-dcp/stream"
+)
+
+type testVBucketDiscovery struct {
+	stream.VBucketDiscovery
+	members []membership.Member
+}
+
+func (s *testVBucketDiscovery) GetMembers() []membership.Member {
+	return s.members
+}
+
+func TestMembershipCollector(t *testing.T) {
+	collector := NewMembershipCollector(&testVBucketDiscovery{
+		members: []membership.Member{
+			{
+				Name: "first", IP: "10.0.0.1", MemberNumber: 1, TotalMembers: 2, Self: true, Leader: true,
+				ClusterJoinTime: 2e9, LastHeartbeat: 5e9, VBucketRangeStart: 0, VBucketRangeEnd: 511,
+			},
+			{
+				Name: "second", IP: "10.0.0.2", MemberNumber: 2, TotalMembers: 2,
+				ClusterJoinTime: 3e9, LastHeartbeat: 6e9, VBucketRangeStart: 512, VBucketRangeEnd: 1023,
+			},
+		},
+	})
+
+	expected := `
+# HELP cbgo_group_member_info Group member info
+# TYPE cbgo_group_member_info gauge
+cbgo_group_member_info{ip="10.0.0.1",leader="true",memberNumber="1",name="first",self="true"} 1
+cbgo_group_member_info{ip="10.0.0.2",leader="false",memberNumber="2",name="second",self="false"} 1
+# HELP cbgo_group_member_vbucket_range_end_current Group member vBucket range end
+# TYPE cbgo_group_member_vbucket_range_end_current gauge
+cbgo_group_member_vbucket_range_end_current{memberNumber="1",name="first"} 511
+cbgo_group_member_vbucket_range_end_current{memberNumber="2",name="second"} 1023
+# HELP cbgo_group_member_last_heartbeat_timestamp_seconds Group member last heartbeat time
+# TYPE cbgo_group_member_last_heartbeat_timestamp_seconds gauge
+cbgo_group_member_last_heartbeat_timestamp_seconds{memberNumber="1",name="first"} 5
+cbgo_group_member_last_heartbeat_timestamp_seconds{memberNumber="2",name="second"} 6
+`
+
+	err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		"cbgo_group_member_info",
+		"cbgo_group_member_vbucket_range_end_current",
+		"cbgo_group_member_last_heartbeat_timestamp_seconds",
+	)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if count := testutil.CollectAndCount(collector); count != 10 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 10, count)
+	}
+}
@@ -0,0 +1,33 @@
+package metric
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Registry is where the collectors of a Dcp instance are registered and gathered from.
+type Registry interface {
+	prometheus.Registerer
+	prometheus.Gatherer
+}
+
+type registry struct {
+	prometheus.Registerer
+	prometheus.Gatherer
+}
+
+// DefaultRegistry returns the process-wide prometheus registry.
+func DefaultRegistry() Registry {
+	return &registry{
+		Registerer: prometheus.DefaultRegisterer,
+		Gatherer:   prometheus.DefaultGatherer,
+	}
+}
+
+// WrapRegistryWith adds labels to every metric registered through the returned registry,
+// so instances sharing a registry can be told apart.
+func WrapRegistryWith(labels prometheus.Labels, reg Registry) Registry {
+	return &registry{
+		Registerer: prometheus.WrapRegistererWith(labels, reg),
+		Gatherer:   reg,
+	}
+}
@@ -0,0 +1,53 @@
+package metric
+
+import (
+	"sync/atomic"
+
+	"github.com/Trendyol/go-dcp/helpers"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ReloadMetric counts the config reloads of a connector.
+type ReloadMetric struct {
+	Succeeded atomic.Int64
+	Rejected  atomic.Int64
+}
+
+type reloadCollector struct {
+	metric *ReloadMetric
+
+	reload *prometheus.Desc
+}
+
+func (s *reloadCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(s, ch)
+}
+
+func (s *reloadCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(
+		s.reload,
+		prometheus.CounterValue,
+		float64(s.metric.Succeeded.Load()),
+		"success",
+	)
+
+	ch <- prometheus.MustNewConstMetric(
+		s.reload,
+		prometheus.CounterValue,
+		float64(s.metric.Rejected.Load()),
+		"rejected",
+	)
+}
+
+func NewReloadCollector(metric *ReloadMetric) prometheus.Collector {
+	return &reloadCollector{
+		metric: metric,
+		reload: prometheus.NewDesc(
+			prometheus.BuildFQName(helpers.Name, "config_reload", "total"),
+			"Config reloads by result",
+			[]string{"result"},
+			nil,
+		),
+	}
+}
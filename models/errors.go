@@ -0,0 +1,18 @@
+package models
+
+// ErrorHandler is called with errors of background operations, the operations are retried and the dcp keeps running.
+type ErrorHandler func(err error)
+
+// OpError is published when a background operation fails after the dcp is started.
+type OpError struct {
+	Err error
+	Op  string
+}
+
+func (e *OpError) Error() string {
+	return e.Op + ": " + e.Err.Error()
+}
+
+func (e *OpError) Unwrap() error {
+	return e.Err
+}
@@ -2,8 +2,6 @@ package models
 
 import (
 	"github.com/json-iterator/go"
-
-	"github.com/Trendyol/go-dcp/logger"
 )
 
 type Identity struct {
@@ -12,28 +10,36 @@ type Identity struct {
 	ClusterJoinTime int64
 }
 
-func (k *Identity) String() string {
+// Marshal returns the identity as the json kept in the leader election lease.
+func (k *Identity) Marshal() (string, error) {
 	str, err := jsoniter.Marshal(k)
 	if err != nil {
-		logger.Log.Error("error while marshalling identity: %v", err)
-		panic(err)
+		return "", err
+	}
+
+	return string(str), nil
+}
+
+func (k *Identity) String() string {
+	str, err := k.Marshal()
+	if err != nil {
+		return k.Name
 	}
 
-	return string(str)
+	return str
 }
 
 func (k *Identity) Equal(other *Identity) bool {
 	return k.IP == other.IP && k.Name == other.Name
 }
 
-func NewIdentityFromStr(str string) *Identity {
+func NewIdentityFromStr(str string) (*Identity, error) {
 	var identity Identity
 
 	err := jsoniter.Unmarshal([]byte(str), &identity)
 	if err != nil {
-		logger.Log.Error("error while unmarshalling identity: %v", err)
-		panic(err)
+		return nil, err
 	}
 
-	return &identity
+	return &identity, nil
 }
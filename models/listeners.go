@@ -1,13 +1,24 @@
 package models
 
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
 type ListenerContext struct {
 	Commit func()
 	Event  interface{}
 	Ack    func()
+	// SpanContext is the span of the event when tracing is enabled, sinks can propagate it with
+	// trace.ContextWithSpanContext. It is invalid when tracing is disabled.
+	SpanContext trace.SpanContext
 }
 
 type ListenerArgs struct {
 	Event interface{}
+	// ReceivedAt is when the observer received the event, before it is queued for the listener.
+	ReceivedAt time.Time
 }
 
 type DcpStreamEndContext struct {
@@ -27,6 +27,7 @@ type InternalDcpMutation struct {
 	*gocbcore.DcpMutation
 	Offset         *Offset
 	CollectionName string
+	BucketName     string
 }
 
 type InternalDcpDeletion struct {
@@ -34,6 +35,7 @@ type InternalDcpDeletion struct {
 	*gocbcore.DcpDeletion
 	Offset         *Offset
 	CollectionName string
+	BucketName     string
 }
 
 type InternalDcpExpiration struct {
@@ -41,6 +43,7 @@ type InternalDcpExpiration struct {
 	*gocbcore.DcpExpiration
 	Offset         *Offset
 	CollectionName string
+	BucketName     string
 }
 
 type InternalDcpSeqNoAdvance struct {
@@ -85,8 +88,9 @@ type CheckpointDocumentCheckpoint struct {
 }
 
 type CheckpointDocument struct {
-	Checkpoint *CheckpointDocumentCheckpoint `json:"checkpoint"`
-	BucketUUID string                        `json:"bucketUuid"`
+	Checkpoint   *CheckpointDocumentCheckpoint `json:"checkpoint"`
+	BucketUUID   string                        `json:"bucketUuid"`
+	FencingToken uint64                        `json:"fencingToken,omitempty"`
 }
 
 func NewEmptyCheckpointDocument(bucketUUID string) *CheckpointDocument {
@@ -0,0 +1,144 @@
+package dcp
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/logger"
+)
+
+// Reload applies the fields of newConfig listed in config.ReloadableFields to the running connector.
+// A change of any other field rejects the reload as a whole.
+func (s *dcp) Reload(newConfig *config.Dcp) error {
+	_, err := s.reload(newConfig)
+	return err
+}
+
+// reloadFile reloads the config file the connector is created from.
+func (s *dcp) reloadFile() ([]string, error) {
+	c, err := newDcpConfig(s.configPath)
+	if err != nil {
+		s.reloadMetric.Rejected.Add(1)
+		s.logger.Error("config reload is rejected, file: %s, err: %v", s.configPath, err)
+
+		return nil, err
+	}
+
+	return s.reload(&c)
+}
+
+func (s *dcp) reload(newConfig *config.Dcp) ([]string, error) {
+	s.reloadLock.Lock()
+	defer s.reloadLock.Unlock()
+
+	fields, err := s.applyReload(newConfig)
+	if err != nil {
+		s.reloadMetric.Rejected.Add(1)
+		s.logger.Error("config reload is rejected: %v", err)
+
+		return nil, err
+	}
+
+	s.reloadMetric.Succeeded.Add(1)
+
+	if len(fields) == 0 {
+		s.logger.Info("config reloaded, no field is changed")
+	} else {
+		s.logger.Info("config reloaded, changed fields: %v", fields)
+	}
+
+	return fields, nil
+}
+
+func (s *dcp) applyReload(newConfig *config.Dcp) ([]string, error) {
+	if err := newConfig.ApplyDefaults(); err != nil {
+		return nil, err
+	}
+
+	if err := newConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	levelSetter, ok := s.logger.(logger.LevelSetter)
+	if !ok && !s.sharedLogger && newConfig.Logging.Level != s.loadedConfig.Logging.Level {
+		return nil, &config.FieldError{Field: "logging.level", Err: errors.New("logger does not support level changes")}
+	}
+
+	// compared with the config as it is loaded, the running config is changed by the connector, e.g. for ephemeral buckets
+	fields, err := s.loadedConfig.ReloadedFields(newConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// the only change which can fail is applied first, so a rejected reload does not change anything
+	if slices.Contains(fields, "logging.level") {
+		if s.sharedLogger {
+			s.logger.Warn("logging.level is not applied to the shared default logger, set Options.Logger to change it on reload")
+		} else if err := levelSetter.SetLevel(newConfig.Logging.Level); err != nil {
+			return nil, &config.FieldError{Field: "logging.level", Err: err}
+		}
+	}
+
+	s.loadedConfig.CopyReloadableFields(newConfig)
+	s.config.CopyReloadableFields(newConfig)
+
+	for _, source := range s.sources {
+		source.config.CopyReloadableFields(newConfig)
+	}
+
+	for _, field := range fields {
+		switch field {
+		case "checkpoint.interval":
+			if s.stream != nil {
+				for _, stream := range s.streams() {
+					stream.ResetCheckpointSchedule(newConfig.Checkpoint.Interval)
+				}
+			}
+		case "healthCheck.interval":
+			if s.healthCheck != nil {
+				s.healthCheck.Reset(newConfig.HealthCheck.Interval)
+			}
+		case "dcp.listener.bufferSize":
+			s.logger.Info("dcp.listener.bufferSize is applied when streams are opened again")
+		}
+	}
+
+	return fields, nil
+}
+
+// watchConfigFile reloads the config file whenever its modification time changes, until stopCh is closed.
+func (s *dcp) watchConfigFile(stopCh chan struct{}) {
+	var lastModified time.Time
+	if info, err := os.Stat(s.configPath); err == nil {
+		lastModified = info.ModTime()
+	}
+
+	ticker := time.NewTicker(s.config.Reload.Interval)
+	defer ticker.Stop()
+
+	s.logger.Info("watching config file %s for changes", s.configPath)
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			info, err := os.Stat(s.configPath)
+			if err != nil {
+				s.logger.Warn("cannot watch config file %s: %v", s.configPath, err)
+				continue
+			}
+
+			if info.ModTime().Equal(lastModified) {
+				continue
+			}
+
+			lastModified = info.ModTime()
+
+			_, _ = s.reloadFile()
+		}
+	}
+}
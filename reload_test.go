@@ -0,0 +1,116 @@
+package dcp
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/metric"
+)
+
+type testLevelLogger struct {
+	logger.Logger
+	err   error
+	level string
+}
+
+func (l *testLevelLogger) SetLevel(level string) error {
+	l.level = level
+	return l.err
+}
+
+func newTestReloadConfig() *config.Dcp {
+	c := &config.Dcp{
+		Hosts:      []string{"localhost:8091"},
+		Username:   "user",
+		Password:   "password",
+		BucketName: "orders",
+	}
+	c.Dcp.Group.Name = "group"
+	c.Logging.Level = "info"
+
+	_ = c.ApplyDefaults()
+
+	return c
+}
+
+func newTestReloadDcp(levelErr error) *dcp {
+	c := newTestReloadConfig()
+
+	return &dcp{
+		config:       c,
+		loadedConfig: *c,
+		reloadMetric: &metric.ReloadMetric{},
+		logger:       &testLevelLogger{Logger: &logger.Loggers{Logrus: logrus.New()}, err: levelErr},
+	}
+}
+
+func TestReload(t *testing.T) {
+	s := newTestReloadDcp(nil)
+
+	newConfig := newTestReloadConfig()
+	newConfig.Logging.Level = "debug"
+	newConfig.Checkpoint.Interval = time.Minute
+
+	if err := s.Reload(newConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.config.Logging.Level != "debug" || s.config.Checkpoint.GetInterval() != time.Minute {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "reloaded fields", s.config)
+	}
+
+	if s.reloadMetric.Succeeded.Load() != 1 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 1, s.reloadMetric.Succeeded.Load())
+	}
+
+	if level := s.logger.(*testLevelLogger).level; level != "debug" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "debug", level)
+	}
+}
+
+func TestReloadSkipsLevelOfSharedLogger(t *testing.T) {
+	s := newTestReloadDcp(nil)
+	s.sharedLogger = true
+
+	newConfig := newTestReloadConfig()
+	newConfig.Logging.Level = "debug"
+
+	if err := s.Reload(newConfig); err != nil {
+		t.Fatal(err)
+	}
+
+	if level := s.logger.(*testLevelLogger).level; level != "" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "level of shared logger unchanged", level)
+	}
+
+	if s.config.Logging.Level != "debug" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "debug", s.config.Logging.Level)
+	}
+}
+
+func TestReloadRejectedByLevelChangeKeepsConfig(t *testing.T) {
+	s := newTestReloadDcp(errors.New("level is not supported"))
+	interval := s.config.Checkpoint.Interval
+
+	newConfig := newTestReloadConfig()
+	newConfig.Logging.Level = "debug"
+	newConfig.Checkpoint.Interval = interval + time.Minute
+
+	if err := s.Reload(newConfig); err == nil {
+		t.Fatalf("Unexpected result. Expected: %v, Got: %v", "rejected reload", err)
+	}
+
+	if s.config.Logging.Level != "info" || s.config.Checkpoint.Interval != interval ||
+		s.loadedConfig.Logging.Level != "info" || s.loadedConfig.Checkpoint.Interval != interval {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "unchanged config", s.config)
+	}
+
+	if s.reloadMetric.Rejected.Load() != 1 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 1, s.reloadMetric.Rejected.Load())
+	}
+}
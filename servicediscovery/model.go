@@ -1,34 +1,77 @@
 package servicediscovery
 
 import (
+	"context"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/Trendyol/go-dcp/models"
+
+	servicediscoveryv1 "github.com/Trendyol/go-dcp/servicediscovery/proto/v1"
 )
 
-type Register struct {
-	From     *models.Identity
-	Identity *models.Identity
+type Service struct {
+	Client          Client
+	assignments     servicediscoveryv1.ServiceDiscovery_AssignmentsServer
+	cancel          context.CancelFunc
+	sendLock        *sync.Mutex
+	ackLock         *sync.RWMutex
+	sentGeneration  *uint64
+	Offsets         map[uint16]uint64
+	Name            string
+	IP              string
+	ClusterJoinTime int64
+	LastHeartbeat   int64
+	AckedEpoch      uint64
 }
 
-type Ping struct {
-	From *models.Identity
+// assign sends the assignment to the follower over its assignment stream. Assignments are sent concurrently,
+// one of an older generation than the last sent one is skipped so the follower does not go back to it.
+func (s *Service) assign(assignment *servicediscoveryv1.Assignment, generation uint64) error {
+	s.sendLock.Lock()
+	defer s.sendLock.Unlock()
+
+	if generation <= *s.sentGeneration {
+		return nil
+	}
+
+	if err := s.assignments.Send(assignment); err != nil {
+		return err
+	}
+
+	*s.sentGeneration = generation
+
+	return nil
 }
 
-type Pong struct {
-	From *models.Identity
+// close ends the assignment stream of a follower and closes the client of the leader.
+func (s *Service) close() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	if s.Client != nil {
+		_ = s.Client.Close()
+	}
 }
 
-type Rebalance struct {
-	From         *models.Identity
-	MemberNumber int
-	TotalMembers int
+// recordAck is called by the assignment stream goroutine of the follower while the leader reads the service.
+func (s *Service) recordAck(offsets map[uint16]uint64, epoch uint64) {
+	s.ackLock.Lock()
+	defer s.ackLock.Unlock()
+
+	s.Offsets = offsets
+	s.AckedEpoch = epoch
+	s.LastHeartbeat = time.Now().UnixNano()
 }
 
-type Service struct {
-	Client          Client
-	Name            string
-	ClusterJoinTime int64
+// snapshot copies the service without racing with recordAck.
+func (s *Service) snapshot() Service {
+	s.ackLock.RLock()
+	defer s.ackLock.RUnlock()
+
+	return *s
 }
 
 type ServiceBy func(s1, s2 *Service) bool
@@ -58,10 +101,50 @@ func (s *serviceSorter) Less(i, j int) bool {
 	return s.by(&s.services[i], &s.services[j])
 }
 
-func NewService(client Client, name string, clusterJoinTime int64) *Service {
+func NewService(client Client, identity *models.Identity) *Service {
 	return &Service{
 		Client:          client,
-		Name:            name,
-		ClusterJoinTime: clusterJoinTime,
+		Name:            identity.Name,
+		IP:              identity.IP,
+		ClusterJoinTime: identity.ClusterJoinTime,
+		LastHeartbeat:   time.Now().UnixNano(),
+		sendLock:        &sync.Mutex{},
+		ackLock:         &sync.RWMutex{},
+		sentGeneration:  new(uint64),
+	}
+}
+
+func newFollowerService(
+	identity *models.Identity,
+	assignments servicediscoveryv1.ServiceDiscovery_AssignmentsServer,
+) *Service {
+	service := NewService(nil, identity)
+	service.assignments = assignments
+
+	return service
+}
+
+func toIdentity(identity *servicediscoveryv1.Identity) *models.Identity {
+	return &models.Identity{
+		IP:              identity.GetIp(),
+		Name:            identity.GetName(),
+		ClusterJoinTime: identity.GetClusterJoinTime(),
+	}
+}
+
+func toIdentities(identities []*servicediscoveryv1.Identity) []*models.Identity {
+	converted := make([]*models.Identity, 0, len(identities))
+	for _, identity := range identities {
+		converted = append(converted, toIdentity(identity))
+	}
+
+	return converted
+}
+
+func fromIdentity(identity *models.Identity) *servicediscoveryv1.Identity {
+	return &servicediscoveryv1.Identity{
+		Ip:              identity.IP,
+		Name:            identity.Name,
+		ClusterJoinTime: identity.ClusterJoinTime,
 	}
 }
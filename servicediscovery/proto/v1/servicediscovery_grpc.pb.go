@@ -0,0 +1,141 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.1
+// source: v1/servicediscovery.proto
+
+package servicediscoveryv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ServiceDiscovery_Assignments_FullMethodName = "/godcp.servicediscovery.v1.ServiceDiscovery/Assignments"
+)
+
+// ServiceDiscoveryClient is the client API for ServiceDiscovery service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ServiceDiscoveryClient interface {
+	Assignments(ctx context.Context, opts ...grpc.CallOption) (ServiceDiscovery_AssignmentsClient, error)
+}
+
+type serviceDiscoveryClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewServiceDiscoveryClient(cc grpc.ClientConnInterface) ServiceDiscoveryClient {
+	return &serviceDiscoveryClient{cc}
+}
+
+func (c *serviceDiscoveryClient) Assignments(ctx context.Context, opts ...grpc.CallOption) (ServiceDiscovery_AssignmentsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ServiceDiscovery_ServiceDesc.Streams[0], ServiceDiscovery_Assignments_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &serviceDiscoveryAssignmentsClient{stream}
+	return x, nil
+}
+
+type ServiceDiscovery_AssignmentsClient interface {
+	Send(*FollowerMessage) error
+	Recv() (*Assignment, error)
+	grpc.ClientStream
+}
+
+type serviceDiscoveryAssignmentsClient struct {
+	grpc.ClientStream
+}
+
+func (x *serviceDiscoveryAssignmentsClient) Send(m *FollowerMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *serviceDiscoveryAssignmentsClient) Recv() (*Assignment, error) {
+	m := new(Assignment)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ServiceDiscoveryServer is the server API for ServiceDiscovery service.
+// All implementations must embed UnimplementedServiceDiscoveryServer
+// for forward compatibility
+type ServiceDiscoveryServer interface {
+	Assignments(ServiceDiscovery_AssignmentsServer) error
+	mustEmbedUnimplementedServiceDiscoveryServer()
+}
+
+// UnimplementedServiceDiscoveryServer must be embedded to have forward compatible implementations.
+type UnimplementedServiceDiscoveryServer struct {
+}
+
+func (UnimplementedServiceDiscoveryServer) Assignments(ServiceDiscovery_AssignmentsServer) error {
+	return status.Errorf(codes.Unimplemented, "method Assignments not implemented")
+}
+func (UnimplementedServiceDiscoveryServer) mustEmbedUnimplementedServiceDiscoveryServer() {}
+
+// UnsafeServiceDiscoveryServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ServiceDiscoveryServer will
+// result in compilation errors.
+type UnsafeServiceDiscoveryServer interface {
+	mustEmbedUnimplementedServiceDiscoveryServer()
+}
+
+func RegisterServiceDiscoveryServer(s grpc.ServiceRegistrar, srv ServiceDiscoveryServer) {
+	s.RegisterService(&ServiceDiscovery_ServiceDesc, srv)
+}
+
+func _ServiceDiscovery_Assignments_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ServiceDiscoveryServer).Assignments(&serviceDiscoveryAssignmentsServer{stream})
+}
+
+type ServiceDiscovery_AssignmentsServer interface {
+	Send(*Assignment) error
+	Recv() (*FollowerMessage, error)
+	grpc.ServerStream
+}
+
+type serviceDiscoveryAssignmentsServer struct {
+	grpc.ServerStream
+}
+
+func (x *serviceDiscoveryAssignmentsServer) Send(m *Assignment) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *serviceDiscoveryAssignmentsServer) Recv() (*FollowerMessage, error) {
+	m := new(FollowerMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ServiceDiscovery_ServiceDesc is the grpc.ServiceDesc for ServiceDiscovery service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ServiceDiscovery_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "godcp.servicediscovery.v1.ServiceDiscovery",
+	HandlerType: (*ServiceDiscoveryServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Assignments",
+			Handler:       _ServiceDiscovery_Assignments_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "v1/servicediscovery.proto",
+}
@@ -1,128 +1,205 @@
 package servicediscovery
 
 import (
-	"fmt"
-	"net/rpc"
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/Trendyol/go-dcp/helpers"
+	"github.com/Trendyol/go-dcp/config"
 
 	"github.com/Trendyol/go-dcp/models"
 
 	"github.com/Trendyol/go-dcp/logger"
+
+	servicediscoveryv1 "github.com/Trendyol/go-dcp/servicediscovery/proto/v1"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	progressInterval = 5 * time.Second
+	reconnectDelay   = 1 * time.Second
 )
 
 type Client interface {
+	Start()
 	Close() error
-	Ping() error
-	Register() error
 	IsConnected() bool
-	Reconnect() error
-	Rebalance(memberNumber int, totalMembers int) error
+	LastHeartbeat() int64
 }
 
 type client struct {
-	client         *rpc.Client
-	myIdentity     *models.Identity
-	targetIdentity *models.Identity
-	port           int
-	connected      bool
+	logger           logger.Logger
+	serviceDiscovery ServiceDiscovery
+	conn             *grpc.ClientConn
+	myIdentity       *models.Identity
+	targetIdentity   *models.Identity
+	cancel           context.CancelFunc
+	sendLock         *sync.Mutex
+	epoch            atomic.Uint64
+	lastHeartbeat    atomic.Int64
+	connected        atomic.Bool
 }
 
-func (c *client) connect() error {
-	return helpers.Retry(
-		func() error {
-			connectAddress := fmt.Sprintf("%s:%d", c.targetIdentity.IP, c.port)
-			client, err := rpc.Dial("tcp", connectAddress)
-			if err != nil {
-				return err
+func (c *client) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	go func() {
+		for {
+			err := c.watch(ctx)
+			c.connected.Store(false)
+
+			if ctx.Err() != nil {
+				return
 			}
 
-			c.client = client
-			c.connected = true
-			logger.Log.Debug("connected to %s as rpc", c.targetIdentity.Name)
+			c.logger.Error("assignment stream to %s is broken, err: %v", c.targetIdentity.Name, err)
 
-			return nil
-		},
-		3,
-		1*time.Second,
-	)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reconnectDelay):
+				c.logger.Debug("reconnecting rpc client %s", c.targetIdentity.Name)
+			}
+		}
+	}()
 }
 
-func (c *client) Close() error {
-	if !c.IsConnected() {
-		return nil
-	}
+func (c *client) send(stream servicediscoveryv1.ServiceDiscovery_AssignmentsClient, message *servicediscoveryv1.FollowerMessage) error {
+	c.sendLock.Lock()
+	defer c.sendLock.Unlock()
 
-	logger.Log.Debug("closing rpc client %s", c.targetIdentity.Name)
+	return stream.Send(message)
+}
+
+func (c *client) ack(stream servicediscoveryv1.ServiceDiscovery_AssignmentsClient) error {
+	offsets := map[uint32]uint64{}
+	for vbID, seqNo := range c.serviceDiscovery.GetOffsets() {
+		offsets[uint32(vbID)] = seqNo
+	}
 
-	c.connected = false
-	err := c.client.Close()
-	c.client = nil
+	err := c.send(stream, &servicediscoveryv1.FollowerMessage{
+		Message: &servicediscoveryv1.FollowerMessage_Ack{
+			Ack: &servicediscoveryv1.Ack{Epoch: c.epoch.Load(), Offsets: offsets},
+		},
+	})
+	if err == nil {
+		c.lastHeartbeat.Store(time.Now().UnixNano())
+	}
 
 	return err
 }
 
-func (c *client) IsConnected() bool {
-	return c.connected
+func (c *client) reportProgress(ctx context.Context, stream servicediscoveryv1.ServiceDiscovery_AssignmentsClient) {
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.ack(stream); err != nil {
+				c.logger.Debug("cannot report progress to %s, err: %v", c.targetIdentity.Name, err)
+				return
+			}
+		}
+	}
 }
 
-func (c *client) Reconnect() error {
-	logger.Log.Debug("reconnecting rpc client %s", c.targetIdentity.Name)
-	return c.connect()
-}
+func (c *client) watch(ctx context.Context) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-func (c *client) Ping() error {
-	return helpers.Retry(
-		func() error {
-			var reply Pong
+	stream, err := servicediscoveryv1.NewServiceDiscoveryClient(c.conn).Assignments(streamCtx)
+	if err != nil {
+		return err
+	}
 
-			return c.client.Call("Handler.Ping", Ping{From: c.myIdentity}, &reply)
+	err = c.send(stream, &servicediscoveryv1.FollowerMessage{
+		Message: &servicediscoveryv1.FollowerMessage_Join{
+			Join: &servicediscoveryv1.Join{Identity: fromIdentity(c.myIdentity)},
 		},
-		3,
-		100*time.Millisecond,
-	)
+	})
+	if err != nil {
+		return err
+	}
+
+	c.connected.Store(true)
+	c.lastHeartbeat.Store(time.Now().UnixNano())
+	c.logger.Debug("connected to %s as rpc", c.targetIdentity.Name)
+
+	go c.reportProgress(streamCtx, stream)
+
+	for {
+		assignment, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		epoch := assignment.GetEpoch()
+		if current := c.epoch.Load(); epoch < current {
+			c.logger.Debug("skipping stale assignment epoch: %v, current: %v", epoch, current)
+			continue
+		}
+
+		c.epoch.Store(epoch)
+		c.serviceDiscovery.SetGroup(toIdentities(assignment.GetMembers()))
+		c.serviceDiscovery.SetInfo(int(assignment.GetMemberNumber()), int(assignment.GetTotalMembers()), epoch)
+
+		if err := c.ack(stream); err != nil {
+			return err
+		}
+	}
 }
 
-func (c *client) Register() error {
-	return helpers.Retry(
-		func() error {
-			var reply bool
+func (c *client) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
 
-			return c.client.Call("Handler.Register", Register{From: c.myIdentity, Identity: c.myIdentity}, &reply)
-		},
-		3,
-		100*time.Millisecond,
-	)
+	c.logger.Debug("closing rpc client %s", c.targetIdentity.Name)
+
+	c.connected.Store(false)
+
+	return c.conn.Close()
 }
 
-func (c *client) Rebalance(memberNumber int, totalMembers int) error {
-	return helpers.Retry(
-		func() error {
-			var reply bool
+func (c *client) IsConnected() bool {
+	return c.connected.Load()
+}
 
-			return c.client.Call(
-				"Handler.Rebalance",
-				Rebalance{From: c.myIdentity, MemberNumber: memberNumber, TotalMembers: totalMembers},
-				&reply,
-			)
-		},
-		3,
-		100*time.Millisecond,
-	)
+func (c *client) LastHeartbeat() int64 {
+	return c.lastHeartbeat.Load()
 }
 
-func NewClient(port int, myIdentity *models.Identity, targetIdentity *models.Identity) (Client, error) {
-	client := &client{
-		port:           port,
-		myIdentity:     myIdentity,
-		targetIdentity: targetIdentity,
+func NewClient(
+	rpcConfig *config.RPC,
+	myIdentity *models.Identity,
+	targetIdentity *models.Identity,
+	serviceDiscovery ServiceDiscovery,
+	logger logger.Logger,
+) (Client, error) {
+	options, err := dialOptions(rpcConfig)
+	if err != nil {
+		return nil, err
 	}
 
-	err := client.connect()
+	conn, err := grpc.Dial(net.JoinHostPort(targetIdentity.IP, strconv.Itoa(rpcConfig.Port)), options...)
 	if err != nil {
 		return nil, err
 	}
 
-	return client, nil
+	return &client{
+		serviceDiscovery: serviceDiscovery,
+		conn:             conn,
+		myIdentity:       myIdentity,
+		targetIdentity:   targetIdentity,
+		sendLock:         &sync.Mutex{},
+		logger:           logger,
+	}, nil
 }
@@ -1,113 +1,158 @@
 package servicediscovery
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
-	"net/rpc"
+
+	"github.com/Trendyol/go-dcp/config"
 
 	"github.com/Trendyol/go-dcp/models"
 
 	"github.com/Trendyol/go-dcp/logger"
+
+	servicediscoveryv1 "github.com/Trendyol/go-dcp/servicediscovery/proto/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+var errNotLeader = status.Error(codes.FailedPrecondition, "not the leader")
+
 type Server interface {
-	Listen()
+	Listen() error
 	Shutdown()
 }
 
 type server struct {
-	listener net.Listener
-	handler  *Handler
-	port     int
+	logger     logger.Logger
+	grpcServer *grpc.Server
+	handler    *Handler
+	rpcConfig  *config.RPC
 }
 
 type Handler struct {
+	servicediscoveryv1.UnimplementedServiceDiscoveryServer
 	serviceDiscovery ServiceDiscovery
 	myIdentity       *models.Identity
-	port             int
+	logger           logger.Logger
 }
 
-func (rh *Handler) Ping(_ Ping, reply *Pong) error {
-	*reply = Pong{
-		From: rh.myIdentity,
+func (rh *Handler) Assignments(stream servicediscoveryv1.ServiceDiscovery_AssignmentsServer) error {
+	message, err := stream.Recv()
+	if err != nil {
+		return err
 	}
 
-	return nil
-}
+	join := message.GetJoin()
+	if join == nil || join.GetIdentity() == nil {
+		return errors.New("first message of assignment stream must be join")
+	}
 
-func (rh *Handler) Register(payload Register, reply *bool) error {
-	followerClient, err := NewClient(rh.port, rh.myIdentity, payload.Identity)
-	if err != nil {
-		*reply = false
-		return err
+	if !rh.serviceDiscovery.IsLeader() {
+		return errNotLeader
 	}
 
-	followerService := NewService(followerClient, payload.Identity.Name, payload.Identity.ClusterJoinTime)
+	// the stream is ended when the follower is removed, e.g. all of them are removed when the leader resigns
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	followerService := newFollowerService(toIdentity(join.GetIdentity()), stream)
+	followerService.cancel = cancel
 	rh.serviceDiscovery.Add(followerService)
 
-	logger.Log.Debug("registered client %s", payload.Identity.Name)
+	rh.logger.Debug("registered client %s", followerService.Name)
 
-	*reply = true
+	defer func() {
+		// a reconnected follower is registered again with the same name, its registration is kept
+		rh.serviceDiscovery.RemoveService(followerService)
+		rh.logger.Debug("client %s disconnected", followerService.Name)
+	}()
 
-	return nil
-}
+	if !rh.serviceDiscovery.IsLeader() {
+		// resigned while the follower is joining, the followers are removed before this one is added
+		return errNotLeader
+	}
 
-func (rh *Handler) Rebalance(payload Rebalance, reply *bool) error {
-	rh.serviceDiscovery.SetInfo(payload.MemberNumber, payload.TotalMembers)
+	errCh := make(chan error, 1)
 
-	*reply = true
+	go func() {
+		errCh <- rh.receiveAcks(stream, followerService)
+	}()
 
-	return nil
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		if err := stream.Context().Err(); err != nil {
+			return err
+		}
+
+		return errNotLeader
+	}
 }
 
-func (s *server) Listen() {
-	server := rpc.NewServer()
+func (rh *Handler) receiveAcks(stream servicediscoveryv1.ServiceDiscovery_AssignmentsServer, followerService *Service) error {
+	for {
+		message, err := stream.Recv()
+		if err != nil {
+			return err
+		}
 
-	err := server.Register(s.handler)
-	if err != nil {
-		logger.Log.Error("error while registering rpc handler: %s", err)
-		panic(err)
+		if ack := message.GetAck(); ack != nil {
+			offsets := make(map[uint16]uint64, len(ack.GetOffsets()))
+			for vbID, seqNo := range ack.GetOffsets() {
+				offsets[uint16(vbID)] = seqNo
+			}
+
+			followerService.recordAck(offsets, ack.GetEpoch())
+		}
 	}
+}
 
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+func (s *server) Listen() error {
+	options, err := serverOptions(s.rpcConfig)
 	if err != nil {
-		logger.Log.Error("error while listening rpc server: %s", err)
-		panic(err)
+		return fmt.Errorf("error while creating rpc server: %w", err)
 	}
 
-	s.listener = listener
-	logger.Log.Info("rpc server started on port %d", s.port)
+	s.grpcServer = grpc.NewServer(options...)
+	servicediscoveryv1.RegisterServiceDiscoveryServer(s.grpcServer, s.handler)
 
-	go func() {
-		for {
-			conn, err := listener.Accept()
-			if err != nil {
-				logger.Log.Error("rpc server error: %s", err)
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.rpcConfig.Port))
+	if err != nil {
+		return fmt.Errorf("error while listening rpc server: %w", err)
+	}
 
-				break
-			}
+	s.logger.Info("rpc server started on port %d, tls: %v", s.rpcConfig.Port, s.rpcConfig.TLS.Enabled)
 
-			go server.ServeConn(conn)
+	go func() {
+		if err := s.grpcServer.Serve(listener); err != nil {
+			s.logger.Error("rpc server error: %s", err)
 		}
 
-		logger.Log.Info("rpc server stopped")
+		s.logger.Info("rpc server stopped")
 	}()
+
+	return nil
 }
 
 func (s *server) Shutdown() {
-	err := s.listener.Close()
-	if err != nil {
-		logger.Log.Error("error while closing rpc server: %s", err)
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
 	}
 }
 
-func NewServer(port int, myIdentity *models.Identity, serviceDiscovery ServiceDiscovery) Server {
+func NewServer(rpcConfig *config.RPC, myIdentity *models.Identity, serviceDiscovery ServiceDiscovery, logger logger.Logger) Server {
 	return &server{
-		port: port,
+		rpcConfig: rpcConfig,
+		logger:    logger,
 		handler: &Handler{
-			port:             port,
 			myIdentity:       myIdentity,
 			serviceDiscovery: serviceDiscovery,
+			logger:           logger,
 		},
 	}
 }
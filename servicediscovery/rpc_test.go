@@ -0,0 +1,271 @@
+package servicediscovery
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/membership"
+	"github.com/Trendyol/go-dcp/models"
+
+	servicediscoveryv1 "github.com/Trendyol/go-dcp/servicediscovery/proto/v1"
+
+	"github.com/asaskevich/EventBus"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type testNetwork struct {
+	listener *bufconn.Listener
+	server   *grpc.Server
+	handler  *Handler
+	lock     sync.Mutex
+}
+
+// serve starts a new server, followers are disconnected from the previous one.
+func (n *testNetwork) serve() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.server != nil {
+		n.server.Stop()
+	}
+
+	n.listener = bufconn.Listen(1024 * 1024)
+	n.server = grpc.NewServer()
+	servicediscoveryv1.RegisterServiceDiscoveryServer(n.server, n.handler)
+
+	go func(server *grpc.Server, listener net.Listener) {
+		_ = server.Serve(listener)
+	}(n.server, n.listener)
+}
+
+func (n *testNetwork) dial(ctx context.Context, _ string) (net.Conn, error) {
+	n.lock.Lock()
+	listener := n.listener
+	n.lock.Unlock()
+
+	return listener.DialContext(ctx)
+}
+
+func (n *testNetwork) stop() {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.server.Stop()
+}
+
+type testCountingServiceDiscovery struct {
+	ServiceDiscovery
+	added atomic.Int32
+}
+
+func (s *testCountingServiceDiscovery) Add(service *Service) {
+	s.added.Add(1)
+	s.ServiceDiscovery.Add(service)
+}
+
+type testEpochStore struct {
+	epoch atomic.Uint64
+}
+
+func (s *testEpochStore) Next(_ context.Context, _ []string) (uint64, error) {
+	return s.epoch.Add(1), nil
+}
+
+func (s *testEpochStore) Current(_ context.Context) (uint64, []string, error) {
+	return s.epoch.Load(), nil, nil
+}
+
+func newTestServiceDiscovery() *serviceDiscovery {
+	c := &config.Dcp{}
+	c.Checkpoint.Timeout = time.Second
+
+	return NewServiceDiscovery(c, EventBus.New(), newTestLogger()).(*serviceDiscovery)
+}
+
+func newTestLogger() logger.Logger {
+	logrusLogger := logrus.New()
+	logrusLogger.SetLevel(logrus.FatalLevel)
+
+	return &logger.Loggers{Logrus: logrusLogger}
+}
+
+func newTestFollower(t *testing.T, network *testNetwork, name string, clusterJoinTime int64) (*serviceDiscovery, Client) {
+	follower := newTestServiceDiscovery()
+	follower.SetMyIdentity(&models.Identity{Name: name, ClusterJoinTime: clusterJoinTime})
+	follower.SetOffsetProvider(func() map[uint16]uint64 {
+		return map[uint16]uint64{1: 10}
+	})
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(network.dial),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &client{
+		serviceDiscovery: follower,
+		conn:             conn,
+		myIdentity:       &models.Identity{Name: name, ClusterJoinTime: clusterJoinTime},
+		targetIdentity:   &models.Identity{Name: "leader"},
+		sendLock:         &sync.Mutex{},
+		logger:           newTestLogger(),
+	}
+	c.Start()
+
+	t.Cleanup(func() {
+		_ = c.Close()
+	})
+
+	return follower, c
+}
+
+func eventually(t *testing.T, message string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Condition is not met in time: %s", message)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func hasInfo(s *serviceDiscovery, memberNumber int, totalMembers int) func() bool {
+	return func() bool {
+		info := s.getInfo()
+		return info != nil && info.MemberNumber == memberNumber && info.TotalMembers == totalMembers
+	}
+}
+
+func findMember(members []membership.Member, name string) *membership.Member {
+	for i := range members {
+		if members[i].Name == name {
+			return &members[i]
+		}
+	}
+
+	return nil
+}
+
+func newTestLeader(t *testing.T) (*serviceDiscovery, *testCountingServiceDiscovery, *testNetwork) {
+	leader := newTestServiceDiscovery()
+	leader.SetMyIdentity(&models.Identity{Name: "leader"})
+	leader.SetEpochStore(&testEpochStore{})
+	leader.monitoring.Store(true)
+	leader.BeLeader()
+
+	counting := &testCountingServiceDiscovery{ServiceDiscovery: leader}
+	network := &testNetwork{handler: &Handler{serviceDiscovery: counting, logger: newTestLogger()}}
+	network.serve()
+	t.Cleanup(network.stop)
+
+	return leader, counting, network
+}
+
+func TestAssignments(t *testing.T) {
+	leader, counting, network := newTestLeader(t)
+
+	first, firstClient := newTestFollower(t, network, "first", 1)
+
+	eventually(t, "first follower is assigned 2/2", hasInfo(first, 2, 2))
+	eventually(t, "first follower acks its offsets and epoch", func() bool {
+		member := findMember(leader.GetMembers(), "first")
+		return member != nil && member.Offsets[1] == 10 && member.Epoch == leader.getInfo().Epoch
+	})
+
+	second, _ := newTestFollower(t, network, "second", 2)
+
+	eventually(t, "followers are rebalanced to 2/3 and 3/3", func() bool {
+		return hasInfo(first, 2, 3)() && hasInfo(second, 3, 3)()
+	})
+
+	if first.getInfo().Epoch != leader.getInfo().Epoch || second.getInfo().Epoch != leader.getInfo().Epoch {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v and %v",
+			leader.getInfo().Epoch, first.getInfo().Epoch, second.getInfo().Epoch)
+	}
+
+	members := first.GetMembers()
+	for memberNumber, name := range []string{"leader", "first", "second"} {
+		member := findMember(members, name)
+		if member == nil || member.MemberNumber != memberNumber+1 || member.Leader != (memberNumber == 0) {
+			t.Errorf("Unexpected result. Expected: %v, Got: %+v", name+" as member "+strconv.Itoa(memberNumber+1), member)
+		}
+	}
+
+	if member := findMember(members, "first"); member == nil || !member.Self {
+		t.Errorf("Unexpected result. Expected: %v, Got: %+v", "first is self", member)
+	}
+
+	// followers join again after the streams of the previous server are broken
+	network.serve()
+
+	eventually(t, "followers reconnect", func() bool {
+		return counting.added.Load() == 4 && len(leader.GetAll()) == 2 && firstClient.IsConnected()
+	})
+
+	eventually(t, "reconnected followers keep their assignment", func() bool {
+		return hasInfo(first, 2, 3)() && hasInfo(second, 3, 3)() && first.getInfo().Epoch == leader.getInfo().Epoch
+	})
+}
+
+func TestResignEndsAssignmentStreams(t *testing.T) {
+	leader, counting, network := newTestLeader(t)
+
+	first, firstClient := newTestFollower(t, network, "first", 1)
+
+	eventually(t, "first follower is assigned 2/2", hasInfo(first, 2, 2))
+
+	leader.DontBeLeader()
+	leader.RemoveAll()
+
+	eventually(t, "assignment stream of the follower is ended", func() bool {
+		return !firstClient.IsConnected()
+	})
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(network.dial),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	stream, err := servicediscoveryv1.NewServiceDiscoveryClient(conn).Assignments(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = stream.Send(&servicediscoveryv1.FollowerMessage{
+		Message: &servicediscoveryv1.FollowerMessage_Join{
+			Join: &servicediscoveryv1.Join{Identity: &servicediscoveryv1.Identity{Name: "second"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := stream.Recv(); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", codes.FailedPrecondition, err)
+	}
+
+	if names := counting.GetAll(); len(names) != 0 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "no followers", names)
+	}
+}
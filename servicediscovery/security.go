@@ -0,0 +1,208 @@
+package servicediscovery
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Trendyol/go-dcp/config"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeader = "authorization"
+	bearerPrefix        = "Bearer "
+	keepaliveTime       = 5 * time.Second
+	keepaliveTimeout    = 5 * time.Second
+)
+
+var ErrUnauthenticated = status.Error(codes.Unauthenticated, "rpc authentication failed")
+
+func newCertPool(rootCAPath string) (*x509.CertPool, error) {
+	cert, err := os.ReadFile(os.ExpandEnv(rootCAPath))
+	if err != nil {
+		return nil, err
+	}
+
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(cert) {
+		return nil, fmt.Errorf("no certificate found in %s", rootCAPath)
+	}
+
+	return certPool, nil
+}
+
+func loadCertificates(tlsConfig *config.RPCTLS) ([]tls.Certificate, error) {
+	if tlsConfig.CertPath == "" || tlsConfig.KeyPath == "" {
+		return nil, nil
+	}
+
+	certificate, err := tls.LoadX509KeyPair(os.ExpandEnv(tlsConfig.CertPath), os.ExpandEnv(tlsConfig.KeyPath))
+	if err != nil {
+		return nil, err
+	}
+
+	return []tls.Certificate{certificate}, nil
+}
+
+func newServerTLSConfig(tlsConfig *config.RPCTLS) (*tls.Config, error) {
+	certificates, err := loadCertificates(tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(certificates) == 0 {
+		return nil, errors.New("rpc tls certPath and keyPath are required for server")
+	}
+
+	serverTLSConfig := &tls.Config{
+		Certificates: certificates,
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if tlsConfig.ClientAuth {
+		clientCAs, err := newCertPool(tlsConfig.RootCAPath)
+		if err != nil {
+			return nil, err
+		}
+
+		serverTLSConfig.ClientCAs = clientCAs
+		serverTLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return serverTLSConfig, nil
+}
+
+func newClientTLSConfig(tlsConfig *config.RPCTLS) (*tls.Config, error) {
+	clientTLSConfig := &tls.Config{
+		ServerName:         tlsConfig.ServerName,
+		InsecureSkipVerify: tlsConfig.InsecureSkipVerify, //nolint:gosec
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if tlsConfig.RootCAPath != "" {
+		rootCAs, err := newCertPool(tlsConfig.RootCAPath)
+		if err != nil {
+			return nil, err
+		}
+
+		clientTLSConfig.RootCAs = rootCAs
+	}
+
+	if tlsConfig.ClientAuth {
+		certificates, err := loadCertificates(tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		clientTLSConfig.Certificates = certificates
+	}
+
+	return clientTLSConfig, nil
+}
+
+func serverOptions(rpcConfig *config.RPC) ([]grpc.ServerOption, error) {
+	options := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{Time: keepaliveTime, Timeout: keepaliveTimeout}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: keepaliveTime, PermitWithoutStream: true}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := verify(ss.Context(), rpcConfig.Token); err != nil {
+				return err
+			}
+
+			return handler(srv, ss)
+		}),
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := verify(ctx, rpcConfig.Token); err != nil {
+				return nil, err
+			}
+
+			return handler(ctx, req)
+		}),
+	}
+
+	if rpcConfig.TLS.Enabled {
+		serverTLSConfig, err := newServerTLSConfig(&rpcConfig.TLS)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, grpc.Creds(credentials.NewTLS(serverTLSConfig)))
+	}
+
+	return options, nil
+}
+
+func dialOptions(rpcConfig *config.RPC) ([]grpc.DialOption, error) {
+	options := []grpc.DialOption{
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                keepaliveTime,
+			Timeout:             keepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+	}
+
+	if rpcConfig.TLS.Enabled {
+		clientTLSConfig, err := newClientTLSConfig(&rpcConfig.TLS)
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, grpc.WithTransportCredentials(credentials.NewTLS(clientTLSConfig)))
+	} else {
+		options = append(options, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	if rpcConfig.Token != "" {
+		options = append(options, grpc.WithPerRPCCredentials(&tokenCredentials{token: rpcConfig.Token}))
+	}
+
+	return options, nil
+}
+
+type tokenCredentials struct {
+	token string
+}
+
+func (t *tokenCredentials) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{authorizationHeader: bearerPrefix + t.token}, nil
+}
+
+// RequireTransportSecurity makes grpc refuse to send the token over a connection without TLS.
+func (t *tokenCredentials) RequireTransportSecurity() bool {
+	return true
+}
+
+// verify compares the bearer token of the incoming rpc with the shared token.
+func verify(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+
+	for _, value := range md.Get(authorizationHeader) {
+		received := strings.TrimPrefix(value, bearerPrefix)
+		if subtle.ConstantTimeCompare([]byte(received), []byte(token)) == 1 {
+			return nil
+		}
+	}
+
+	return ErrUnauthenticated
+}
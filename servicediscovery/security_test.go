@@ -0,0 +1,37 @@
+package servicediscovery
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func newIncomingContext(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(authorizationHeader, bearerPrefix+token))
+}
+
+func TestVerifyMatchingToken(t *testing.T) {
+	if err := verify(newIncomingContext("secret"), "secret"); err != nil {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", nil, err)
+	}
+}
+
+func TestVerifyMismatchingToken(t *testing.T) {
+	if err := verify(newIncomingContext("wrong"), "secret"); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", ErrUnauthenticated, err)
+	}
+}
+
+func TestVerifyMissingToken(t *testing.T) {
+	if err := verify(context.Background(), "secret"); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", ErrUnauthenticated, err)
+	}
+}
+
+func TestVerifyWithoutConfiguredToken(t *testing.T) {
+	if err := verify(context.Background(), ""); err != nil {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", nil, err)
+	}
+}
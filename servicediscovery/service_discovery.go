@@ -1,7 +1,9 @@
 package servicediscovery
 
 import (
-	"fmt"
+	"context"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/asaskevich/EventBus"
@@ -13,48 +15,97 @@ import (
 	"github.com/Trendyol/go-dcp/membership"
 
 	"github.com/Trendyol/go-dcp/helpers"
+	"github.com/Trendyol/go-dcp/models"
 
 	"github.com/Trendyol/go-dcp/logger"
+
+	servicediscoveryv1 "github.com/Trendyol/go-dcp/servicediscovery/proto/v1"
 )
 
 type ServiceDiscovery interface {
 	Add(service *Service)
 	Remove(name string)
+	RemoveService(service *Service)
 	RemoveAll()
 	AssignLeader(leaderService *Service)
 	RemoveLeader()
-	ReassignLeader() error
-	StartHeartbeat()
-	StopHeartbeat()
 	StartMonitor()
 	StopMonitor()
 	GetAll() []string
-	SetInfo(memberNumber int, totalMembers int)
+	SetInfo(memberNumber int, totalMembers int, epoch uint64)
+	SetGroup(group []*models.Identity)
 	BeLeader()
 	DontBeLeader()
+	IsLeader() bool
+	SetMyIdentity(identity *models.Identity)
+	GetMembers() []membership.Member
+	SetOffsetProvider(offsetProvider func() map[uint16]uint64)
+	GetOffsets() map[uint16]uint64
+	SetEpochStore(epochStore membership.EpochStore)
+	LatestEpoch(model *membership.Model) uint64
 }
 
 type serviceDiscovery struct {
-	bus             EventBus.Bus
-	leaderService   *Service
-	services        *wrapper.ConcurrentSwissMap[string, *Service]
-	heartbeatTicker *time.Ticker
-	monitorTicker   *time.Ticker
-	info            *membership.Model
-	config          *config.Dcp
-	amILeader       bool
+	logger         logger.Logger
+	bus            EventBus.Bus
+	leaderService  *Service
+	services       *wrapper.ConcurrentSwissMap[string, *Service]
+	monitorTimer   *time.Timer
+	retryTimer     *time.Timer
+	info           *membership.Model
+	group          []*models.Identity
+	config         *config.Dcp
+	myIdentity     *models.Identity
+	offsetProvider func() map[uint16]uint64
+	epochStore     membership.EpochStore
+	assignLock     *sync.Mutex
+	servicesLock   *sync.Mutex
+	infoLock       *sync.RWMutex
+	generation     uint64
+	amILeader      atomic.Bool
+	monitoring     atomic.Bool
 }
 
 func (s *serviceDiscovery) Add(service *Service) {
+	s.servicesLock.Lock()
 	s.services.Store(service.Name, service)
+	s.servicesLock.Unlock()
+
+	s.assign()
 }
 
 func (s *serviceDiscovery) Remove(name string) {
-	if service, ok := s.services.Load(name); ok {
-		_ = service.Client.Close()
-
+	s.servicesLock.Lock()
+	service, ok := s.services.Load(name)
+	if ok {
 		s.services.Delete(name)
 	}
+	s.servicesLock.Unlock()
+
+	if ok {
+		s.removed(service)
+	}
+}
+
+// RemoveService removes the service only while it is still the one registered with its name.
+func (s *serviceDiscovery) RemoveService(service *Service) {
+	s.servicesLock.Lock()
+	registered, ok := s.services.Load(service.Name)
+	ok = ok && registered == service
+	if ok {
+		s.services.Delete(service.Name)
+	}
+	s.servicesLock.Unlock()
+
+	if ok {
+		s.removed(service)
+	}
+}
+
+func (s *serviceDiscovery) removed(service *Service) {
+	service.close()
+
+	s.assign()
 }
 
 func (s *serviceDiscovery) RemoveAll() {
@@ -72,123 +123,189 @@ func (s *serviceDiscovery) RemoveAll() {
 }
 
 func (s *serviceDiscovery) BeLeader() {
-	s.amILeader = true
+	s.amILeader.Store(true)
+	s.assign()
 }
 
 func (s *serviceDiscovery) DontBeLeader() {
-	s.amILeader = false
+	s.amILeader.Store(false)
+}
+
+func (s *serviceDiscovery) IsLeader() bool {
+	return s.amILeader.Load()
 }
 
 func (s *serviceDiscovery) AssignLeader(leaderService *Service) {
+	s.servicesLock.Lock()
+	defer s.servicesLock.Unlock()
+
 	s.leaderService = leaderService
 }
 
 func (s *serviceDiscovery) RemoveLeader() {
-	if s.leaderService == nil {
+	s.servicesLock.Lock()
+	leaderService := s.leaderService
+	s.leaderService = nil
+	s.servicesLock.Unlock()
+
+	if leaderService == nil {
 		return
 	}
 
-	_ = s.leaderService.Client.Close()
+	_ = leaderService.Client.Close()
+}
+
+func (s *serviceDiscovery) getLeaderService() *Service {
+	s.servicesLock.Lock()
+	defer s.servicesLock.Unlock()
 
-	s.leaderService = nil
+	return s.leaderService
 }
 
-func (s *serviceDiscovery) ReassignLeader() error {
-	if s.leaderService == nil {
-		return fmt.Errorf("leader is not assigned")
+// nextEpoch returns the epoch of the assignment from the epoch store, so it stays increasing across leader changes.
+// Without an epoch store it is 0 and checkpoint writes are not fenced.
+func (s *serviceDiscovery) nextEpoch(services []Service) (uint64, error) {
+	if s.epochStore == nil {
+		return 0, nil
 	}
 
-	err := s.leaderService.Client.Reconnect()
+	members := make([]string, 0, len(services)+1)
+	if s.myIdentity != nil {
+		members = append(members, s.myIdentity.Name)
+	}
 
-	if err == nil {
-		err = s.leaderService.Client.Register()
+	for _, service := range services {
+		members = append(members, service.Name)
 	}
 
-	return err
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Checkpoint.Timeout)
+	defer cancel()
+
+	return s.epochStore.Next(ctx, members)
 }
 
-func (s *serviceDiscovery) StartHeartbeat() {
-	s.heartbeatTicker = time.NewTicker(5 * time.Second)
+// assign pushes the current assignment to every follower, it is triggered by membership changes instead of polling.
+// The assignment is taken under assignLock and sent outside of it, so a slow follower does not block the others.
+func (s *serviceDiscovery) assign() {
+	if !s.amILeader.Load() || !s.monitoring.Load() {
+		return
+	}
 
-	go func() {
-		for range s.heartbeatTicker.C {
-			if s.leaderService != nil {
-				err := s.leaderService.Client.Ping()
-				if err != nil {
-					logger.Log.Error("leader is down, health check failed for leader")
+	s.assignLock.Lock()
 
-					tempLeaderService := s.leaderService
+	services := s.getSortedServices()
+	totalMembers := len(services) + 1
+	epoch, err := s.nextEpoch(services)
+	if err != nil {
+		s.retryAssign(err)
+		s.assignLock.Unlock()
 
-					if err := s.ReassignLeader(); err != nil {
-						if tempLeaderService != s.leaderService {
-							_ = tempLeaderService.Client.Close()
-						} else {
-							s.RemoveLeader()
-						}
-					}
-				}
-			}
+		return
+	}
 
-			var needToBeRemove []string
+	if s.retryTimer != nil {
+		s.retryTimer.Stop()
+	}
 
-			s.services.Range(func(name string, service *Service) bool {
-				err := service.Client.Ping()
-				if err != nil {
-					needToBeRemove = append(needToBeRemove, name)
-				}
+	s.generation++
+	generation := s.generation
 
-				return true
-			})
+	s.SetInfo(1, totalMembers, epoch)
+
+	s.assignLock.Unlock()
+
+	var leader *servicediscoveryv1.Identity
+	var group []*servicediscoveryv1.Identity
+	if s.myIdentity != nil {
+		leader = fromIdentity(s.myIdentity)
+		group = toGroup(leader, services)
+	}
 
-			for _, name := range needToBeRemove {
-				s.Remove(name)
-				logger.Log.Debug("client %s disconnected", name)
+	wg := &sync.WaitGroup{}
+
+	for index := range services {
+		wg.Add(1)
+
+		go func(service *Service, memberNumber int) {
+			defer wg.Done()
+
+			err := service.assign(&servicediscoveryv1.Assignment{
+				Epoch:        epoch,
+				MemberNumber: uint32(memberNumber),
+				TotalMembers: uint32(totalMembers),
+				Leader:       leader,
+				Members:      group,
+			}, generation)
+			if err != nil {
+				s.logger.Error("rebalance failed for %s, err: %v", service.Name, err)
 			}
-		}
-	}()
+		}(&services[index], index+2)
+	}
+
+	wg.Wait()
+
+	s.logger.Debug("assignment epoch %v sent to %v followers", epoch, len(services))
 }
 
-func (s *serviceDiscovery) StopHeartbeat() {
-	s.heartbeatTicker.Stop()
+// toGroup returns the identities of the members ordered by the member numbers of the assignment.
+func toGroup(leader *servicediscoveryv1.Identity, services []Service) []*servicediscoveryv1.Identity {
+	group := make([]*servicediscoveryv1.Identity, 0, len(services)+1)
+	group = append(group, leader)
+
+	for _, service := range services {
+		group = append(group, &servicediscoveryv1.Identity{
+			Ip:              service.IP,
+			Name:            service.Name,
+			ClusterJoinTime: service.ClusterJoinTime,
+		})
+	}
+
+	return group
 }
 
-func (s *serviceDiscovery) StartMonitor() {
-	s.monitorTicker = time.NewTicker(5 * time.Second)
+// retryAssign schedules assign again after the rebalance delay, it is called with assignLock held.
+// A pending retry is postponed instead of adding another one.
+func (s *serviceDiscovery) retryAssign(err error) {
+	delay := s.config.Dcp.Group.Membership.RebalanceDelay
 
-	go func() {
-		logger.Log.Info("service discovery will start after %v", s.config.Dcp.Group.Membership.RebalanceDelay)
-		time.Sleep(s.config.Dcp.Group.Membership.RebalanceDelay)
+	s.logger.Error("cannot get epoch of the assignment, retrying after %v, err: %v", delay, err)
 
-		for range s.monitorTicker.C {
-			if !s.amILeader {
-				continue
-			}
+	if s.retryTimer == nil {
+		s.retryTimer = time.AfterFunc(delay, s.assign)
+		return
+	}
 
-			names := s.GetAll()
-			totalMembers := len(names) + 1
+	s.retryTimer.Reset(delay)
+}
 
-			s.SetInfo(1, totalMembers)
+func (s *serviceDiscovery) StartMonitor() {
+	s.logger.Info("service discovery will start after %v", s.config.Dcp.Group.Membership.RebalanceDelay)
 
-			for index, name := range names {
-				if service, ok := s.services.Load(name); ok {
-					if err := service.Client.Rebalance(index+2, totalMembers); err != nil {
-						logger.Log.Error("rebalance failed for %s", name)
-					}
-				}
-			}
-		}
-	}()
+	s.monitorTimer = time.AfterFunc(s.config.Dcp.Group.Membership.RebalanceDelay, func() {
+		s.monitoring.Store(true)
+		s.assign()
+	})
 }
 
 func (s *serviceDiscovery) StopMonitor() {
-	s.monitorTicker.Stop()
+	s.monitoring.Store(false)
+
+	if s.monitorTimer != nil {
+		s.monitorTimer.Stop()
+	}
+
+	s.assignLock.Lock()
+	if s.retryTimer != nil {
+		s.retryTimer.Stop()
+	}
+	s.assignLock.Unlock()
 }
 
-func (s *serviceDiscovery) GetAll() []string {
+func (s *serviceDiscovery) getSortedServices() []Service {
 	var services []Service
 
 	s.services.Range(func(name string, service *Service) bool {
-		services = append(services, *service)
+		services = append(services, service.snapshot())
 
 		return true
 	})
@@ -197,6 +314,12 @@ func (s *serviceDiscovery) GetAll() []string {
 		return s1.ClusterJoinTime < s2.ClusterJoinTime
 	}).Sort(services)
 
+	return services
+}
+
+func (s *serviceDiscovery) GetAll() []string {
+	services := s.getSortedServices()
+
 	var names []string
 
 	for _, service := range services {
@@ -206,25 +329,189 @@ func (s *serviceDiscovery) GetAll() []string {
 	return names
 }
 
-func (s *serviceDiscovery) SetInfo(memberNumber int, totalMembers int) {
+func (s *serviceDiscovery) SetInfo(memberNumber int, totalMembers int, epoch uint64) {
+	s.infoLock.Lock()
+	defer s.infoLock.Unlock()
+
 	newInfo := &membership.Model{
 		MemberNumber: memberNumber,
 		TotalMembers: totalMembers,
+		Epoch:        epoch,
+	}
+
+	if !newInfo.IsChanged(s.info) {
+		// same vBucket range, only the fencing token moves forward without a rebalance, see LatestEpoch
+		if epoch > s.info.Epoch {
+			s.info = newInfo
+		}
+
+		return
+	}
+
+	s.info = newInfo
+
+	s.logger.With("member", memberNumber).Debug("new info arrived for member: %v/%v, epoch: %v", memberNumber, totalMembers, epoch)
+
+	// subscribers keep the published model, it is a copy so later changes of info do not reach them
+	published := *newInfo
+	s.bus.Publish(helpers.MembershipChangedBusEventName, &published)
+}
+
+// LatestEpoch returns the epoch of the current info when it has the same assignment as model.
+// The epoch of an assignment moves forward without publishing a new model.
+func (s *serviceDiscovery) LatestEpoch(model *membership.Model) uint64 {
+	s.infoLock.RLock()
+	defer s.infoLock.RUnlock()
+
+	if s.info != nil && !model.IsChanged(s.info) && s.info.Epoch > model.Epoch {
+		return s.info.Epoch
+	}
+
+	return model.Epoch
+}
+
+// SetGroup keeps the members of the assignment received by a follower, ordered by their member numbers.
+func (s *serviceDiscovery) SetGroup(group []*models.Identity) {
+	s.infoLock.Lock()
+	defer s.infoLock.Unlock()
+
+	s.group = group
+}
+
+func (s *serviceDiscovery) getGroup() []*models.Identity {
+	s.infoLock.RLock()
+	defer s.infoLock.RUnlock()
+
+	return s.group
+}
+
+func (s *serviceDiscovery) getInfo() *membership.Model {
+	s.infoLock.RLock()
+	defer s.infoLock.RUnlock()
+
+	return s.info
+}
+
+func (s *serviceDiscovery) SetMyIdentity(identity *models.Identity) {
+	s.myIdentity = identity
+}
+
+func (s *serviceDiscovery) SetOffsetProvider(offsetProvider func() map[uint16]uint64) {
+	s.offsetProvider = offsetProvider
+}
+
+func (s *serviceDiscovery) SetEpochStore(epochStore membership.EpochStore) {
+	s.epochStore = epochStore
+}
+
+func (s *serviceDiscovery) GetOffsets() map[uint16]uint64 {
+	if s.offsetProvider == nil {
+		return nil
 	}
 
-	if newInfo.IsChanged(s.info) {
-		s.info = newInfo
+	return s.offsetProvider()
+}
 
-		logger.Log.Debug("new info arrived for member: %v/%v", memberNumber, totalMembers)
+func (s *serviceDiscovery) GetMembers() []membership.Member {
+	info := s.getInfo()
+	if info == nil {
+		return nil
+	}
+
+	amILeader := s.amILeader.Load()
 
-		s.bus.Publish(helpers.MembershipChangedBusEventName, newInfo)
+	if !amILeader {
+		if group := s.getGroup(); len(group) > 0 {
+			return s.getFollowerMembers(info, group)
+		}
 	}
+
+	var members []membership.Member
+
+	if s.myIdentity != nil {
+		members = append(members, membership.Member{
+			Name:            s.myIdentity.Name,
+			IP:              s.myIdentity.IP,
+			ClusterJoinTime: s.myIdentity.ClusterJoinTime,
+			LastHeartbeat:   time.Now().UnixNano(),
+			MemberNumber:    info.MemberNumber,
+			TotalMembers:    info.TotalMembers,
+			Self:            true,
+			Leader:          amILeader,
+		})
+	}
+
+	if !amILeader {
+		// the leader has not sent the group yet, the leader is the first member of every assignment
+		if leaderService := s.getLeaderService(); leaderService != nil {
+			members = append(members, membership.Member{
+				Name:            leaderService.Name,
+				IP:              leaderService.IP,
+				ClusterJoinTime: leaderService.ClusterJoinTime,
+				LastHeartbeat:   leaderService.Client.LastHeartbeat(),
+				MemberNumber:    1,
+				TotalMembers:    info.TotalMembers,
+				Leader:          true,
+			})
+		}
+
+		return members
+	}
+
+	for index, service := range s.getSortedServices() {
+		members = append(members, membership.Member{
+			Name:            service.Name,
+			IP:              service.IP,
+			ClusterJoinTime: service.ClusterJoinTime,
+			LastHeartbeat:   service.LastHeartbeat,
+			MemberNumber:    index + 2,
+			TotalMembers:    info.TotalMembers,
+			Epoch:           service.AckedEpoch,
+			Offsets:         service.Offsets,
+		})
+	}
+
+	return members
+}
+
+// getFollowerMembers returns the group of the latest assignment, a follower knows the heartbeats of itself and the leader.
+func (s *serviceDiscovery) getFollowerMembers(info *membership.Model, group []*models.Identity) []membership.Member {
+	leaderService := s.getLeaderService()
+
+	members := make([]membership.Member, 0, len(group))
+
+	for index, identity := range group {
+		member := membership.Member{
+			Name:            identity.Name,
+			IP:              identity.IP,
+			ClusterJoinTime: identity.ClusterJoinTime,
+			MemberNumber:    index + 1,
+			TotalMembers:    info.TotalMembers,
+			Self:            s.myIdentity != nil && identity.Name == s.myIdentity.Name,
+			Leader:          index == 0,
+		}
+
+		switch {
+		case member.Self:
+			member.LastHeartbeat = time.Now().UnixNano()
+		case member.Leader && leaderService != nil:
+			member.LastHeartbeat = leaderService.Client.LastHeartbeat()
+		}
+
+		members = append(members, member)
+	}
+
+	return members
 }
 
-func NewServiceDiscovery(config *config.Dcp, bus EventBus.Bus) ServiceDiscovery {
+func NewServiceDiscovery(config *config.Dcp, bus EventBus.Bus, logger logger.Logger) ServiceDiscovery {
 	return &serviceDiscovery{
-		services: wrapper.CreateConcurrentSwissMap[string, *Service](0),
-		bus:      bus,
-		config:   config,
+		logger:       logger,
+		services:     wrapper.CreateConcurrentSwissMap[string, *Service](0),
+		bus:          bus,
+		config:       config,
+		assignLock:   &sync.Mutex{},
+		servicesLock: &sync.Mutex{},
+		infoLock:     &sync.RWMutex{},
 	}
 }
@@ -0,0 +1,77 @@
+package servicediscovery
+
+import (
+	"testing"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/helpers"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/membership"
+	"github.com/Trendyol/go-dcp/models"
+
+	"github.com/asaskevich/EventBus"
+)
+
+func TestRemoveServiceKeepsReconnectedFollower(t *testing.T) {
+	s := NewServiceDiscovery(&config.Dcp{}, EventBus.New(), logger.Log)
+
+	identity := &models.Identity{Name: "follower"}
+
+	disconnected := newFollowerService(identity, nil)
+	s.Add(disconnected)
+
+	reconnected := newFollowerService(identity, nil)
+	s.Add(reconnected)
+
+	// the stream of the disconnected follower ends after the follower is registered again
+	s.RemoveService(disconnected)
+
+	if names := s.GetAll(); len(names) != 1 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", []string{"follower"}, names)
+	}
+
+	s.RemoveService(reconnected)
+
+	if names := s.GetAll(); len(names) != 0 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", []string{}, names)
+	}
+}
+
+func TestServiceRecordAck(t *testing.T) {
+	service := newFollowerService(&models.Identity{Name: "follower"}, nil)
+
+	service.recordAck(map[uint16]uint64{1: 10}, 3)
+
+	snapshot := service.snapshot()
+
+	if snapshot.AckedEpoch != 3 || snapshot.Offsets[1] != 10 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "epoch 3 and offset 10", snapshot)
+	}
+}
+
+func TestSetInfoDoesNotChangePublishedModel(t *testing.T) {
+	bus := EventBus.New()
+	s := NewServiceDiscovery(&config.Dcp{}, bus, newTestLogger())
+
+	var published *membership.Model
+	if err := bus.Subscribe(helpers.MembershipChangedBusEventName, func(model *membership.Model) {
+		published = model
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s.SetInfo(2, 3, 5)
+	s.SetInfo(2, 3, 7)
+
+	if published == nil || published.Epoch != 5 {
+		t.Fatalf("Unexpected result. Expected: %v, Got: %v", 5, published)
+	}
+
+	if epoch := s.LatestEpoch(published); epoch != 7 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 7, epoch)
+	}
+
+	if epoch := s.LatestEpoch(&membership.Model{MemberNumber: 1, TotalMembers: 3, Epoch: 4}); epoch != 4 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 4, epoch)
+	}
+}
@@ -0,0 +1,193 @@
+package dcp
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/asaskevich/EventBus"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/helpers"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/metadata"
+	"github.com/Trendyol/go-dcp/models"
+	"github.com/Trendyol/go-dcp/stream"
+)
+
+// source streams a bucket declared in config.Dcp.Sources next to the top level one.
+// It shares the membership and the api of the connector but keeps its own checkpoints.
+type source struct {
+	logger     logger.Logger
+	tracer     trace.Tracer
+	tail       *stream.Tail
+	client     couchbase.Client
+	metadata   metadata.Metadata
+	stream     stream.Stream
+	bus        EventBus.Bus
+	config     *config.Dcp
+	version    *couchbase.Version
+	bucketInfo *couchbase.BucketInfo
+	stopCh     chan struct{}
+}
+
+func (s *source) createMetadata() (metadata.Metadata, error) {
+	switch {
+	case s.config.IsCouchbaseMetadata():
+		return couchbase.NewCBMetadata(s.client, s.config)
+	case s.config.IsFileMetadata():
+		return metadata.NewFSMetadata(s.config)
+	default:
+		return nil, fmt.Errorf("metadata: %s, err: %w", s.config.Metadata.Type, metadata.ErrUnsupportedMetadataType)
+	}
+}
+
+// open starts streaming the vbuckets assigned by vBucketDiscovery, the source bucket must have as many vbuckets
+// as the top level one since they share the assignment.
+func (s *source) open(ctx context.Context,
+	vBucketDiscovery stream.VBucketDiscovery,
+	listener models.Listener,
+	eventHandler models.EventHandler,
+	errorListener func(opErr *models.OpError),
+	vBucketNumber int,
+) error {
+	md, err := s.createMetadata()
+	if err != nil {
+		return err
+	}
+
+	s.metadata = md
+
+	if s.config.Metadata.ReadOnly {
+		s.metadata = metadata.NewReadMetadata(s.metadata)
+	}
+
+	s.logger.Info("source %s using %v metadata", s.config.GetSourceName(), reflect.TypeOf(s.metadata))
+
+	// operations of the source are published on its own bus, vbucket ids are not unique across buckets
+	err = s.bus.SubscribeAsync(helpers.ErrorBusEventName, errorListener, false)
+	if err != nil {
+		return fmt.Errorf("cannot subscribe to error event: %w", err)
+	}
+
+	vBuckets, err := s.client.GetNumVBuckets()
+	if err != nil {
+		return err
+	}
+
+	if vBuckets != vBucketNumber {
+		return fmt.Errorf(
+			"source %s has %d vbuckets, expected: %d", s.config.GetSourceName(), vBuckets, vBucketNumber,
+		)
+	}
+
+	collectionIDs, err := s.client.GetCollectionIDs(ctx, s.config.ScopeName, s.config.CollectionNames)
+	if err != nil {
+		return err
+	}
+
+	s.stream = stream.NewStream(
+		s.client, s.metadata, s.config, s.version, s.bucketInfo, vBucketDiscovery,
+		listener, collectionIDs, s.stopCh, s.bus, eventHandler, s.tracer, s.tail, s.logger,
+	)
+
+	return s.stream.Open(ctx)
+}
+
+func (s *source) close(closeWithCancel bool, errorListener func(opErr *models.OpError)) error {
+	var err error
+
+	if s.stream != nil {
+		s.stream.Close(closeWithCancel)
+	}
+
+	disconnect(s.client)
+
+	if s.bus.HasCallback(helpers.ErrorBusEventName) {
+		if unsubscribeErr := s.bus.Unsubscribe(helpers.ErrorBusEventName, errorListener); unsubscribeErr != nil {
+			err = fmt.Errorf("cannot unsubscribe source %s from error event: %w", s.config.GetSourceName(), unsubscribeErr)
+		}
+	}
+
+	s.logger.Info("source %s closed", s.config.GetSourceName())
+
+	return err
+}
+
+// sourcesStream makes a rebalance requested through the api rebalance the sources as well.
+type sourcesStream struct {
+	stream.Stream
+	sources []*source
+}
+
+// GetLags returns the lags of the sources after the ones of the top level bucket.
+func (s *sourcesStream) GetLags() ([]stream.VBucketLag, error) {
+	lags, err := s.Stream.GetLags()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, source := range s.sources {
+		if source.stream == nil {
+			continue
+		}
+
+		sourceLags, err := source.stream.GetLags()
+		if err != nil {
+			return nil, fmt.Errorf("source %s: %w", source.config.GetSourceName(), err)
+		}
+
+		lags = append(lags, sourceLags...)
+	}
+
+	return lags, nil
+}
+
+// GetHealth combines the health of the top level bucket with the sources, every one of them must be ready.
+func (s *sourcesStream) GetHealth() stream.Health {
+	health := s.Stream.GetHealth()
+
+	for _, source := range s.sources {
+		if source.stream == nil {
+			health = health.Merge(stream.Health{})
+			continue
+		}
+
+		health = health.Merge(source.stream.GetHealth())
+	}
+
+	return health
+}
+
+func (s *sourcesStream) Rebalance() {
+	s.Stream.Rebalance()
+
+	for _, source := range s.sources {
+		if source.stream == nil {
+			continue
+		}
+
+		source.stream.Rebalance()
+	}
+}
+
+func newSource(config *config.Dcp, tracer trace.Tracer, tail *stream.Tail, logger logger.Logger) (*source, error) {
+	client, version, bucketInfo, err := connect(config, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return &source{
+		logger:     logger,
+		tracer:     tracer,
+		tail:       tail,
+		client:     client,
+		config:     config,
+		version:    version,
+		bucketInfo: bucketInfo,
+		bus:        EventBus.New(),
+		stopCh:     make(chan struct{}, 1),
+	}, nil
+}
@@ -0,0 +1,142 @@
+package dcp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/asaskevich/EventBus"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/helpers"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/models"
+	"github.com/Trendyol/go-dcp/stream"
+)
+
+type testSourceStream struct {
+	stream.Stream
+	lagsErr    error
+	lags       []stream.VBucketLag
+	health     stream.Health
+	rebalanced int
+	closed     int
+}
+
+func (s *testSourceStream) GetLags() ([]stream.VBucketLag, error) {
+	return s.lags, s.lagsErr
+}
+
+func (s *testSourceStream) GetHealth() stream.Health {
+	return s.health
+}
+
+func (s *testSourceStream) Rebalance() {
+	s.rebalanced++
+}
+
+func (s *testSourceStream) Close(_ bool) {
+	s.closed++
+}
+
+type testSourceClient struct {
+	couchbase.Client
+	dcpClosed int
+	closed    int
+}
+
+func (c *testSourceClient) DcpClose() {
+	c.dcpClosed++
+}
+
+func (c *testSourceClient) Close() {
+	c.closed++
+}
+
+func newTestSource(name string, sourceStream stream.Stream) *source {
+	sourceConfig := &config.Dcp{BucketName: name}
+
+	return &source{
+		config: sourceConfig,
+		stream: sourceStream,
+		client: &testSourceClient{},
+		bus:    EventBus.New(),
+		logger: &logger.Loggers{Logrus: logrus.New()},
+	}
+}
+
+func TestSourcesStreamRebalance(t *testing.T) {
+	top := &testSourceStream{}
+	opened := &testSourceStream{}
+
+	s := &sourcesStream{Stream: top, sources: []*source{newTestSource("opened", opened), newTestSource("not-opened", nil)}}
+	s.Rebalance()
+
+	if top.rebalanced != 1 || opened.rebalanced != 1 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v and %v", 1, top.rebalanced, opened.rebalanced)
+	}
+}
+
+func TestSourcesStreamGetLags(t *testing.T) {
+	top := &testSourceStream{lags: []stream.VBucketLag{{Bucket: "top", VbID: 1}}}
+	opened := &testSourceStream{lags: []stream.VBucketLag{{Bucket: "opened", VbID: 1}}}
+
+	s := &sourcesStream{Stream: top, sources: []*source{newTestSource("opened", opened), newTestSource("not-opened", nil)}}
+
+	lags, err := s.GetLags()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(lags) != 2 || lags[0].Bucket != "top" || lags[1].Bucket != "opened" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "lags of top and opened", lags)
+	}
+
+	opened.lagsErr = errors.New("high seq nos are not sampled yet")
+
+	if _, err := s.GetLags(); err == nil {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "error of source", err)
+	}
+}
+
+func TestSourcesStreamGetHealth(t *testing.T) {
+	top := &testSourceStream{health: stream.Health{Open: true, AssignedVBuckets: 512, OpenStreams: 512}}
+	opened := &testSourceStream{health: stream.Health{Open: true, AssignedVBuckets: 512, OpenStreams: 500}}
+
+	s := &sourcesStream{Stream: top, sources: []*source{newTestSource("opened", opened)}}
+
+	health := s.GetHealth()
+	if !health.Open || health.AssignedVBuckets != 1024 || health.OpenStreams != 1012 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %+v", "open with 1012 of 1024 streams", health)
+	}
+
+	s.sources = append(s.sources, newTestSource("not-opened", nil))
+
+	if health := s.GetHealth(); health.Open {
+		t.Errorf("Unexpected result. Expected: %v, Got: %+v", "not open", health)
+	}
+}
+
+func TestSourceClose(t *testing.T) {
+	sourceStream := &testSourceStream{}
+	source := newTestSource("source", sourceStream)
+
+	errorListener := func(_ *models.OpError) {}
+	if err := source.bus.SubscribeAsync(helpers.ErrorBusEventName, errorListener, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := source.close(false, errorListener); err != nil {
+		t.Fatal(err)
+	}
+
+	client := source.client.(*testSourceClient)
+	if sourceStream.closed != 1 || client.dcpClosed != 1 || client.closed != 1 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %+v", "stream and connections closed", client)
+	}
+
+	if source.bus.HasCallback(helpers.ErrorBusEventName) {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "error listener unsubscribed", true)
+	}
+}
@@ -1,7 +1,11 @@
 package stream
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Trendyol/go-dcp/wrapper"
@@ -17,6 +21,9 @@ import (
 	"github.com/Trendyol/go-dcp/logger"
 
 	"github.com/couchbase/gocbcore/v10"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -25,44 +32,56 @@ const (
 )
 
 type Checkpoint interface {
-	Save()
-	Load() (*wrapper.ConcurrentSwissMap[uint16, *models.Offset], *wrapper.ConcurrentSwissMap[uint16, bool], bool)
-	Clear()
-	StartSchedule()
+	Save(ctx context.Context)
+	Load(ctx context.Context) (*wrapper.ConcurrentSwissMap[uint16, *models.Offset], *wrapper.ConcurrentSwissMap[uint16, bool], bool, error) //nolint:lll
+	Clear(ctx context.Context)
+	StartSchedule(ctx context.Context)
 	StopSchedule()
+	ResetSchedule(interval time.Duration)
 	GetMetric() *CheckpointMetric
 }
 
 type CheckpointMetric struct {
-	OffsetWrite        int
+	OffsetWrite int
+	// LastSavedAt is the unix nano time of the latest successful save since the stream is opened, 0 if none.
+	LastSavedAt        atomic.Int64
 	OffsetWriteLatency int64
+	// ConsecutiveSaveFailures is read by the readiness endpoint while checkpoints are saved.
+	ConsecutiveSaveFailures atomic.Int64
 }
 
 type checkpoint struct {
-	stream     Stream
-	client     couchbase.Client
-	metadata   metadata.Metadata
-	schedule   *time.Ticker
-	config     *config.Dcp
-	saveLock   *sync.Mutex
-	loadLock   *sync.Mutex
-	metric     *CheckpointMetric
-	bucketUUID string
-	vbIds      []uint16
+	logger       logger.Logger
+	tracer       trace.Tracer
+	stream       Stream
+	client       couchbase.Client
+	metadata     metadata.Metadata
+	schedule     *time.Ticker
+	config       *config.Dcp
+	saveLock     *sync.Mutex
+	loadLock     *sync.Mutex
+	scheduleLock *sync.Mutex
+	metric       *CheckpointMetric
+	bucketUUID   string
+	vbIds        []uint16
 }
 
-func (s *checkpoint) Save() {
+func (s *checkpoint) Save(ctx context.Context) {
 	offsets, dirtyOffsets, anyDirtyOffset := s.stream.GetOffsets()
 
 	if !anyDirtyOffset {
-		logger.Log.Trace("no need to save checkpoint")
+		s.logger.Trace("no need to save checkpoint")
 		return
 	}
 
 	s.saveLock.Lock()
 	defer s.saveLock.Unlock()
 
+	ctx, span := s.tracer.Start(ctx, CheckpointSaveSpanName)
+	defer span.End()
+
 	checkpointDump := map[uint16]*models.CheckpointDocument{}
+	fencingToken := s.stream.GetFencingToken()
 
 	offsets.Range(func(vbID uint16, offset *models.Offset) bool {
 		checkpointDump[vbID] = &models.CheckpointDocument{
@@ -74,7 +93,8 @@ func (s *checkpoint) Save() {
 					EndSeqNo:   offset.EndSeqNo,
 				},
 			},
-			BucketUUID: s.bucketUUID,
+			BucketUUID:   s.bucketUUID,
+			FencingToken: fencingToken,
 		}
 
 		return true
@@ -95,43 +115,71 @@ func (s *checkpoint) Save() {
 
 	s.metric.OffsetWrite = dirtyOffsetCount
 
+	span.SetAttributes(
+		BucketKey.String(s.config.BucketName),
+		DirtyKey.Int(dirtyOffsetCount),
+		FencingKey.Int64(int64(fencingToken)),
+	)
+
 	start := time.Now()
 
-	err := s.metadata.Save(checkpointDump, dirtyOffsetsDump, s.bucketUUID)
+	err := s.metadata.Save(ctx, checkpointDump, dirtyOffsetsDump, s.bucketUUID)
+
+	latency := time.Since(start)
+	s.metric.OffsetWriteLatency = latency.Milliseconds()
 
-	s.metric.OffsetWriteLatency = time.Since(start).Milliseconds()
+	streamMetric, _ := s.stream.GetMetric()
+	if streamMetric != nil && streamMetric.CheckpointSaveLatency != nil {
+		streamMetric.CheckpointSaveLatency.Observe(latency.Seconds())
+	}
 
 	if err == nil {
-		logger.Log.Trace("saved checkpoint")
-		s.stream.UnmarkDirtyOffsets()
+		s.metric.ConsecutiveSaveFailures.Store(0)
+		s.metric.LastSavedAt.Store(time.Now().UnixNano())
 	} else {
-		logger.Log.Error("error while saving checkpoint document: %v", err)
+		s.metric.ConsecutiveSaveFailures.Add(1)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "cannot save checkpoint")
+	}
+
+	switch {
+	case err == nil:
+		s.logger.Trace("saved checkpoint")
+		s.stream.UnmarkDirtyOffsets()
+	case errors.Is(err, metadata.ErrStaleFencingToken):
+		s.logger.Error("checkpoint rejected, another member owns the vbuckets: %v", err)
+		s.stream.Fence()
+	default:
+		s.logger.Error("error while saving checkpoint document: %v", err)
+	}
+
+	if err != nil && streamMetric != nil && streamMetric.Errors != nil {
+		streamMetric.Errors.Add(ErrorRecord{Time: time.Now(), Op: "checkpoint save", Error: err.Error()})
 	}
 }
 
-func (s *checkpoint) Load() (*wrapper.ConcurrentSwissMap[uint16, *models.Offset], *wrapper.ConcurrentSwissMap[uint16, bool], bool) {
+//nolint:lll
+func (s *checkpoint) Load(ctx context.Context) (*wrapper.ConcurrentSwissMap[uint16, *models.Offset], *wrapper.ConcurrentSwissMap[uint16, bool], bool, error) {
 	s.loadLock.Lock()
 	defer s.loadLock.Unlock()
 
-	dump, exist, err := s.metadata.Load(s.vbIds, s.bucketUUID)
-	if err == nil {
-		logger.Log.Debug("loaded checkpoint")
-	} else {
-		logger.Log.Error("error while loading checkpoint document: %v", err)
-		panic(err)
+	dump, exist, err := s.metadata.Load(ctx, s.vbIds, s.bucketUUID)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("error while loading checkpoint document: %w", err)
 	}
 
+	s.logger.Debug("loaded checkpoint")
+
 	offsets := wrapper.CreateConcurrentSwissMap[uint16, *models.Offset](1024)
 	dirtyOffsets := wrapper.CreateConcurrentSwissMap[uint16, bool](1024)
 	anyDirtyOffset := false
 
 	if !exist && s.config.Checkpoint.AutoReset == CheckpointAutoResetTypeLatest {
-		logger.Log.Debug("no checkpoint found, auto reset checkpoint to latest")
+		s.logger.Debug("no checkpoint found, auto reset checkpoint to latest")
 
-		seqNoMap, err := s.client.GetVBucketSeqNos()
+		seqNoMap, err := s.client.GetVBucketSeqNos(ctx)
 		if err != nil {
-			logger.Log.Error("error while getting vbucket seqNos: %v", err)
-			panic(err)
+			return nil, nil, false, fmt.Errorf("error while getting vbucket seqNos: %w", err)
 		}
 
 		dump.Range(func(vbID uint16, doc *models.CheckpointDocument) bool {
@@ -154,7 +202,7 @@ func (s *checkpoint) Load() (*wrapper.ConcurrentSwissMap[uint16, *models.Offset]
 			return true
 		})
 
-		return offsets, dirtyOffsets, anyDirtyOffset
+		return offsets, dirtyOffsets, anyDirtyOffset, nil
 	}
 
 	dump.Range(func(vbID uint16, doc *models.CheckpointDocument) bool {
@@ -170,27 +218,31 @@ func (s *checkpoint) Load() (*wrapper.ConcurrentSwissMap[uint16, *models.Offset]
 		return true
 	})
 
-	return offsets, dirtyOffsets, anyDirtyOffset
+	return offsets, dirtyOffsets, anyDirtyOffset, nil
 }
 
-func (s *checkpoint) Clear() {
-	_ = s.metadata.Clear(s.vbIds)
-	logger.Log.Debug("cleared checkpoint")
+func (s *checkpoint) Clear(ctx context.Context) {
+	_ = s.metadata.Clear(ctx, s.vbIds)
+	s.logger.Debug("cleared checkpoint")
 }
 
-func (s *checkpoint) StartSchedule() {
+func (s *checkpoint) StartSchedule(ctx context.Context) {
 	if s.config.Checkpoint.Type != CheckpointTypeAuto {
 		return
 	}
 
+	s.scheduleLock.Lock()
+	schedule := time.NewTicker(s.config.Checkpoint.GetInterval())
+	s.schedule = schedule
+	s.scheduleLock.Unlock()
+
 	go func() {
-		s.schedule = time.NewTicker(s.config.Checkpoint.Interval)
-		for range s.schedule.C {
-			s.Save()
+		for range schedule.C {
+			s.Save(ctx)
 		}
 	}()
 
-	logger.Log.Debug("started checkpoint schedule")
+	s.logger.Debug("started checkpoint schedule")
 }
 
 func (s *checkpoint) StopSchedule() {
@@ -198,25 +250,44 @@ func (s *checkpoint) StopSchedule() {
 		return
 	}
 
+	s.scheduleLock.Lock()
 	if s.schedule != nil {
 		s.schedule.Stop()
 	}
+	s.scheduleLock.Unlock()
 
-	logger.Log.Debug("stopped checkpoint schedule")
+	s.logger.Debug("stopped checkpoint schedule")
+}
+
+// ResetSchedule applies a reloaded checkpoint.interval to the running schedule.
+func (s *checkpoint) ResetSchedule(interval time.Duration) {
+	if s.config.Checkpoint.Type != CheckpointTypeAuto {
+		return
+	}
+
+	s.scheduleLock.Lock()
+	defer s.scheduleLock.Unlock()
+
+	if s.schedule == nil {
+		return
+	}
+
+	s.schedule.Reset(interval)
+
+	s.logger.Debug("reset checkpoint schedule to %v", interval)
 }
 
 func (s *checkpoint) GetMetric() *CheckpointMetric {
 	return s.metric
 }
 
-func getBucketUUID(client couchbase.Client) string {
+func getBucketUUID(client couchbase.Client) (string, error) {
 	snapshot, err := client.GetConfigSnapshot()
 	if err != nil {
-		logger.Log.Error("failed to get config snapshot: %v", err)
-		panic(err)
+		return "", fmt.Errorf("failed to get config snapshot: %w", err)
 	}
 
-	return snapshot.BucketUUID()
+	return snapshot.BucketUUID(), nil
 }
 
 func NewCheckpoint(
@@ -225,16 +296,26 @@ func NewCheckpoint(
 	client couchbase.Client,
 	metadata metadata.Metadata,
 	config *config.Dcp,
-) Checkpoint {
-	return &checkpoint{
-		client:     client,
-		stream:     stream,
-		vbIds:      vbIds,
-		bucketUUID: getBucketUUID(client),
-		metadata:   metadata,
-		config:     config,
-		saveLock:   &sync.Mutex{},
-		loadLock:   &sync.Mutex{},
-		metric:     &CheckpointMetric{},
+	tracer trace.Tracer,
+	logger logger.Logger,
+) (Checkpoint, error) {
+	bucketUUID, err := getBucketUUID(client)
+	if err != nil {
+		return nil, err
 	}
+
+	return &checkpoint{
+		client:       client,
+		stream:       stream,
+		vbIds:        vbIds,
+		bucketUUID:   bucketUUID,
+		metadata:     metadata,
+		config:       config,
+		saveLock:     &sync.Mutex{},
+		loadLock:     &sync.Mutex{},
+		scheduleLock: &sync.Mutex{},
+		metric:       &CheckpointMetric{},
+		tracer:       tracer,
+		logger:       logger,
+	}, nil
 }
@@ -0,0 +1,107 @@
+package stream
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Trendyol/go-dcp/config"
+)
+
+// Health is the state of a stream which decides whether it is ready.
+type Health struct {
+	Open                    bool          `json:"open"`
+	Rebalancing             bool          `json:"rebalancing"`
+	AssignedVBuckets        int           `json:"assignedVBuckets"`
+	OpenStreams             int           `json:"openStreams"`
+	CheckpointSaveFailures  int           `json:"checkpointSaveFailures"`
+	ListenerBusyFor         time.Duration `json:"-"`
+	ListenerBusyForSeconds  float64       `json:"listenerBusyForSeconds"`
+	ReopeningVBucketStreams int           `json:"reopeningVBucketStreams"`
+	// ListenerStalled is set while the watchdog reports a stall and its action is failHealth.
+	ListenerStalled     bool    `json:"listenerStalled"`
+	SecondsSinceLastAck float64 `json:"secondsSinceLastAck"`
+}
+
+// NotReadyReasons returns why the stream is not ready, it is ready when there is none.
+func (h Health) NotReadyReasons(readiness *config.Readiness) []string {
+	var reasons []string
+
+	if h.Rebalancing && !readiness.ReadyWhileRebalancing {
+		reasons = append(reasons, "rebalance is in progress")
+	} else if !h.Open {
+		reasons = append(reasons, "stream is not open")
+	}
+
+	if h.Open && h.AssignedVBuckets > 0 &&
+		float64(h.OpenStreams) < readiness.MinOpenStreamRatio*float64(h.AssignedVBuckets) {
+		reasons = append(reasons, fmt.Sprintf(
+			"%d of %d assigned vBuckets have an open stream", h.OpenStreams, h.AssignedVBuckets,
+		))
+	}
+
+	if h.CheckpointSaveFailures >= readiness.MaxCheckpointSaveFailures {
+		reasons = append(reasons, fmt.Sprintf("%d checkpoint saves failed in a row", h.CheckpointSaveFailures))
+	}
+
+	if h.ListenerBusyFor >= readiness.ListenerStallTimeout {
+		reasons = append(reasons, fmt.Sprintf("listener is handling an event for %v", h.ListenerBusyFor.Truncate(time.Second)))
+	}
+
+	if h.ListenerStalled {
+		reasons = append(reasons, fmt.Sprintf("listener did not ack any event for %.0fs", h.SecondsSinceLastAck))
+	}
+
+	return reasons
+}
+
+// Merge combines the health of the streams of several buckets.
+func (h Health) Merge(other Health) Health {
+	return Health{
+		Open:                    h.Open && other.Open,
+		Rebalancing:             h.Rebalancing || other.Rebalancing,
+		AssignedVBuckets:        h.AssignedVBuckets + other.AssignedVBuckets,
+		OpenStreams:             h.OpenStreams + other.OpenStreams,
+		ReopeningVBucketStreams: h.ReopeningVBucketStreams + other.ReopeningVBucketStreams,
+		CheckpointSaveFailures:  max(h.CheckpointSaveFailures, other.CheckpointSaveFailures),
+		ListenerBusyFor:         max(h.ListenerBusyFor, other.ListenerBusyFor),
+		ListenerBusyForSeconds:  max(h.ListenerBusyForSeconds, other.ListenerBusyForSeconds),
+		ListenerStalled:         h.ListenerStalled || other.ListenerStalled,
+		SecondsSinceLastAck:     max(h.SecondsSinceLastAck, other.SecondsSinceLastAck),
+	}
+}
+
+func (s *stream) GetHealth() Health {
+	// the fields are replaced by Open and Close while the health is read
+	s.openLock.RLock()
+	observer, vbIDs, checkpoint := s.observer, s.vbIds, s.checkpoint
+	s.openLock.RUnlock()
+
+	health := Health{
+		Open:        observer != nil,
+		Rebalancing: s.balancing.Load(),
+	}
+
+	if !health.Open {
+		return health
+	}
+
+	health.AssignedVBuckets = vbIDs.Count()
+	health.ReopeningVBucketStreams = s.reopening.Count()
+	health.OpenStreams = max(int(s.activeStreams.Load())-health.ReopeningVBucketStreams, 0)
+
+	if checkpoint != nil {
+		health.CheckpointSaveFailures = int(checkpoint.GetMetric().ConsecutiveSaveFailures.Load())
+	}
+
+	if startedAt := s.listenerStartedAt.Load(); startedAt != 0 {
+		health.ListenerBusyFor = time.Since(time.Unix(0, startedAt))
+		health.ListenerBusyForSeconds = health.ListenerBusyFor.Seconds()
+	}
+
+	if watchdog := s.config.Dcp.Listener.Watchdog; watchdog.Enabled {
+		health.SecondsSinceLastAck = time.Since(time.Unix(0, s.lastAckAt.Load())).Seconds()
+		health.ListenerStalled = s.stalled.Load() && watchdog.Action == config.WatchdogActionFailHealth
+	}
+
+	return health
+}
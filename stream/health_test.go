@@ -0,0 +1,92 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+func TestHealthNotReadyReasons(t *testing.T) {
+	readiness := &config.Readiness{
+		MinOpenStreamRatio:        0.5,
+		MaxCheckpointSaveFailures: 3,
+		ListenerStallTimeout:      time.Minute,
+	}
+
+	healthy := Health{Open: true, AssignedVBuckets: 10, OpenStreams: 5}
+	if reasons := healthy.NotReadyReasons(readiness); len(reasons) != 0 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "no reason", reasons)
+	}
+
+	unhealthy := Health{
+		Open:                   true,
+		AssignedVBuckets:       10,
+		OpenStreams:            4,
+		CheckpointSaveFailures: 3,
+		ListenerBusyFor:        2 * time.Minute,
+	}
+	if reasons := unhealthy.NotReadyReasons(readiness); len(reasons) != 3 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 3, reasons)
+	}
+
+	rebalancing := Health{Rebalancing: true}
+	if reasons := rebalancing.NotReadyReasons(readiness); len(reasons) != 1 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 1, reasons)
+	}
+
+	readiness.ReadyWhileRebalancing = true
+
+	if reasons := rebalancing.NotReadyReasons(readiness); len(reasons) != 1 || reasons[0] != "stream is not open" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "stream is not open", reasons)
+	}
+}
+
+func TestHealthMerge(t *testing.T) {
+	merged := Health{Open: true, AssignedVBuckets: 512, OpenStreams: 512, CheckpointSaveFailures: 1}.Merge(
+		Health{Open: true, AssignedVBuckets: 512, OpenStreams: 500, ListenerBusyFor: time.Second, Rebalancing: true},
+	)
+
+	if !merged.Open || !merged.Rebalancing || merged.AssignedVBuckets != 1024 || merged.OpenStreams != 1012 ||
+		merged.CheckpointSaveFailures != 1 || merged.ListenerBusyFor != time.Second {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "merged health", merged)
+	}
+
+	if notOpen := merged.Merge(Health{}); notOpen.Open {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", false, notOpen.Open)
+	}
+}
+
+func TestGetHealthWhileClosing(t *testing.T) {
+	s, _, _ := newTestStream(func(ctx *models.ListenerContext) {
+		ctx.Ack()
+	}, time.Second)
+	s.activeStreams.Store(1)
+
+	doneCh := make(chan struct{})
+	readDoneCh := make(chan struct{})
+
+	go func() {
+		defer close(readDoneCh)
+
+		for {
+			select {
+			case <-doneCh:
+				return
+			default:
+				s.GetHealth()
+				s.GetObserver()
+				s.GetMetric()
+			}
+		}
+	}()
+
+	s.Close(false)
+	close(doneCh)
+	<-readDoneCh
+
+	if health := s.GetHealth(); health.Open {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "closed stream", health)
+	}
+}
@@ -0,0 +1,93 @@
+package stream
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// LatencyBuckets are the upper bounds in seconds of the latency histograms, from 1ms to about 33s.
+var LatencyBuckets = []float64{
+	0.001, 0.002, 0.004, 0.008, 0.016, 0.032, 0.064, 0.128, 0.256, 0.512, 1.024, 2.048, 4.096, 8.192, 16.384, 32.768,
+}
+
+// Histogram counts observations into buckets without locking, so it can be observed for every event.
+// The metric collector exposes it as a prometheus histogram.
+type Histogram struct {
+	upperBounds []float64
+	counts      []atomic.Uint64
+	count       atomic.Uint64
+	sumBits     atomic.Uint64
+}
+
+func (h *Histogram) Observe(value float64) {
+	i := sort.SearchFloat64s(h.upperBounds, value)
+	if i < len(h.counts) {
+		h.counts[i].Add(1)
+	}
+
+	for {
+		old := h.sumBits.Load()
+		if h.sumBits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+value)) {
+			break
+		}
+	}
+
+	h.count.Add(1)
+}
+
+// Snapshot returns the count, the sum and the cumulative count of every bucket.
+func (h *Histogram) Snapshot() (uint64, float64, map[float64]uint64) {
+	buckets := make(map[float64]uint64, len(h.upperBounds))
+
+	var cumulative uint64
+
+	for i, upperBound := range h.upperBounds {
+		cumulative += h.counts[i].Load()
+		buckets[upperBound] = cumulative
+	}
+
+	count := h.count.Load()
+	if count < cumulative {
+		// an observation is being recorded
+		count = cumulative
+	}
+
+	return count, math.Float64frombits(h.sumBits.Load()), buckets
+}
+
+func NewHistogram(upperBounds []float64) *Histogram {
+	return &Histogram{
+		upperBounds: upperBounds,
+		counts:      make([]atomic.Uint64, len(upperBounds)),
+	}
+}
+
+// CollectionHistograms keeps a histogram per collection, they are created on the first observation.
+type CollectionHistograms struct {
+	histograms  sync.Map
+	upperBounds []float64
+}
+
+func (c *CollectionHistograms) Observe(collectionName string, value float64) {
+	histogram, ok := c.histograms.Load(collectionName)
+	if !ok {
+		histogram, _ = c.histograms.LoadOrStore(collectionName, NewHistogram(c.upperBounds))
+	}
+
+	histogram.(*Histogram).Observe(value)
+}
+
+func (c *CollectionHistograms) Range(fn func(collectionName string, histogram *Histogram)) {
+	c.histograms.Range(func(key, value any) bool {
+		fn(key.(string), value.(*Histogram))
+		return true
+	})
+}
+
+func NewCollectionHistograms(upperBounds []float64) *CollectionHistograms {
+	return &CollectionHistograms{
+		upperBounds: upperBounds,
+	}
+}
@@ -0,0 +1,62 @@
+package stream
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestHistogramSnapshot(t *testing.T) {
+	histogram := NewHistogram([]float64{0.01, 0.1, 1})
+
+	for _, value := range []float64{0.005, 0.01, 0.05, 0.5, 5} {
+		histogram.Observe(value)
+	}
+
+	count, sum, buckets := histogram.Snapshot()
+
+	if count != 5 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 5, count)
+	}
+
+	if math.Abs(sum-5.565) > 1e-9 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 5.565, sum)
+	}
+
+	expected := map[float64]uint64{0.01: 2, 0.1: 3, 1: 4}
+	for upperBound, cumulative := range expected {
+		if buckets[upperBound] != cumulative {
+			t.Errorf("Unexpected result. Expected: %v, Got: %v", expected, buckets)
+		}
+	}
+}
+
+func TestCollectionHistogramsObserveConcurrently(t *testing.T) {
+	histograms := NewCollectionHistograms(LatencyBuckets)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 1000; j++ {
+				histograms.Observe("orders", 0.003)
+				histograms.Observe("users", 0.003)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	counts := map[string]uint64{}
+	histograms.Range(func(collectionName string, histogram *Histogram) {
+		counts[collectionName], _, _ = histogram.Snapshot()
+	})
+
+	if len(counts) != 2 || counts["orders"] != 8000 || counts["users"] != 8000 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "8000 per collection", counts)
+	}
+}
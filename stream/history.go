@@ -0,0 +1,71 @@
+package stream
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	RebalanceHistorySize = 20
+	ErrorHistorySize     = 50
+)
+
+// RebalanceRecord is a finished rebalance of the stream, Error is set when the stream could not be opened again.
+type RebalanceRecord struct {
+	StartedAt    time.Time `json:"startedAt"`
+	FinishedAt   time.Time `json:"finishedAt"`
+	Error        string    `json:"error,omitempty"`
+	FencingToken uint64    `json:"fencingToken"`
+	VBuckets     int       `json:"vBuckets"`
+}
+
+// ErrorRecord is an error of an operation running in the background of the stream.
+type ErrorRecord struct {
+	Time  time.Time `json:"time"`
+	Op    string    `json:"op"`
+	Error string    `json:"error"`
+}
+
+// History keeps the latest entries, the oldest entry is dropped when it is full.
+type History[T any] struct {
+	entries []T
+	next    int
+	full    bool
+	lock    sync.RWMutex
+}
+
+func (h *History[T]) Add(entry T) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.entries[h.next] = entry
+	h.next = (h.next + 1) % len(h.entries)
+
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// List returns the entries from the latest to the oldest.
+func (h *History[T]) List() []T {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	count := h.next
+	if h.full {
+		count = len(h.entries)
+	}
+
+	entries := make([]T, 0, count)
+	for i := 1; i <= count; i++ {
+		entries = append(entries, h.entries[(h.next-i+len(h.entries))%len(h.entries)])
+	}
+
+	return entries
+}
+
+func NewHistory[T any](size int) *History[T] {
+	return &History[T]{
+		entries: make([]T, size),
+	}
+}
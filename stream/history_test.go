@@ -0,0 +1,27 @@
+package stream
+
+import (
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	history := NewHistory[int](3)
+
+	if entries := history.List(); len(entries) != 0 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 0, len(entries))
+	}
+
+	history.Add(1)
+	history.Add(2)
+
+	if entries := history.List(); len(entries) != 2 || entries[0] != 2 || entries[1] != 1 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", []int{2, 1}, entries)
+	}
+
+	history.Add(3)
+	history.Add(4)
+
+	if entries := history.List(); len(entries) != 3 || entries[0] != 4 || entries[2] != 2 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", []int{4, 3, 2}, entries)
+	}
+}
@@ -0,0 +1,106 @@
+package stream
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+// VBucketLag tells how far the acked events of a vBucket are behind its high seqno.
+type VBucketLag struct {
+	FirstUnackedEventTime time.Time `json:"firstUnackedEventTime"`
+	HighSeqNoSampledAt    time.Time `json:"highSeqNoSampledAt"`
+	Bucket                string    `json:"bucket"`
+	SeqNo                 uint64    `json:"seqNo"`
+	HighSeqNo             uint64    `json:"highSeqNo"`
+	SeqNoLag              uint64    `json:"seqNoLag"`
+	SecondsBehind         float64   `json:"secondsBehind"`
+	VbID                  uint16    `json:"vbId"`
+}
+
+// newVBucketLag is 0 seconds behind when the vBucket is caught up. Otherwise it is behind since the event time
+// of its first event which is not acked yet, an idle vBucket is not behind before its next event is received.
+func newVBucketLag(vbID uint16, seqNo uint64, highSeqNo uint64, firstUnackedEventTime time.Time, sampledAt time.Time) VBucketLag {
+	lag := VBucketLag{
+		VbID:                  vbID,
+		SeqNo:                 seqNo,
+		HighSeqNo:             highSeqNo,
+		FirstUnackedEventTime: firstUnackedEventTime,
+		HighSeqNoSampledAt:    sampledAt,
+	}
+
+	if highSeqNo <= seqNo {
+		return lag
+	}
+
+	lag.SeqNoLag = highSeqNo - seqNo
+
+	if firstUnackedEventTime.IsZero() {
+		return lag
+	}
+
+	if secondsBehind := sampledAt.Sub(firstUnackedEventTime).Seconds(); secondsBehind > 0 {
+		lag.SecondsBehind = secondsBehind
+	}
+
+	return lag
+}
+
+// GetLags returns the lags against the latest high seq no sample, with an error when there is no sample yet
+// or the sample is stale.
+func (s *stream) GetLags() ([]VBucketLag, error) {
+	// offsets are replaced when the stream is opened and closed
+	s.openLock.RLock()
+	offsets := s.offsets
+	s.openLock.RUnlock()
+
+	if offsets == nil {
+		return nil, nil
+	}
+
+	highSeqNos, err := s.highSeqNoSampler.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	lags := make([]VBucketLag, 0, offsets.Count())
+
+	offsets.Range(func(vbID uint16, offset *models.Offset) bool {
+		firstUnackedEventTime, _ := s.unackedEventTimes.Load(vbID)
+
+		lag := newVBucketLag(vbID, offset.SeqNo, highSeqNos.SeqNos[vbID], firstUnackedEventTime, highSeqNos.SampledAt)
+		lag.Bucket = s.config.BucketName
+
+		lags = append(lags, lag)
+
+		return true
+	})
+
+	return lags, nil
+}
+
+func (s *stream) GetHighSeqNoSampler() couchbase.HighSeqNoSampler {
+	return s.highSeqNoSampler
+}
+
+// WorstLags returns at most limit lags with the most seconds behind first, seqno lag breaks ties.
+func WorstLags(lags []VBucketLag, limit int) []VBucketLag {
+	worst := make([]VBucketLag, len(lags))
+	copy(worst, lags)
+
+	sort.Slice(worst, func(i, j int) bool {
+		if worst[i].SecondsBehind != worst[j].SecondsBehind {
+			return worst[i].SecondsBehind > worst[j].SecondsBehind
+		}
+
+		return worst[i].SeqNoLag > worst[j].SeqNoLag
+	})
+
+	if limit >= 0 && len(worst) > limit {
+		worst = worst[:limit]
+	}
+
+	return worst
+}
@@ -0,0 +1,79 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Trendyol/go-dcp/models"
+)
+
+func TestNewVBucketLag(t *testing.T) {
+	sampledAt := time.Now()
+	unackedEventTime := sampledAt.Add(-10 * time.Second)
+
+	caughtUp := newVBucketLag(1, 100, 100, unackedEventTime, sampledAt)
+	if caughtUp.SecondsBehind != 0 || caughtUp.SeqNoLag != 0 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "caught up", caughtUp)
+	}
+
+	behind := newVBucketLag(2, 100, 150, unackedEventTime, sampledAt)
+	if behind.SecondsBehind != 10 || behind.SeqNoLag != 50 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "10 seconds and 50 seq nos behind", behind)
+	}
+
+	notReceived := newVBucketLag(3, 100, 150, time.Time{}, sampledAt)
+	if notReceived.SecondsBehind != 0 || notReceived.SeqNoLag != 50 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "0 seconds and 50 seq nos behind", notReceived)
+	}
+}
+
+func TestWorstLags(t *testing.T) {
+	lags := []VBucketLag{
+		{VbID: 1, SecondsBehind: 5, SeqNoLag: 1},
+		{VbID: 2, SecondsBehind: 30, SeqNoLag: 1},
+		{VbID: 3, SecondsBehind: 5, SeqNoLag: 10},
+		{VbID: 4},
+	}
+
+	worst := WorstLags(lags, 3)
+
+	if len(worst) != 3 || worst[0].VbID != 2 || worst[1].VbID != 3 || worst[2].VbID != 1 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "[2 3 1]", worst)
+	}
+
+	if lags[0].VbID != 1 {
+		t.Errorf("Lags are sorted in place")
+	}
+}
+
+func TestLagIsMeasuredFromFirstUnackedEvent(t *testing.T) {
+	handledCh := make(chan struct{}, 3)
+
+	s, observer, _ := newTestStream(func(ctx *models.ListenerContext) {
+		if ctx.Event.(models.DcpMutation).SeqNo == 1 {
+			ctx.Ack()
+		}
+		handledCh <- struct{}{}
+	}, time.Second)
+
+	eventTime := time.Now().Add(-time.Hour)
+	for seqNo := uint64(1); seqNo <= 3; seqNo++ {
+		mutation := newTestMutation(seqNo)
+		event := mutation.Event.(models.DcpMutation)
+		event.EventTime = eventTime.Add(time.Duration(seqNo) * time.Minute)
+		mutation.Event = event
+
+		observer.listenerCh <- mutation
+		<-handledCh
+	}
+
+	if unackedEventTime, _ := s.unackedEventTimes.Load(1); !unackedEventTime.Equal(eventTime.Add(2 * time.Minute)) {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", eventTime.Add(2*time.Minute), unackedEventTime)
+	}
+
+	s.Close(false)
+
+	if count := s.unackedEventTimes.Count(); count != 0 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 0, count)
+	}
+}
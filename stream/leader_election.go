@@ -3,6 +3,7 @@ package stream
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/asaskevich/EventBus"
@@ -24,12 +25,15 @@ const (
 	KubernetesLeaderElectionType = "kubernetes"
 )
 
+var ErrUnsupportedLeaderElectionType = errors.New("leader election type is not supported")
+
 type LeaderElection interface {
-	Start()
+	Start(ctx context.Context) error
 	Stop()
 }
 
 type leaderElection struct {
+	logger           logger.Logger
 	rpcServer        servicediscovery.Server
 	serviceDiscovery servicediscovery.ServiceDiscovery
 	bus              EventBus.Bus
@@ -57,55 +61,68 @@ func (l *leaderElection) OnBecomeFollower(leaderIdentity *models.Identity) {
 	l.serviceDiscovery.RemoveAll()
 	l.serviceDiscovery.RemoveLeader()
 
-	leaderClient, err := servicediscovery.NewClient(l.config.LeaderElection.RPC.Port, l.myIdentity, leaderIdentity)
+	leaderClient, err := servicediscovery.NewClient(
+		&l.config.LeaderElection.RPC, l.myIdentity, leaderIdentity, l.serviceDiscovery, l.logger,
+	)
 	if err != nil {
+		l.logger.Error("error while creating leader client: %v", err)
 		return
 	}
 
-	leaderService := servicediscovery.NewService(leaderClient, leaderIdentity.Name, leaderIdentity.ClusterJoinTime)
+	leaderService := servicediscovery.NewService(leaderClient, leaderIdentity)
 
 	l.serviceDiscovery.AssignLeader(leaderService)
 
-	err = leaderClient.Register()
-	if err != nil {
-		logger.Log.Error("error while registering leader client: %v", err)
-		panic(err)
-	}
+	leaderClient.Start()
 }
 
-func (l *leaderElection) Start() {
+func (l *leaderElection) Start(ctx context.Context) error {
 	var kubernetesClient kubernetes.Client
+	var err error
 
 	if l.config.LeaderElection.Type == KubernetesLeaderElectionType {
-		kubernetesClient = kubernetes.NewClient()
+		kubernetesClient, err = kubernetes.NewClient(l.logger)
+		if err != nil {
+			return err
+		}
+
 		l.myIdentity = kubernetesClient.GetIdentity()
+		l.serviceDiscovery.SetMyIdentity(l.myIdentity)
 	} else {
-		err := errors.New("leader election type is not supported")
-		logger.Log.Error("leader election: %s, err: %v", l.config.LeaderElection.Type, err)
-		panic(err)
+		return fmt.Errorf("leader election: %s, err: %w", l.config.LeaderElection.Type, ErrUnsupportedLeaderElectionType)
 	}
 
-	l.rpcServer = servicediscovery.NewServer(l.config.LeaderElection.RPC.Port, l.myIdentity, l.serviceDiscovery)
-	l.rpcServer.Listen()
+	l.elector, err = kubernetes.NewLeaderElector(kubernetesClient, l.config, l.myIdentity, l, l.bus, l.logger)
+	if err != nil {
+		return err
+	}
 
-	if l.config.LeaderElection.Type == KubernetesLeaderElectionType {
-		l.elector = kubernetes.NewLeaderElector(kubernetesClient, l.config, l.myIdentity, l, l.bus)
+	l.rpcServer = servicediscovery.NewServer(&l.config.LeaderElection.RPC, l.myIdentity, l.serviceDiscovery, l.logger)
+	if err := l.rpcServer.Listen(); err != nil {
+		return err
 	}
 
-	l.elector.Run(context.Background())
+	return l.elector.Run(ctx)
 }
 
 func (l *leaderElection) Stop() {
-	l.elector.Close()
-	l.rpcServer.Shutdown()
+	if l.elector != nil {
+		l.elector.Close()
+	}
+
+	if l.rpcServer != nil {
+		l.rpcServer.Shutdown()
+	}
 }
 
 func NewLeaderElection(
 	config *config.Dcp,
 	serviceDiscovery servicediscovery.ServiceDiscovery,
 	bus EventBus.Bus,
+	logger logger.Logger,
 ) LeaderElection {
 	return &leaderElection{
+		logger:           logger,
 		config:           config,
 		serviceDiscovery: serviceDiscovery,
 		newLeaderLock:    &sync.Mutex{},
@@ -1,15 +1,23 @@
 package stream
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/asaskevich/EventBus"
 
+	"golang.org/x/sync/errgroup"
+
 	"github.com/couchbase/gocbcore/v10"
 
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
 	"github.com/Trendyol/go-dcp/wrapper"
 
 	"github.com/Trendyol/go-dcp/config"
@@ -25,24 +33,54 @@ import (
 )
 
 type Stream interface {
-	Open()
+	Open(ctx context.Context) error
 	Rebalance()
-	Save()
+	Save(ctx context.Context)
 	Close(bool)
 	GetOffsets() (*wrapper.ConcurrentSwissMap[uint16, *models.Offset], *wrapper.ConcurrentSwissMap[uint16, bool], bool)
 	GetObserver() couchbase.Observer
 	GetMetric() (*Metric, int)
 	UnmarkDirtyOffsets()
 	GetCheckpointMetric() *CheckpointMetric
+	GetFencingToken() uint64
+	Fence()
+	ResetCheckpointSchedule(interval time.Duration)
+	GetLags() ([]VBucketLag, error)
+	GetHighSeqNoSampler() couchbase.HighSeqNoSampler
+	GetHealth() Health
+	GetTail() *Tail
 }
 
 type Metric struct {
-	ProcessLatency int64
-	DcpLatency     int64
+	// ProcessLatencies observes the seconds the listener takes per collection.
+	ProcessLatencies *CollectionHistograms
+	// DcpLatencies observes the seconds from the event time to its receipt by the observer per collection.
+	DcpLatencies *CollectionHistograms
+	// QueueWaits observes the seconds events wait for the listener per collection.
+	QueueWaits *CollectionHistograms
+	// CheckpointSaveLatency observes the seconds a checkpoint save takes, it is kept across rebalances.
+	CheckpointSaveLatency *Histogram
+	ProcessLatency        int64
+	DcpLatency            int64
+	// Rebalances keeps the latest rebalances, they are kept across rebalances.
+	Rebalances *History[RebalanceRecord]
+	// Errors keeps the latest errors published on the bus of the stream and the failed checkpoint saves.
+	Errors *History[ErrorRecord]
+	// ListenerStalls counts the stalls detected by the listener watchdog.
+	ListenerStalls atomic.Int64
 	Rebalance      int
+	// Fenced counts the rejected checkpoint writes which stopped the streams.
+	Fenced atomic.Uint64
+	// CollectionMetrics counts the events per collection and event type, it is kept across rebalances.
+	CollectionMetrics *couchbase.CollectionMetrics
 }
 
 type stream struct {
+	logger                     logger.Logger
+	tracer                     trace.Tracer
+	tail                       *Tail
+	rebalanceSpan              trace.Span
+	ctx                        context.Context
 	client                     couchbase.Client
 	metadata                   metadata.Metadata
 	checkpoint                 Checkpoint
@@ -61,13 +99,27 @@ type stream struct {
 	bucketInfo                 *couchbase.BucketInfo
 	metric                     *Metric
 	finishStreamWithCloseCh    chan struct{}
+	listenDoneCh               chan struct{}
 	collectionIDs              map[uint32]string
 	offsets                    *wrapper.ConcurrentSwissMap[uint16, *models.Offset]
 	vbIds                      *wrapper.ConcurrentSwissMap[uint16, struct{}]
-	activeStreams              int
+	activeStreams              atomic.Int32
+	fencingToken               atomic.Uint64
+	fencedToken                atomic.Uint64
 	rebalanceLock              sync.Mutex
-	anyDirtyOffset             bool
-	balancing                  bool
+	openLock                   sync.RWMutex
+	anyDirtyOffset             atomic.Bool
+	unackedEventTimes          *wrapper.ConcurrentSwissMap[uint16, time.Time]
+	highSeqNoSampler           couchbase.HighSeqNoSampler
+	reopening                  *wrapper.ConcurrentSwissMap[uint16, struct{}]
+	listenerStartedAt          atomic.Int64
+	listenGeneration           atomic.Uint64
+	lastAckAt                  atomic.Int64
+	stalled                    atomic.Bool
+	watchdogStopCh             chan struct{}
+	watchdogDoneCh             chan struct{}
+	rebalanceStartedAt         time.Time
+	balancing                  atomic.Bool
 	closeWithCancel            bool
 }
 
@@ -76,11 +128,18 @@ func (s *stream) setOffset(vbID uint16, offset *models.Offset, dirty bool) {
 		s.offsets.Store(vbID, offset)
 		s.dirtyOffsets.Store(vbID, dirty)
 	} else {
-		logger.Log.Warn("vbId=%v not belong our vbId range", vbID)
+		s.logger.With("vbId", vbID).Warn("vbId not belong our vbId range")
 	}
 }
 
-func (s *stream) waitAndForward(payload interface{}, offset *models.Offset, vbID uint16, eventTime time.Time) {
+// isListening reports whether the stream is not closed since the listener of the generation is started.
+// A listener which could not be drained on close may resume later, it must not move the offsets of the next stream.
+func (s *stream) isListening(generation uint64) bool {
+	return s.listenGeneration.Load() == generation
+}
+
+//nolint:lll
+func (s *stream) waitAndForward(payload interface{}, offset *models.Offset, vbID uint16, eventTime time.Time, receivedAt time.Time, generation uint64) {
 	if helpers.IsMetadata(payload) {
 		s.setOffset(vbID, offset, false)
 		return
@@ -88,33 +147,82 @@ func (s *stream) waitAndForward(payload interface{}, offset *models.Offset, vbID
 
 	s.metric.DcpLatency = time.Since(eventTime).Milliseconds()
 
+	s.tail.publish(payload)
+
+	s.observeLatencies(payload, eventTime, receivedAt)
+
+	span := s.startEventSpan(payload, offset, vbID, receivedAt)
+
+	// the lag of the vBucket is measured from its first event which is not acked yet
+	if _, ok := s.unackedEventTimes.Load(vbID); !ok {
+		s.unackedEventTimes.Store(vbID, eventTime)
+	}
+
 	ctx := &models.ListenerContext{
-		Commit: s.checkpoint.Save,
-		Event:  payload,
+		SpanContext: span.spanContext(),
+		Commit: func() {
+			s.checkpoint.Save(s.ctx)
+		},
+		Event: payload,
 		Ack: func() {
+			if !s.isListening(generation) {
+				s.logger.With("vbId", vbID).Warn("ack of a closed stream is skipped, the event will be streamed again")
+				return
+			}
+
 			s.setOffset(vbID, offset, true)
-			s.anyDirtyOffset = true
+			s.anyDirtyOffset.Store(true)
+			s.unackedEventTimes.Delete(vbID)
+			s.acked()
+			span.ack()
 		},
 	}
 
 	start := time.Now()
+	s.listenerStartedAt.Store(start.UnixNano())
 
 	s.listener(ctx)
 
-	s.metric.ProcessLatency = time.Since(start).Milliseconds()
+	if s.isListening(generation) {
+		s.listenerStartedAt.Store(0)
+	}
+
+	span.returned()
+
+	processLatency := time.Since(start)
+	s.metric.ProcessLatency = processLatency.Milliseconds()
+	s.metric.ProcessLatencies.Observe(collectionName(payload), processLatency.Seconds())
 }
 
-func (s *stream) listen() {
-	for args := range s.observer.Listen() {
+func (s *stream) observeLatencies(payload interface{}, eventTime time.Time, receivedAt time.Time) {
+	if receivedAt.IsZero() {
+		return
+	}
+
+	collection := collectionName(payload)
+
+	s.metric.DcpLatencies.Observe(collection, receivedAt.Sub(eventTime).Seconds())
+	s.metric.QueueWaits.Observe(collection, time.Since(receivedAt).Seconds())
+}
+
+func (s *stream) listen(listenerCh models.ListenerCh, doneCh chan struct{}, generation uint64) {
+	defer close(doneCh)
+
+	for args := range listenerCh {
+		if !s.isListening(generation) {
+			// the stream is closed before this listener is drained, the events left are streamed again
+			return
+		}
+
 		event := args.Event
 
 		switch v := event.(type) {
 		case models.DcpMutation:
-			s.waitAndForward(v, v.Offset, v.VbID, v.EventTime)
+			s.waitAndForward(v, v.Offset, v.VbID, v.EventTime, args.ReceivedAt, generation)
 		case models.DcpDeletion:
-			s.waitAndForward(v, v.Offset, v.VbID, v.EventTime)
+			s.waitAndForward(v, v.Offset, v.VbID, v.EventTime, args.ReceivedAt, generation)
 		case models.DcpExpiration:
-			s.waitAndForward(v, v.Offset, v.VbID, v.EventTime)
+			s.waitAndForward(v, v.Offset, v.VbID, v.EventTime, args.ReceivedAt, generation)
 		case models.DcpSeqNoAdvanced:
 			s.setOffset(v.VbID, v.Offset, true)
 		default:
@@ -123,14 +231,18 @@ func (s *stream) listen() {
 }
 
 func (s *stream) reopenStream(vbID uint16) {
+	s.reopening.Store(vbID, struct{}{})
+
 	go func(innerVbID uint16) {
-		for {
-			err := s.openStream(innerVbID)
+		defer s.reopening.Delete(innerVbID)
+
+		for s.ctx.Err() == nil {
+			err := s.openStream(s.ctx, innerVbID)
 			if err == nil {
-				logger.Log.Info("re-open stream, vbID: %d", innerVbID)
+				s.logger.With("vbId", innerVbID).Info("re-open stream")
 				break
 			} else {
-				logger.Log.Error("cannot re-open stream, vbID: %d, err: %v", innerVbID, err)
+				s.logger.With("vbId", innerVbID).Error("cannot re-open stream, err: %v", err)
 			}
 
 			time.Sleep(time.Second)
@@ -142,14 +254,14 @@ func (s *stream) listenEnd() {
 	for endContext := range s.observer.ListenEnd() {
 		if !s.closeWithCancel && endContext.Err != nil {
 			if !errors.Is(endContext.Err, gocbcore.ErrDCPStreamClosed) {
-				logger.Log.Error("end stream vbId: %v got error: %v", endContext.Event.VbID, endContext.Err)
+				s.logger.With("vbId", endContext.Event.VbID).Error("end stream got error: %v", endContext.Err)
 			} else {
-				logger.Log.Debug("end stream vbId: %v got error: %v", endContext.Event.VbID, endContext.Err)
+				s.logger.With("vbId", endContext.Event.VbID).Debug("end stream got error: %v", endContext.Err)
 			}
 		}
 
 		if endContext.Err == nil {
-			logger.Log.Debug("end stream vbId: %v", endContext.Event.VbID)
+			s.logger.With("vbId", endContext.Event.VbID).Debug("end stream")
 		}
 
 		if !s.closeWithCancel && endContext.Err != nil &&
@@ -157,72 +269,118 @@ func (s *stream) listenEnd() {
 				errors.Is(endContext.Err, gocbcore.ErrDCPBackfillFailed)) {
 			s.reopenStream(endContext.Event.VbID)
 		} else {
-			s.activeStreams--
-			if s.activeStreams == 0 {
+			if s.activeStreams.Add(-1) == 0 {
 				s.finishStreamWithEndEventCh <- struct{}{}
 			}
 		}
 	}
 }
 
-func (s *stream) Open() {
+// Open streams the vBuckets of this member, ctx is used by every operation of the stream until it is closed.
+func (s *stream) Open(ctx context.Context) error {
+	s.ctx = ctx
+
 	s.eventHandler.BeforeStreamStart()
 
 	vbIds := s.vBucketDiscovery.Get()
+	s.fencingToken.Store(s.vBucketDiscovery.GetMetric().Epoch)
+
+	checkpoint, err := NewCheckpoint(s, vbIds, s.client, s.metadata, s.config, s.tracer, s.logger)
+	if err != nil {
+		return err
+	}
 
 	if !s.config.RollbackMitigation.Disabled {
 		if s.bucketInfo.IsEphemeral() {
-			logger.Log.Info("rollback mitigation is disabled for ephemeral bucket")
+			s.logger.Info("rollback mitigation is disabled for ephemeral bucket")
 			s.config.RollbackMitigation.Disabled = true
 		} else {
-			s.rollbackMitigation = couchbase.NewRollbackMitigation(s.client, s.config, vbIds, s.bus)
-			s.rollbackMitigation.Start()
+			s.rollbackMitigation = couchbase.NewRollbackMitigation(s.client, s.config, vbIds, s.bus, s.logger)
+			if err := s.rollbackMitigation.Start(ctx); err != nil {
+				return err
+			}
 		}
 	}
 
-	s.activeStreams = len(vbIds)
+	s.activeStreams.Store(int32(len(vbIds)))
 
-	s.checkpoint = NewCheckpoint(s, vbIds, s.client, s.metadata, s.config)
-	s.vbIds = wrapper.CreateConcurrentSwissMap[uint16, struct{}](1024)
+	vbIDs := wrapper.CreateConcurrentSwissMap[uint16, struct{}](1024)
 	for _, vbID := range vbIds {
-		s.vbIds.Store(vbID, struct{}{})
+		vbIDs.Store(vbID, struct{}{})
+	}
+
+	s.openLock.Lock()
+	s.checkpoint, s.vbIds = checkpoint, vbIDs
+	s.openLock.Unlock()
+
+	offsets, dirtyOffsets, anyDirtyOffset, err := s.checkpoint.Load(ctx)
+	if err != nil {
+		return err
 	}
-	s.offsets, s.dirtyOffsets, s.anyDirtyOffset = s.checkpoint.Load()
-	s.observer = couchbase.NewObserver(s.config, s.collectionIDs, s.bus)
 
-	s.openAllStreams(vbIds)
+	s.openLock.Lock()
+	s.offsets, s.dirtyOffsets = offsets, dirtyOffsets
+	s.openLock.Unlock()
+	s.anyDirtyOffset.Store(anyDirtyOffset)
+
+	observer, err := couchbase.NewObserver(s.config, s.collectionIDs, s.metric.CollectionMetrics, s.bus, s.logger)
+	if err != nil {
+		return err
+	}
+
+	s.openLock.Lock()
+	s.observer = observer
+	s.openLock.Unlock()
+
+	// the listener is started with the observer, so a stream which fails to open can be drained as well
+	s.listenDoneCh = make(chan struct{})
+	go s.listen(s.observer.Listen(), s.listenDoneCh, s.listenGeneration.Load())
+
+	if err := s.openAllStreams(ctx, vbIds); err != nil {
+		return err
+	}
 
 	go s.listenEnd()
-	go s.listen()
 
-	logger.Log.Info("stream started")
+	s.highSeqNoSampler.Start()
+	s.startWatchdog()
+
+	s.logger.Info("stream started")
 	s.eventHandler.AfterStreamStart()
 
-	s.checkpoint.StartSchedule()
+	s.checkpoint.StartSchedule(ctx)
 
 	go s.wait()
+
+	return nil
 }
 
 func (s *stream) Rebalance() {
-	if s.balancing && s.rebalanceTimer != nil {
+	if s.balancing.Load() && s.rebalanceTimer != nil {
 		// Is rebalance timer triggered already
 		if s.rebalanceTimer.Stop() {
 			s.rebalanceTimer.Reset(s.config.Dcp.Group.Membership.RebalanceDelay)
-			logger.Log.Info("latest rebalance time is resetted")
+			s.logger.Info("latest rebalance time is resetted")
 		} else {
 			s.rebalanceTimer = time.AfterFunc(s.config.Dcp.Group.Membership.RebalanceDelay, s.Rebalance)
-			logger.Log.Info("latest rebalance time is reassigned")
+			s.logger.Info("latest rebalance time is reassigned")
 		}
 		return
 	}
-	logger.Log.Info("rebalance starting")
+	s.logger.Info("rebalance starting")
 	s.rebalanceLock.Lock()
 
+	s.rebalanceStartedAt = time.Now()
+
+	// the span lasts until the stream is opened again after the rebalance delay
+	ctx, span := s.tracer.Start(s.ctx, RebalanceSpanName, trace.WithNewRoot())
+	span.SetAttributes(BucketKey.String(s.config.BucketName), GroupKey.String(s.config.Dcp.Group.Name))
+	s.rebalanceSpan = span
+
 	s.eventHandler.BeforeRebalanceStart()
 
-	if !s.balancing {
-		s.balancing = true
-		s.Save()
+	if s.balancing.CompareAndSwap(false, true) {
+		s.Save(ctx)
 		s.Close(false)
 	}
 
@@ -230,56 +388,112 @@ func (s *stream) Rebalance() {
 
 	s.rebalanceTimer = time.AfterFunc(s.config.Dcp.Group.Membership.RebalanceDelay, s.rebalance)
 
-	logger.Log.Info("rebalance will start after %v", s.config.Dcp.Group.Membership.RebalanceDelay)
+	s.logger.Info("rebalance will start after %v", s.config.Dcp.Group.Membership.RebalanceDelay)
 }
 
 func (s *stream) rebalance() {
-	logger.Log.Info("reassigning vbuckets and opening stream is starting")
+	if fencedToken := s.fencedToken.Load(); fencedToken != 0 && s.vBucketDiscovery.GetEpoch() <= fencedToken {
+		s.logger.Info("waiting for a newer assignment than fencing token: %v", fencedToken)
+		s.rebalanceSpan.AddEvent("waiting for a newer assignment")
+		s.rebalanceTimer = time.AfterFunc(s.config.Dcp.Group.Membership.RebalanceDelay, s.rebalance)
+		return
+	}
 
-	defer s.rebalanceLock.Unlock()
+	s.fencedToken.Store(0)
+
+	s.logger.Info("reassigning vbuckets and opening stream is starting")
 
 	s.eventHandler.BeforeRebalanceEnd()
-	s.Open()
+
+	if err := s.Open(s.ctx); err != nil {
+		s.rebalanceFailed(err)
+		return
+	}
+
+	defer s.rebalanceLock.Unlock()
+	defer s.rebalanceSpan.End()
+
 	s.metric.Rebalance++
+	s.metric.Rebalances.Add(RebalanceRecord{
+		StartedAt:    s.rebalanceStartedAt,
+		FinishedAt:   time.Now(),
+		FencingToken: s.fencingToken.Load(),
+		VBuckets:     s.vbIds.Count(),
+	})
+	s.rebalanceSpan.SetAttributes(FencingKey.Int64(int64(s.fencingToken.Load())))
 
-	logger.Log.Info("rebalance is finished")
-	s.balancing = false
+	s.logger.Info("rebalance is finished")
+	s.balancing.Store(false)
 	s.eventHandler.AfterRebalanceEnd()
 }
 
-func (s *stream) Save() {
-	s.checkpoint.Save()
+// rebalanceFailed closes the partly opened stream and opens it again after the rebalance delay,
+// the rebalance lock is kept until the stream is opened.
+func (s *stream) rebalanceFailed(err error) {
+	s.rebalanceSpan.RecordError(err)
+	s.rebalanceSpan.SetStatus(codes.Error, "cannot open stream")
+	s.bus.Publish(helpers.ErrorBusEventName, &models.OpError{Op: "rebalance", Err: err})
+	s.metric.Rebalances.Add(RebalanceRecord{
+		StartedAt: s.rebalanceStartedAt, FinishedAt: time.Now(), Error: err.Error(),
+	})
+
+	s.Close(false)
+
+	// wait is not started by the failed open, so the signal of the close is not received by anyone
+	select {
+	case <-s.finishStreamWithCloseCh:
+	default:
+	}
+
+	if s.ctx.Err() != nil {
+		s.logger.Error("cannot open stream after rebalance, the stream is stopped: %v", err)
+		s.rebalanceSpan.End()
+		s.rebalanceLock.Unlock()
+
+		return
+	}
+
+	s.logger.Error("cannot open stream after rebalance, retrying after %v: %v", s.config.Dcp.Group.Membership.RebalanceDelay, err)
+	s.rebalanceTimer = time.AfterFunc(s.config.Dcp.Group.Membership.RebalanceDelay, s.rebalance)
 }
 
-func (s *stream) openStream(vbID uint16) error {
+func (s *stream) Save(ctx context.Context) {
+	s.checkpoint.Save(ctx)
+}
+
+func (s *stream) openStream(ctx context.Context, vbID uint16) error {
 	offset, exist := s.offsets.Load(vbID)
 	if !exist {
 		err := fmt.Errorf("vbID: %d not found on offset map", vbID)
-		logger.Log.Error("error while opening stream, err: %v", err)
+		s.logger.Error("error while opening stream, err: %v", err)
 		return err
 	}
-	return s.client.OpenStream(vbID, s.collectionIDs, offset, s.observer)
+	return s.client.OpenStream(ctx, vbID, s.collectionIDs, offset, s.observer)
 }
 
-func (s *stream) openAllStreams(vbIds []uint16) {
-	openWg := &sync.WaitGroup{}
-	openWg.Add(len(vbIds))
+func (s *stream) openAllStreams(ctx context.Context, vbIds []uint16) error {
+	eg, ctx := errgroup.WithContext(ctx)
 
 	for _, vbID := range vbIds {
-		go func(innerVbId uint16) {
-			err := s.openStream(innerVbId)
+		innerVbID := vbID
+
+		eg.Go(func() error {
+			err := s.openStream(ctx, innerVbID)
 			if err != nil {
-				logger.Log.Error("cannot open stream, vbID: %d, err: %v", innerVbId, err)
-				panic(err)
+				return fmt.Errorf("cannot open stream, vbID: %d, err: %w", innerVbID, err)
 			}
-			openWg.Done()
-		}(vbID)
+
+			return nil
+		})
 	}
 
-	openWg.Wait()
+	return eg.Wait()
 }
 
 func (s *stream) closeAllStreams(internal bool) {
+	// streams are closed even if the context of the stream is cancelled
+	ctx := context.WithoutCancel(s.ctx)
+
 	var wg sync.WaitGroup
 	wg.Add(s.offsets.Count())
 
@@ -290,9 +504,9 @@ func (s *stream) closeAllStreams(internal bool) {
 				// todo: this is not a good way to close stream
 				s.observer.End(models.DcpStreamEnd{VbID: vbID}, nil)
 			} else {
-				err := s.client.CloseStream(vbID)
+				err := s.client.CloseStream(ctx, vbID)
 				if err != nil {
-					logger.Log.Error("cannot close stream, vbID: %d, err: %v", vbID, err)
+					s.logger.With("vbId", vbID).Error("cannot close stream, err: %v", err)
 				}
 			}
 		}(vbID)
@@ -308,7 +522,7 @@ func (s *stream) wait() {
 	case <-s.finishStreamWithEndEventCh:
 	}
 
-	if !s.balancing {
+	if !s.balancing.Load() {
 		close(s.stopCh)
 	}
 }
@@ -316,13 +530,22 @@ func (s *stream) wait() {
 func (s *stream) Close(closeWithCancel bool) {
 	s.closeWithCancel = closeWithCancel
 
-	s.eventHandler.BeforeStreamStop()
+	// the latest sample is kept, so it gets stale while the stream is closed
+	s.highSeqNoSampler.Stop()
+	s.stopWatchdog()
 
-	if !s.config.RollbackMitigation.Disabled {
-		s.rollbackMitigation.Stop()
+	if s.observer == nil {
+		// stream is not opened or failed while opening
+		s.stopRollbackMitigation()
+		return
 	}
 
-	s.observer.Close()
+	s.eventHandler.BeforeStreamStop()
+
+	// new events are skipped from now on, events buffered before are drained
+	s.observer.Stop()
+
+	s.stopRollbackMitigation()
 
 	if s.checkpoint != nil {
 		s.checkpoint.StopSchedule()
@@ -331,36 +554,124 @@ func (s *stream) Close(closeWithCancel bool) {
 	disableStreamEndByClient := s.version.Lower(couchbase.SrvVer550)
 	s.closeAllStreams(disableStreamEndByClient)
 
+	s.observer.Close()
+	s.drain()
+
+	// a listener which is not drained is stuck, it may resume after the stream is opened again by a restart
+	s.listenGeneration.Add(1)
+	s.listenerStartedAt.Store(0)
+
+	if s.checkpoint != nil && s.config.Checkpoint.Type == CheckpointTypeAuto {
+		// the final checkpoint is saved even if the context of the stream is cancelled
+		s.checkpoint.Save(context.WithoutCancel(s.ctx))
+	}
+
 	s.finishStreamWithCloseCh <- struct{}{}
 	s.observer.CloseEnd()
-	s.observer = nil
 
+	s.openLock.Lock()
+	s.observer = nil
 	s.offsets = wrapper.CreateConcurrentSwissMap[uint16, *models.Offset](1024)
 	s.dirtyOffsets = wrapper.CreateConcurrentSwissMap[uint16, bool](1024)
+	s.openLock.Unlock()
 
-	logger.Log.Info("stream stopped")
+	// the vBuckets may be assigned to another member, the events which are not acked are streamed again
+	s.unackedEventTimes.Clear()
+
+	s.logger.Info("stream stopped")
 	s.eventHandler.AfterStreamStop()
 }
 
+// drain waits for the listener to handle and ack the buffered events, at most dcp.listener.drainTimeout.
+func (s *stream) drain() {
+	timeout := time.NewTimer(s.config.Dcp.Listener.DrainTimeout)
+	defer timeout.Stop()
+
+	select {
+	case <-s.listenDoneCh:
+		s.logger.Debug("listener drained")
+	case <-timeout.C:
+		s.logger.Warn(
+			"listener is not drained in %v, events which are not acked will be streamed again",
+			s.config.Dcp.Listener.DrainTimeout,
+		)
+	}
+}
+
+func (s *stream) stopRollbackMitigation() {
+	if s.rollbackMitigation != nil {
+		s.rollbackMitigation.Stop()
+		s.rollbackMitigation = nil
+	}
+}
+
 func (s *stream) GetOffsets() (*wrapper.ConcurrentSwissMap[uint16, *models.Offset], *wrapper.ConcurrentSwissMap[uint16, bool], bool) {
-	return s.offsets, s.dirtyOffsets, s.anyDirtyOffset
+	s.openLock.RLock()
+	defer s.openLock.RUnlock()
+
+	return s.offsets, s.dirtyOffsets, s.anyDirtyOffset.Load()
+}
+
+func (s *stream) GetTail() *Tail {
+	return s.tail
 }
 
 func (s *stream) GetObserver() couchbase.Observer {
+	s.openLock.RLock()
+	defer s.openLock.RUnlock()
+
 	return s.observer
 }
 
 func (s *stream) GetMetric() (*Metric, int) {
-	return s.metric, s.activeStreams
+	return s.metric, int(s.activeStreams.Load())
 }
 
 func (s *stream) GetCheckpointMetric() *CheckpointMetric {
+	s.openLock.RLock()
+	defer s.openLock.RUnlock()
+
 	return s.checkpoint.GetMetric()
 }
 
+func (s *stream) GetFencingToken() uint64 {
+	return s.fencingToken.Load()
+}
+
+// Fence is called when a checkpoint write is rejected because of a stale fencing token.
+// Uncommitted offsets are dropped and streams stay closed until a newer assignment arrives.
+func (s *stream) Fence() {
+	fencingToken := s.fencingToken.Load()
+	if fencingToken == 0 || s.balancing.Load() || s.closeWithCancel {
+		return
+	}
+
+	// the token is fenced once, by the first of the concurrent rejected writes
+	fencedToken := s.fencedToken.Load()
+	if fencedToken == fencingToken || !s.fencedToken.CompareAndSwap(fencedToken, fencingToken) {
+		return
+	}
+
+	s.logger.Warn("fencing token: %v is stale, stopping streams", fencingToken)
+
+	s.metric.Fenced.Add(1)
+	s.UnmarkDirtyOffsets()
+
+	go s.Rebalance()
+}
+
+// ResetCheckpointSchedule applies a reloaded checkpoint.interval, it is applied on the next open if the stream is closed.
+func (s *stream) ResetCheckpointSchedule(interval time.Duration) {
+	if s.checkpoint != nil {
+		s.checkpoint.ResetSchedule(interval)
+	}
+}
+
+// UnmarkDirtyOffsets is called while the listener may be acking, the map is cleared in place so acks are not
+// written to a replaced map.
 func (s *stream) UnmarkDirtyOffsets() {
-	s.anyDirtyOffset = false
-	s.dirtyOffsets = wrapper.CreateConcurrentSwissMap[uint16, bool](1024)
+	s.anyDirtyOffset.Store(false)
+	s.dirtyOffsets.Clear()
 }
 
 func NewStream(client couchbase.Client,
@@ -374,8 +685,22 @@ func NewStream(client couchbase.Client,
 	stopCh chan struct{},
 	bus EventBus.Bus,
 	eventHandler models.EventHandler,
+	tracer trace.Tracer,
+	tail *Tail,
+	logger logger.Logger,
 ) Stream {
-	return &stream{
+	if tracer == nil {
+		tracer = NewTracer(nil)
+	}
+
+	s := &stream{
+		tracer:                     tracer,
+		tail:                       tail,
+		rebalanceSpan:              noop.Span{},
+		unackedEventTimes:          wrapper.CreateConcurrentSwissMap[uint16, time.Time](1024),
+		reopening:                  wrapper.CreateConcurrentSwissMap[uint16, struct{}](1024),
+		highSeqNoSampler:           couchbase.NewHighSeqNoSampler(client, &config.Metric.HighSeqNo, logger),
+		logger:                     logger,
 		client:                     client,
 		metadata:                   metadata,
 		listener:                   listener,
@@ -389,6 +714,24 @@ func NewStream(client couchbase.Client,
 		stopCh:                     stopCh,
 		bus:                        bus,
 		eventHandler:               eventHandler,
-		metric:                     &Metric{},
+		metric: &Metric{
+			ProcessLatencies:      NewCollectionHistograms(LatencyBuckets),
+			DcpLatencies:          NewCollectionHistograms(LatencyBuckets),
+			QueueWaits:            NewCollectionHistograms(LatencyBuckets),
+			CheckpointSaveLatency: NewHistogram(LatencyBuckets),
+			Rebalances:            NewHistory[RebalanceRecord](RebalanceHistorySize),
+			Errors:                NewHistory[ErrorRecord](ErrorHistorySize),
+			CollectionMetrics:     couchbase.NewCollectionMetrics(config.ScopeName, config.Metric.CollectionLabelLimit),
+		},
+	}
+
+	if err := bus.SubscribeAsync(helpers.ErrorBusEventName, s.recordError, false); err != nil {
+		logger.Error("cannot subscribe to error event: %v", err)
 	}
+
+	return s
+}
+
+func (s *stream) recordError(opErr *models.OpError) {
+	s.metric.Errors.Add(ErrorRecord{Time: time.Now(), Op: opErr.Op, Error: opErr.Err.Error()})
 }
@@ -0,0 +1,223 @@
+package stream
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asaskevich/EventBus"
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/sirupsen/logrus"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/couchbase"
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/models"
+	"github.com/Trendyol/go-dcp/wrapper"
+)
+
+type testObserver struct {
+	couchbase.Observer
+	listenerCh models.ListenerCh
+}
+
+func (o *testObserver) Listen() models.ListenerCh {
+	return o.listenerCh
+}
+
+func (o *testObserver) Stop() {}
+
+func (o *testObserver) End(_ models.DcpStreamEnd, _ error) {}
+
+func (o *testObserver) Close() {
+	close(o.listenerCh)
+}
+
+func (o *testObserver) CloseEnd() {}
+
+// testCheckpoint records the offsets acked when it is saved.
+type testCheckpoint struct {
+	Checkpoint
+	stream *stream
+	saved  map[uint16]uint64
+	lock   sync.Mutex
+}
+
+func (c *testCheckpoint) Save(_ context.Context) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.saved = map[uint16]uint64{}
+	c.stream.dirtyOffsets.Range(func(vbID uint16, dirty bool) bool {
+		if offset, ok := c.stream.offsets.Load(vbID); ok && dirty {
+			c.saved[vbID] = offset.SeqNo
+		}
+		return true
+	})
+}
+
+func (c *testCheckpoint) StopSchedule() {}
+
+func (c *testCheckpoint) GetMetric() *CheckpointMetric {
+	return &CheckpointMetric{}
+}
+
+type testHighSeqNoSampler struct {
+	couchbase.HighSeqNoSampler
+}
+
+func (s *testHighSeqNoSampler) Stop() {}
+
+func newTestStream(listener models.Listener, drainTimeout time.Duration) (*stream, *testObserver, *testCheckpoint) {
+	c := &config.Dcp{}
+	c.Checkpoint.Type = CheckpointTypeAuto
+	c.Dcp.Listener.DrainTimeout = drainTimeout
+
+	observer := &testObserver{listenerCh: make(models.ListenerCh, 10)}
+
+	s := NewStream(
+		nil, nil, c, &couchbase.Version{Major: 5}, nil, nil, listener, nil, make(chan struct{}, 1), EventBus.New(),
+		models.DefaultEventHandler, nil, nil, &logger.Loggers{Logrus: logrus.New()},
+	).(*stream)
+	s.ctx = context.Background()
+	s.highSeqNoSampler = &testHighSeqNoSampler{}
+	s.observer = observer
+	s.vbIds = wrapper.CreateConcurrentSwissMap[uint16, struct{}](1)
+	s.vbIds.Store(1, struct{}{})
+	s.offsets = wrapper.CreateConcurrentSwissMap[uint16, *models.Offset](1)
+	s.offsets.Store(1, &models.Offset{})
+	s.dirtyOffsets = wrapper.CreateConcurrentSwissMap[uint16, bool](1)
+
+	checkpoint := &testCheckpoint{stream: s}
+	s.checkpoint = checkpoint
+
+	s.listenDoneCh = make(chan struct{})
+	go s.listen(observer.Listen(), s.listenDoneCh, s.listenGeneration.Load())
+
+	return s, observer, checkpoint
+}
+
+func newTestMutation(seqNo uint64) models.ListenerArgs {
+	return models.ListenerArgs{
+		Event: models.DcpMutation{
+			DcpMutation: &gocbcore.DcpMutation{VbID: 1, SeqNo: seqNo},
+			Offset:      &models.Offset{SeqNo: seqNo},
+			EventTime:   time.Now(),
+		},
+	}
+}
+
+func TestCloseDrainsBufferedEventsBeforeFinalSave(t *testing.T) {
+	var handled []uint64
+
+	s, observer, checkpoint := newTestStream(func(ctx *models.ListenerContext) {
+		time.Sleep(10 * time.Millisecond)
+		handled = append(handled, ctx.Event.(models.DcpMutation).SeqNo)
+		ctx.Ack()
+	}, time.Second)
+
+	for seqNo := uint64(1); seqNo <= 3; seqNo++ {
+		observer.listenerCh <- newTestMutation(seqNo)
+	}
+
+	s.Close(false)
+
+	if len(handled) != 3 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 3, handled)
+	}
+
+	if checkpoint.saved[1] != 3 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 3, checkpoint.saved[1])
+	}
+}
+
+func TestCloseSavesAfterDrainTimeout(t *testing.T) {
+	releaseCh := make(chan struct{})
+	defer close(releaseCh)
+
+	s, observer, checkpoint := newTestStream(func(ctx *models.ListenerContext) {
+		if ctx.Event.(models.DcpMutation).SeqNo == 2 {
+			<-releaseCh
+		}
+		ctx.Ack()
+	}, 50*time.Millisecond)
+
+	for seqNo := uint64(1); seqNo <= 3; seqNo++ {
+		observer.listenerCh <- newTestMutation(seqNo)
+	}
+
+	closedCh := make(chan struct{})
+	go func() {
+		s.Close(false)
+		close(closedCh)
+	}()
+
+	select {
+	case <-closedCh:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Unexpected result. Expected: %v, Got: %v", "closed after drain timeout", "not closed")
+	}
+
+	checkpoint.lock.Lock()
+	defer checkpoint.lock.Unlock()
+
+	if checkpoint.saved[1] != 1 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 1, checkpoint.saved[1])
+	}
+}
+
+func TestStuckListenerDoesNotMoveOffsetsAfterClose(t *testing.T) {
+	releaseCh := make(chan struct{})
+
+	var handled []uint64
+
+	s, observer, _ := newTestStream(func(ctx *models.ListenerContext) {
+		seqNo := ctx.Event.(models.DcpMutation).SeqNo
+		handled = append(handled, seqNo)
+		if seqNo == 2 {
+			<-releaseCh
+		}
+		ctx.Ack()
+	}, 50*time.Millisecond)
+
+	for seqNo := uint64(1); seqNo <= 3; seqNo++ {
+		observer.listenerCh <- newTestMutation(seqNo)
+	}
+
+	listenDoneCh := s.listenDoneCh
+
+	s.Close(false)
+
+	// the stream is opened again by the restart of the watchdog while the listener is stuck
+	s.offsets.Store(1, &models.Offset{SeqNo: 5})
+
+	close(releaseCh)
+	<-listenDoneCh
+
+	if len(handled) != 2 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", []uint64{1, 2}, handled)
+	}
+
+	if offset, _ := s.offsets.Load(1); offset.SeqNo != 5 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 5, offset.SeqNo)
+	}
+
+	if dirty, _ := s.dirtyOffsets.Load(1); dirty || s.listenerStartedAt.Load() != 0 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "not dirty and not busy", dirty)
+	}
+}
+
+func TestUnmarkDirtyOffsetsKeepsMap(t *testing.T) {
+	s := &stream{dirtyOffsets: wrapper.CreateConcurrentSwissMap[uint16, bool](1024)}
+	s.dirtyOffsets.Store(1, true)
+	s.anyDirtyOffset.Store(true)
+
+	dirtyOffsets := s.dirtyOffsets
+
+	s.UnmarkDirtyOffsets()
+
+	if s.dirtyOffsets != dirtyOffsets || dirtyOffsets.Count() != 0 || s.anyDirtyOffset.Load() {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "same map without dirty offsets", dirtyOffsets.ToMap())
+	}
+}
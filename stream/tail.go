@@ -0,0 +1,201 @@
+package stream
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Trendyol/go-dcp/models"
+)
+
+// TailEvent is a copy of an event sent to the tail subscribers, it is not acked or checkpointed.
+type TailEvent struct {
+	EventTime  time.Time `json:"eventTime"`
+	Bucket     string    `json:"bucket"`
+	Type       string    `json:"type"`
+	Collection string    `json:"collection"`
+	Key        string    `json:"key"`
+	// Value is the value as a string, it is cut at TailFilter.MaxValueSize and left out when redacted.
+	Value     string `json:"value,omitempty"`
+	ValueSize int    `json:"valueSize"`
+	SeqNo     uint64 `json:"seqNo"`
+	RevNo     uint64 `json:"revNo"`
+	Cas       uint64 `json:"cas"`
+	VbID      uint16 `json:"vbId"`
+	Truncated bool   `json:"truncated,omitempty"`
+	Redacted  bool   `json:"redacted,omitempty"`
+}
+
+// TailFilter selects the events of a tail subscriber, empty fields match every event.
+type TailFilter struct {
+	KeyPrefix   string
+	Collections []string
+	VbIDs       []uint16
+	// MaxValueSize is how many bytes of a value are sent, 0 or less sends the whole value.
+	MaxValueSize int
+	Redact       bool
+}
+
+func (f *TailFilter) match(vbID uint16, collection string, key []byte) bool {
+	if f.KeyPrefix != "" && !strings.HasPrefix(string(key), f.KeyPrefix) {
+		return false
+	}
+
+	if len(f.Collections) > 0 && !contains(f.Collections, collection) {
+		return false
+	}
+
+	return len(f.VbIDs) == 0 || contains(f.VbIDs, vbID)
+}
+
+func (f *TailFilter) value(value []byte) (string, bool) {
+	if f.MaxValueSize > 0 && len(value) > f.MaxValueSize {
+		return string(value[:f.MaxValueSize]), true
+	}
+
+	return string(value), false
+}
+
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TailSubscription receives the events matching its filter until it is closed.
+type TailSubscription struct {
+	tail    *Tail
+	ch      chan TailEvent
+	filter  TailFilter
+	dropped atomic.Uint64
+}
+
+// Events is closed when the subscription is closed.
+func (s *TailSubscription) Events() <-chan TailEvent {
+	return s.ch
+}
+
+// Dropped returns how many events are dropped because the subscriber is slower than the stream.
+func (s *TailSubscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+func (s *TailSubscription) Close() {
+	s.tail.unsubscribe(s)
+}
+
+// Tail copies the events handed to the listeners to its subscribers. It never blocks the listener,
+// events are dropped for a subscriber whose buffer is full.
+type Tail struct {
+	subscribers map[*TailSubscription]struct{}
+	lock        sync.RWMutex
+	count       atomic.Int32
+}
+
+func (t *Tail) Subscribe(filter TailFilter, bufferSize int) *TailSubscription {
+	subscription := &TailSubscription{
+		tail:   t,
+		ch:     make(chan TailEvent, bufferSize),
+		filter: filter,
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.subscribers[subscription] = struct{}{}
+	t.count.Add(1)
+
+	return subscription
+}
+
+func (t *Tail) unsubscribe(subscription *TailSubscription) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.subscribers[subscription]; !ok {
+		return
+	}
+
+	delete(t.subscribers, subscription)
+	t.count.Add(-1)
+	close(subscription.ch)
+}
+
+// publish is called by the listener goroutine, it costs an atomic load while there is no subscriber.
+func (t *Tail) publish(payload interface{}) {
+	if t == nil || t.count.Load() == 0 {
+		return
+	}
+
+	event, key, value, ok := newTailEvent(payload)
+	if !ok {
+		return
+	}
+
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	for subscription := range t.subscribers {
+		if !subscription.filter.match(event.VbID, event.Collection, key) {
+			continue
+		}
+
+		subscriberEvent := event
+		if subscription.filter.Redact {
+			subscriberEvent.Redacted = len(value) > 0
+		} else {
+			subscriberEvent.Value, subscriberEvent.Truncated = subscription.filter.value(value)
+		}
+
+		select {
+		case subscription.ch <- subscriberEvent:
+		default:
+			subscription.dropped.Add(1)
+		}
+	}
+}
+
+func newTailEvent(payload interface{}) (TailEvent, []byte, []byte, bool) {
+	var event TailEvent
+	var key, value []byte
+
+	switch v := payload.(type) {
+	case models.DcpMutation:
+		event = TailEvent{
+			EventTime: v.EventTime, Bucket: v.BucketName, Collection: v.CollectionName,
+			SeqNo: v.SeqNo, RevNo: v.RevNo, Cas: v.Cas, VbID: v.VbID,
+		}
+		key, value = v.Key, v.Value
+	case models.DcpDeletion:
+		event = TailEvent{
+			EventTime: v.EventTime, Bucket: v.BucketName, Collection: v.CollectionName,
+			SeqNo: v.SeqNo, RevNo: v.RevNo, Cas: v.Cas, VbID: v.VbID,
+		}
+		key, value = v.Key, v.Value
+	case models.DcpExpiration:
+		event = TailEvent{
+			EventTime: v.EventTime, Bucket: v.BucketName, Collection: v.CollectionName,
+			SeqNo: v.SeqNo, RevNo: v.RevNo, Cas: v.Cas, VbID: v.VbID,
+		}
+		key = v.Key
+	default:
+		return event, nil, nil, false
+	}
+
+	event.Type, _ = eventInfo(payload)
+	event.Key = string(key)
+	event.ValueSize = len(value)
+
+	return event, key, value, true
+}
+
+func NewTail() *Tail {
+	return &Tail{
+		subscribers: map[*TailSubscription]struct{}{},
+	}
+}
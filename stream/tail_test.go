@@ -0,0 +1,100 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/couchbase/gocbcore/v10"
+
+	"github.com/Trendyol/go-dcp/models"
+)
+
+func newTailMutation(vbID uint16, collection string, key string, value string) models.DcpMutation {
+	return models.DcpMutation{
+		DcpMutation: &gocbcore.DcpMutation{
+			VbID:  vbID,
+			SeqNo: 10,
+			Key:   []byte(key),
+			Value: []byte(value),
+		},
+		CollectionName: collection,
+		BucketName:     "bucket",
+	}
+}
+
+func TestTailFilter(t *testing.T) {
+	tail := NewTail()
+
+	subscription := tail.Subscribe(TailFilter{
+		KeyPrefix:    "user::",
+		Collections:  []string{"users"},
+		VbIDs:        []uint16{1, 2},
+		MaxValueSize: 4,
+	}, 10)
+
+	tail.publish(newTailMutation(1, "users", "user::1", `{"name":"john"}`))
+	tail.publish(newTailMutation(3, "users", "user::2", `{}`))
+	tail.publish(newTailMutation(2, "orders", "user::3", `{}`))
+	tail.publish(newTailMutation(2, "users", "order::4", `{}`))
+
+	subscription.Close()
+
+	var events []TailEvent
+	for event := range subscription.Events() {
+		events = append(events, event)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("Unexpected result. Expected: %v, Got: %v", 1, len(events))
+	}
+
+	event := events[0]
+	if event.Key != "user::1" || event.Type != "mutation" || event.Bucket != "bucket" || event.VbID != 1 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %+v", "user::1 mutation", event)
+	}
+
+	if event.Value != `{"na` || !event.Truncated || event.ValueSize != 15 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %+v", "truncated value", event)
+	}
+}
+
+func TestTailRedact(t *testing.T) {
+	tail := NewTail()
+
+	subscription := tail.Subscribe(TailFilter{Redact: true}, 10)
+
+	tail.publish(newTailMutation(1, "users", "user::1", `{"password":"secret"}`))
+
+	event := <-subscription.Events()
+	if event.Value != "" || !event.Redacted || event.ValueSize != 21 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %+v", "redacted value", event)
+	}
+
+	subscription.Close()
+}
+
+func TestTailDropsWhenSubscriberIsSlow(t *testing.T) {
+	tail := NewTail()
+
+	subscription := tail.Subscribe(TailFilter{}, 1)
+	defer subscription.Close()
+
+	for i := 0; i < 3; i++ {
+		tail.publish(newTailMutation(1, "users", "user::1", `{}`))
+	}
+
+	if dropped := subscription.Dropped(); dropped != 2 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 2, dropped)
+	}
+}
+
+func TestTailWithoutSubscriber(t *testing.T) {
+	var tail *Tail
+	tail.publish(newTailMutation(1, "users", "user::1", `{}`))
+
+	tail = NewTail()
+	subscription := tail.Subscribe(TailFilter{}, 1)
+	subscription.Close()
+	subscription.Close()
+
+	tail.publish(newTailMutation(1, "users", "user::1", `{}`))
+}
@@ -0,0 +1,133 @@
+package stream
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/Trendyol/go-dcp/models"
+)
+
+// TracerName is the instrumentation name of the spans created by the connector.
+const TracerName = "github.com/Trendyol/go-dcp"
+
+const (
+	EventSpanName          = "dcp.event"
+	CheckpointSaveSpanName = "dcp.checkpoint.save"
+	RebalanceSpanName      = "dcp.rebalance"
+)
+
+const (
+	BucketKey     = attribute.Key("dcp.bucket")
+	GroupKey      = attribute.Key("dcp.group")
+	VbIDKey       = attribute.Key("dcp.vbucket.id")
+	SeqNoKey      = attribute.Key("dcp.seqno")
+	EventTypeKey  = attribute.Key("dcp.event.type")
+	CollectionKey = attribute.Key("dcp.collection")
+	DirtyKey      = attribute.Key("dcp.checkpoint.dirty_vbuckets")
+	FencingKey    = attribute.Key("dcp.fencing_token")
+)
+
+// NewTracer returns the tracer of the connector on provider, spans are not recorded when provider is nil.
+func NewTracer(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		provider = noop.NewTracerProvider()
+	}
+
+	return provider.Tracer(TracerName)
+}
+
+// eventSpan covers an event from its receipt by the observer until the listener returned and acked it,
+// whichever is later. Events which are never acked are not exported.
+type eventSpan struct {
+	span      trace.Span
+	remaining atomic.Int32
+	acked     atomic.Bool
+}
+
+func (s *stream) startEventSpan(payload interface{}, offset *models.Offset, vbID uint16, receivedAt time.Time) *eventSpan {
+	dequeuedAt := time.Now()
+	if receivedAt.IsZero() {
+		receivedAt = dequeuedAt
+	}
+
+	_, span := s.tracer.Start(s.ctx, EventSpanName,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithTimestamp(receivedAt),
+		trace.WithNewRoot(),
+	)
+
+	if !span.IsRecording() {
+		return nil
+	}
+
+	eventType, collection := eventInfo(payload)
+
+	span.SetAttributes(
+		BucketKey.String(s.config.BucketName),
+		GroupKey.String(s.config.Dcp.Group.Name),
+		VbIDKey.Int(int(vbID)),
+		SeqNoKey.Int64(int64(offset.SeqNo)),
+		EventTypeKey.String(eventType),
+		CollectionKey.String(collection),
+	)
+	span.AddEvent("dequeued", trace.WithTimestamp(dequeuedAt))
+
+	e := &eventSpan{span: span}
+	e.remaining.Store(2)
+
+	return e
+}
+
+func (e *eventSpan) spanContext() trace.SpanContext {
+	if e == nil {
+		return trace.SpanContext{}
+	}
+
+	return e.span.SpanContext()
+}
+
+func (e *eventSpan) returned() {
+	if e == nil {
+		return
+	}
+
+	e.span.AddEvent("listener returned")
+	e.done()
+}
+
+func (e *eventSpan) ack() {
+	if e == nil || !e.acked.CompareAndSwap(false, true) {
+		return
+	}
+
+	e.span.AddEvent("acked")
+	e.done()
+}
+
+func (e *eventSpan) done() {
+	if e.remaining.Add(-1) == 0 {
+		e.span.End()
+	}
+}
+
+func collectionName(payload interface{}) string {
+	_, collection := eventInfo(payload)
+	return collection
+}
+
+func eventInfo(payload interface{}) (string, string) {
+	switch v := payload.(type) {
+	case models.DcpMutation:
+		return "mutation", v.CollectionName
+	case models.DcpDeletion:
+		return "deletion", v.CollectionName
+	case models.DcpExpiration:
+		return "expiration", v.CollectionName
+	default:
+		return "unknown", ""
+	}
+}
@@ -0,0 +1,79 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/Trendyol/go-dcp/config"
+	"github.com/Trendyol/go-dcp/models"
+)
+
+func TestEventSpanEndsAfterReturnAndAck(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	s := &stream{
+		ctx:    context.Background(),
+		config: &config.Dcp{BucketName: "orders"},
+		tracer: NewTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+	}
+
+	receivedAt := time.Now().Add(-time.Second)
+	mutation := models.DcpMutation{CollectionName: "_default"}
+
+	span := s.startEventSpan(mutation, &models.Offset{SeqNo: 42}, 7, receivedAt)
+	if !span.spanContext().IsValid() {
+		t.Fatalf("Unexpected result. Expected: %v, Got: %v", "valid span context", span.spanContext())
+	}
+
+	span.returned()
+
+	if len(recorder.Ended()) != 0 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", 0, len(recorder.Ended()))
+	}
+
+	span.ack()
+	span.ack()
+
+	ended := recorder.Ended()
+	if len(ended) != 1 {
+		t.Fatalf("Unexpected result. Expected: %v, Got: %v", 1, len(ended))
+	}
+
+	if !ended[0].StartTime().Equal(receivedAt) {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", receivedAt, ended[0].StartTime())
+	}
+
+	attributes := map[string]string{}
+	for _, attribute := range ended[0].Attributes() {
+		attributes[string(attribute.Key)] = attribute.Value.Emit()
+	}
+
+	if attributes["dcp.vbucket.id"] != "7" || attributes["dcp.seqno"] != "42" ||
+		attributes["dcp.event.type"] != "mutation" || attributes["dcp.bucket"] != "orders" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "event attributes", attributes)
+	}
+}
+
+func TestEventSpanIsNilWithoutTracing(t *testing.T) {
+	s := &stream{
+		ctx:    context.Background(),
+		config: &config.Dcp{},
+		tracer: NewTracer(nil),
+	}
+
+	span := s.startEventSpan(models.DcpDeletion{}, &models.Offset{}, 1, time.Time{})
+	if span != nil {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", nil, span)
+	}
+
+	span.returned()
+	span.ack()
+
+	if span.spanContext().IsValid() {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "invalid span context", span.spanContext())
+	}
+}
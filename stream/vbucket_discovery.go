@@ -2,6 +2,7 @@ package stream
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/asaskevich/EventBus"
 
@@ -16,14 +17,22 @@ import (
 	"github.com/Trendyol/go-dcp/membership"
 )
 
+var ErrUnknownMembership = errors.New("unknown membership")
+
 type VBucketDiscovery interface {
 	Get() []uint16
 	Close()
 	GetMetric() *VBucketDiscoveryMetric
+	GetEpoch() uint64
+	GetMembership() membership.Membership
+	GetMembers() []membership.Member
+	SetMemberLister(memberLister membership.MemberLister)
 }
 
 type vBucketDiscovery struct {
+	logger                 logger.Logger
 	membership             membership.Membership
+	memberLister           membership.MemberLister
 	vBucketDiscoveryMetric *VBucketDiscoveryMetric
 	vBucketNumber          int
 }
@@ -35,6 +44,7 @@ type VBucketDiscoveryMetric struct {
 	VBucketCount      int
 	VBucketRangeStart uint16
 	VBucketRangeEnd   uint16
+	Epoch             uint64
 }
 
 func (s *vBucketDiscovery) Get() []uint16 {
@@ -51,7 +61,7 @@ func (s *vBucketDiscovery) Get() []uint16 {
 	start := readyToStreamVBuckets[0]
 	end := readyToStreamVBuckets[len(readyToStreamVBuckets)-1]
 
-	logger.Log.Info(
+	s.logger.With("member", receivedInfo.MemberNumber).Info(
 		"member: %v/%v, vbucket range: %v-%v",
 		receivedInfo.MemberNumber, receivedInfo.TotalMembers,
 		start, end,
@@ -61,49 +71,131 @@ func (s *vBucketDiscovery) Get() []uint16 {
 	s.vBucketDiscoveryMetric.MemberNumber = receivedInfo.MemberNumber
 	s.vBucketDiscoveryMetric.VBucketRangeStart = start
 	s.vBucketDiscoveryMetric.VBucketRangeEnd = end
+	s.vBucketDiscoveryMetric.Epoch = s.latestEpoch(receivedInfo)
 
 	return readyToStreamVBuckets
 }
 
 func (s *vBucketDiscovery) Close() {
 	s.membership.Close()
-	logger.Log.Debug("vbucket discovery closed")
+	s.logger.Debug("vbucket discovery closed")
 }
 
 func (s *vBucketDiscovery) GetMetric() *VBucketDiscoveryMetric {
 	return s.vBucketDiscoveryMetric
 }
 
+// GetEpoch returns the latest fencing token of the membership, it may be newer than the one streams are opened with.
+func (s *vBucketDiscovery) GetEpoch() uint64 {
+	return s.latestEpoch(s.membership.GetInfo())
+}
+
+func (s *vBucketDiscovery) latestEpoch(info *membership.Model) uint64 {
+	if epochProvider, ok := s.memberLister.(membership.EpochProvider); ok {
+		return epochProvider.LatestEpoch(info)
+	}
+
+	return info.Epoch
+}
+
+func (s *vBucketDiscovery) GetMembership() membership.Membership {
+	return s.membership
+}
+
+// SetMemberLister overrides the source of group members, e.g. service discovery when leader election is enabled.
+func (s *vBucketDiscovery) SetMemberLister(memberLister membership.MemberLister) {
+	s.memberLister = memberLister
+}
+
+func (s *vBucketDiscovery) GetMembers() []membership.Member {
+	var members []membership.Member
+
+	if s.memberLister != nil {
+		members = s.memberLister.GetMembers()
+	} else if memberLister, ok := s.membership.(membership.MemberLister); ok {
+		members = memberLister.GetMembers()
+	}
+
+	if len(members) == 0 && s.vBucketDiscoveryMetric.TotalMembers != 0 {
+		members = append(members, membership.Member{
+			MemberNumber: s.vBucketDiscoveryMetric.MemberNumber,
+			TotalMembers: s.vBucketDiscoveryMetric.TotalMembers,
+			Self:         true,
+		})
+	}
+
+	vBuckets := make([]uint16, 0, s.vBucketNumber)
+	for i := 0; i < s.vBucketNumber; i++ {
+		vBuckets = append(vBuckets, uint16(i))
+	}
+
+	chunks := map[int][][]uint16{}
+
+	for i := range members {
+		member := &members[i]
+		if member.TotalMembers <= 0 || member.MemberNumber <= 0 || member.MemberNumber > member.TotalMembers {
+			continue
+		}
+
+		if _, ok := chunks[member.TotalMembers]; !ok {
+			chunks[member.TotalMembers] = helpers.ChunkSlice[uint16](vBuckets, member.TotalMembers)
+		}
+
+		assigned := chunks[member.TotalMembers][member.MemberNumber-1]
+		if len(assigned) > 0 {
+			member.VBucketRangeStart = assigned[0]
+			member.VBucketRangeEnd = assigned[len(assigned)-1]
+		}
+	}
+
+	return members
+}
+
 func NewVBucketDiscovery(client couchbase.Client,
 	config *config.Dcp,
 	vBucketNumber int,
 	bus EventBus.Bus,
-) VBucketDiscovery {
+	logger logger.Logger,
+) (VBucketDiscovery, error) {
 	var ms membership.Membership
+	var err error
 
 	switch {
 	case config.Dcp.Group.Membership.Type == membership.StaticMembershipType:
 		ms = membership.NewStaticMembership(config)
 	case config.Dcp.Group.Membership.Type == membership.CouchbaseMembershipType:
-		ms = couchbase.NewCBMembership(config, client, bus)
+		ms, err = couchbase.NewCBMembership(config, client, bus, logger)
 	case config.Dcp.Group.Membership.Type == membership.KubernetesStatefulSetMembershipType:
-		ms = kubernetes.NewStatefulSetMembership(config)
+		ms, err = kubernetes.NewStatefulSetMembership(config)
 	case config.Dcp.Group.Membership.Type == membership.KubernetesHaMembershipType:
-		ms = kubernetes.NewHaMembership(config, bus)
+		ms, err = kubernetes.NewHaMembership(config, bus, logger)
 	default:
-		err := errors.New("unknown membership")
-		logger.Log.Error("membership: %s, err: %v", config.Dcp.Group.Membership.Type, err)
-		panic(err)
+		err = fmt.Errorf("membership: %s, err: %w", config.Dcp.Group.Membership.Type, ErrUnknownMembership)
+	}
+
+	if err != nil {
+		return nil, err
 	}
 
-	logger.Log.Debug("vbucket discovery opened with membership type: %s", config.Dcp.Group.Membership.Type)
+	logger.Debug("vbucket discovery opened with membership type: %s", config.Dcp.Group.Membership.Type)
 
+	return NewVBucketDiscoveryWithMembership(ms, config.Dcp.Group.Membership.Type, vBucketNumber, logger), nil
+}
+
+// NewVBucketDiscoveryWithMembership creates a vbucket discovery which assigns vbuckets by ms,
+// membershipType is only reported in metrics.
+func NewVBucketDiscoveryWithMembership(ms membership.Membership,
+	membershipType string,
+	vBucketNumber int,
+	logger logger.Logger,
+) VBucketDiscovery {
 	return &vBucketDiscovery{
 		vBucketNumber: vBucketNumber,
 		membership:    ms,
+		logger:        logger,
 		vBucketDiscoveryMetric: &VBucketDiscoveryMetric{
 			VBucketCount: vBucketNumber,
-			Type:         config.Dcp.Group.Membership.Type,
+			Type:         membershipType,
 		},
 	}
 }
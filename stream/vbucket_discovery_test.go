@@ -0,0 +1,105 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/Trendyol/go-dcp/logger"
+	"github.com/Trendyol/go-dcp/membership"
+
+	"github.com/sirupsen/logrus"
+)
+
+type testMembership struct {
+	info    *membership.Model
+	members []membership.Member
+}
+
+func (s *testMembership) GetInfo() *membership.Model {
+	return s.info
+}
+
+func (s *testMembership) Close() {}
+
+type testMemberListerMembership struct {
+	testMembership
+}
+
+func (s *testMemberListerMembership) GetMembers() []membership.Member {
+	return s.members
+}
+
+type testMemberLister []membership.Member
+
+func (s testMemberLister) GetMembers() []membership.Member {
+	return s
+}
+
+type vBucketRange struct {
+	start uint16
+	end   uint16
+}
+
+func TestVBucketDiscoveryGetMembers(t *testing.T) {
+	tests := []struct {
+		name         string
+		membership   membership.Membership
+		memberLister membership.MemberLister
+		expected     []vBucketRange
+	}{
+		{
+			name:       "members of membership",
+			membership: &testMemberListerMembership{testMembership{members: []membership.Member{{MemberNumber: 1, TotalMembers: 2}, {MemberNumber: 2, TotalMembers: 2}}}}, //nolint:lll
+			expected:   []vBucketRange{{0, 511}, {512, 1023}},
+		},
+		{
+			name:       "members of member lister",
+			membership: &testMemberListerMembership{testMembership{members: []membership.Member{{MemberNumber: 1, TotalMembers: 1}}}},
+			memberLister: testMemberLister{
+				{MemberNumber: 1, TotalMembers: 3}, {MemberNumber: 2, TotalMembers: 3}, {MemberNumber: 3, TotalMembers: 3},
+			},
+			expected: []vBucketRange{{0, 341}, {342, 682}, {683, 1023}},
+		},
+		{
+			name:       "members with different total members",
+			membership: &testMemberListerMembership{testMembership{members: []membership.Member{{MemberNumber: 1, TotalMembers: 1}, {MemberNumber: 2, TotalMembers: 4}}}}, //nolint:lll
+			expected:   []vBucketRange{{0, 1023}, {256, 511}},
+		},
+		{
+			name:       "invalid member numbers",
+			membership: &testMemberListerMembership{testMembership{members: []membership.Member{{MemberNumber: 3, TotalMembers: 2}, {MemberNumber: 0, TotalMembers: 2}}}}, //nolint:lll
+			expected:   []vBucketRange{{0, 0}, {0, 0}},
+		},
+		{
+			name:       "self of membership without members",
+			membership: &testMembership{info: &membership.Model{MemberNumber: 2, TotalMembers: 4}},
+			expected:   []vBucketRange{{256, 511}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			discovery := NewVBucketDiscoveryWithMembership(
+				test.membership, membership.StaticMembershipType, 1024, &logger.Loggers{Logrus: logrus.New()},
+			)
+			if test.memberLister != nil {
+				discovery.SetMemberLister(test.memberLister)
+			}
+
+			if _, ok := test.membership.(membership.MemberLister); !ok {
+				discovery.Get()
+			}
+
+			members := discovery.GetMembers()
+			if len(members) != len(test.expected) {
+				t.Fatalf("Unexpected member count. Expected: %v, Got: %v", len(test.expected), len(members))
+			}
+
+			for i, member := range members {
+				actual := vBucketRange{member.VBucketRangeStart, member.VBucketRangeEnd}
+				if actual != test.expected[i] {
+					t.Errorf("Unexpected range of member %v. Expected: %v, Got: %v", member.MemberNumber, test.expected[i], actual)
+				}
+			}
+		})
+	}
+}
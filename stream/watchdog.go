@@ -0,0 +1,135 @@
+package stream
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/Trendyol/go-dcp/config"
+)
+
+const goroutineDumpSize = 1 << 20
+
+// stallCheck is what the watchdog observes about the listener on every interval.
+type stallCheck struct {
+	SinceLastAck         time.Duration
+	QueuedEvents         int
+	ListenerBusy         bool
+	SeqNoLag             uint64
+	PreviousLag          uint64
+	LagIsSampled         bool
+	PreviousLagIsSampled bool
+}
+
+// stalled reports whether the listener has not acked any event for stallTimeout
+// while events are waiting for it, it is handling an event or the lag grows.
+func (c stallCheck) stalled(stallTimeout time.Duration) bool {
+	if c.SinceLastAck < stallTimeout {
+		return false
+	}
+
+	if c.QueuedEvents > 0 || c.ListenerBusy {
+		return true
+	}
+
+	return c.LagIsSampled && c.PreviousLagIsSampled && c.SeqNoLag > c.PreviousLag
+}
+
+func (s *stream) startWatchdog() {
+	if !s.config.Dcp.Listener.Watchdog.Enabled {
+		return
+	}
+
+	s.lastAckAt.Store(time.Now().UnixNano())
+	s.stalled.Store(false)
+
+	s.watchdogStopCh = make(chan struct{})
+	s.watchdogDoneCh = make(chan struct{})
+
+	go s.watch(s.watchdogStopCh, s.watchdogDoneCh)
+}
+
+func (s *stream) stopWatchdog() {
+	if s.watchdogStopCh == nil {
+		return
+	}
+
+	close(s.watchdogStopCh)
+	<-s.watchdogDoneCh
+
+	s.watchdogStopCh = nil
+	s.stalled.Store(false)
+}
+
+func (s *stream) watch(stopCh chan struct{}, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	watchdog := s.config.Dcp.Listener.Watchdog
+
+	ticker := time.NewTicker(watchdog.Interval)
+	defer ticker.Stop()
+
+	var previous stallCheck
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			check := s.checkStall(previous)
+			previous = check
+
+			if !check.stalled(watchdog.StallTimeout) {
+				continue
+			}
+
+			if s.stalled.Swap(true) {
+				// the stall is reported once, it is cleared by the next ack
+				continue
+			}
+
+			s.onStall(check, &watchdog)
+		}
+	}
+}
+
+func (s *stream) checkStall(previous stallCheck) stallCheck {
+	check := stallCheck{
+		SinceLastAck:         time.Since(time.Unix(0, s.lastAckAt.Load())),
+		QueuedEvents:         len(s.observer.Listen()),
+		ListenerBusy:         s.listenerStartedAt.Load() != 0,
+		PreviousLag:          previous.SeqNoLag,
+		PreviousLagIsSampled: previous.LagIsSampled,
+	}
+
+	if lags, err := s.GetLags(); err == nil {
+		for _, lag := range lags {
+			check.SeqNoLag += lag.SeqNoLag
+		}
+		check.LagIsSampled = true
+	}
+
+	return check
+}
+
+func (s *stream) onStall(check stallCheck, watchdog *config.Watchdog) {
+	s.metric.ListenerStalls.Add(1)
+
+	buf := make([]byte, goroutineDumpSize)
+	buf = buf[:runtime.Stack(buf, true)]
+
+	s.logger.Error(
+		"listener did not ack any event for %v, queued events: %d, listener busy: %v, seq no lag: %d, action: %s, goroutines:\n%s",
+		check.SinceLastAck.Truncate(time.Second), check.QueuedEvents, check.ListenerBusy, check.SeqNoLag, watchdog.Action, buf,
+	)
+
+	if watchdog.Action == config.WatchdogActionRestart {
+		// Rebalance closes the stream which stops the watchdog, so it cannot run on this goroutine
+		go s.Rebalance()
+	}
+}
+
+// acked clears the stall, the listener is alive again.
+func (s *stream) acked() {
+	s.lastAckAt.Store(time.Now().UnixNano())
+	s.stalled.Store(false)
+}
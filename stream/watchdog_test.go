@@ -0,0 +1,77 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Trendyol/go-dcp/config"
+)
+
+func TestStallCheckStalled(t *testing.T) {
+	stallTimeout := time.Minute
+
+	tests := []struct {
+		name     string
+		check    stallCheck
+		expected bool
+	}{
+		{
+			name:     "recently acked",
+			check:    stallCheck{SinceLastAck: time.Second, QueuedEvents: 10, ListenerBusy: true},
+			expected: false,
+		},
+		{
+			name:     "idle",
+			check:    stallCheck{SinceLastAck: time.Hour},
+			expected: false,
+		},
+		{
+			name:     "events are queued",
+			check:    stallCheck{SinceLastAck: time.Hour, QueuedEvents: 1},
+			expected: true,
+		},
+		{
+			name:     "listener is busy",
+			check:    stallCheck{SinceLastAck: time.Hour, ListenerBusy: true},
+			expected: true,
+		},
+		{
+			name: "lag grows",
+			check: stallCheck{
+				SinceLastAck: time.Hour, SeqNoLag: 20, PreviousLag: 10, LagIsSampled: true, PreviousLagIsSampled: true,
+			},
+			expected: true,
+		},
+		{
+			name: "lag does not grow",
+			check: stallCheck{
+				SinceLastAck: time.Hour, SeqNoLag: 10, PreviousLag: 10, LagIsSampled: true, PreviousLagIsSampled: true,
+			},
+			expected: false,
+		},
+		{
+			name:     "lag is not sampled before",
+			check:    stallCheck{SinceLastAck: time.Hour, SeqNoLag: 20, LagIsSampled: true},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		if actual := test.check.stalled(stallTimeout); actual != test.expected {
+			t.Errorf("Unexpected result for %s. Expected: %v, Got: %v", test.name, test.expected, actual)
+		}
+	}
+}
+
+func TestHealthListenerStalled(t *testing.T) {
+	health := Health{Open: true}.Merge(Health{Open: true, ListenerStalled: true, SecondsSinceLastAck: 90})
+
+	if !health.ListenerStalled || health.SecondsSinceLastAck != 90 {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "stalled for 90s", health)
+	}
+
+	reasons := health.NotReadyReasons(&config.Readiness{MaxCheckpointSaveFailures: 3, ListenerStallTimeout: time.Minute})
+	if len(reasons) != 1 || reasons[0] != "listener did not ack any event for 90s" {
+		t.Errorf("Unexpected result. Expected: %v, Got: %v", "listener did not ack any event for 90s", reasons)
+	}
+}
@@ -44,11 +44,15 @@ func TestCouchbase(t *testing.T) {
 	}()
 
 	go func() {
-		ctx, _ := context.WithTimeout(context.Background(), 20*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+
 		for {
 			select {
 			case <-ctx.Done():
-				t.Fatalf("could not process events in 20 seconds")
+				t.Errorf("could not process events in 20 seconds")
+				newDcp.Close()
+				return
 			default:
 				if mutationCount == 31591 {
 					newDcp.Close()
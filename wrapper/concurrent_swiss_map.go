@@ -35,6 +35,11 @@ func (m *ConcurrentSwissMap[K, V]) Store(key K, value V) {
 	m.m.Store(key, value)
 }
 
+// Clear deletes every key, concurrent readers and writers keep using the same map.
+func (m *ConcurrentSwissMap[K, V]) Clear() {
+	m.m.Clear()
+}
+
 func (m *ConcurrentSwissMap[K, V]) Count() int {
 	return m.m.Count()
 }
@@ -14,3 +14,14 @@ func TestSyncMapWrapper(t *testing.T) {
 		t.Errorf("key not exist")
 	}
 }
+
+func TestSyncMapWrapperClear(t *testing.T) {
+	p := CreateConcurrentSwissMap[string, string](0)
+	p.Store("key", "value")
+
+	p.Clear()
+
+	if _, ok := p.Load("key"); ok || p.Count() != 0 {
+		t.Errorf("map must be empty after clear")
+	}
+}